@@ -0,0 +1,166 @@
+package httprouterx
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaRange is a media range of the Accept header, as defined by RFC 7231 section 5.3.2.
+type MediaRange struct {
+	Type    string
+	Subtype string
+
+	// Params are the media type parameters, e.g. version=2. Keys are lower-cased.
+	// The quality weight and the accept extensions after it are not included.
+	Params map[string]string
+
+	// Quality is the q parameter, which defaults to 1.
+	Quality float64
+}
+
+// MediaType returns the media range without parameters, e.g. "application/json".
+func (m MediaRange) MediaType() string { return m.Type + "/" + m.Subtype }
+
+// Matches reports whether the media type (which may include parameters) falls within the media range.
+// Wildcards match any type or subtype, and every parameter of the range must be present in the media type
+// with the same value.
+func (m MediaRange) Matches(mediaType string) bool {
+	mt, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	typ, subtype, _ := strings.Cut(mt, "/")
+	if m.Type != "*" && m.Type != typ {
+		return false
+	}
+	if m.Subtype != "*" && m.Subtype != subtype {
+		return false
+	}
+	for k, v := range m.Params {
+		if params[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// specificity ranks how specific the media range is, higher is more specific.
+func (m MediaRange) specificity() int {
+	switch {
+	case m.Type == "*":
+		return 0
+	case m.Subtype == "*":
+		return 1
+	default:
+		return 2 + len(m.Params)
+	}
+}
+
+// ParseAccept parses the Accept header of the request into media ranges, sorted by quality from highest to
+// lowest. Media ranges with the same quality are sorted from the most specific to the least specific, and keep
+// the order of the header otherwise. Malformed media ranges are skipped.
+//
+// Media ranges with quality 0 are kept (at the end), since they explicitly mark a media type as not acceptable.
+// If the request has no Accept header, it returns nil, which means any media type is acceptable.
+func ParseAccept(r *http.Request) []MediaRange {
+	header := strings.Join(r.Header.Values("Accept"), ",")
+	var ranges []MediaRange
+	for _, part := range splitQuoted(header, ',') {
+		if mr, ok := parseMediaRange(part); ok {
+			ranges = append(ranges, mr)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].Quality != ranges[j].Quality {
+			return ranges[i].Quality > ranges[j].Quality
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+func parseMediaRange(s string) (MediaRange, bool) {
+	parts := splitQuoted(s, ';')
+	if len(parts) == 0 {
+		return MediaRange{}, false
+	}
+
+	typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(parts[0])), "/")
+	if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+		return MediaRange{}, false
+	}
+
+	mr := MediaRange{Type: typ, Subtype: subtype, Quality: 1}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.TrimSpace(v)
+		if k == "" {
+			continue
+		}
+		if k == "q" {
+			q, err := strconv.ParseFloat(v, 64)
+			if err != nil || q < 0 || q > 1 {
+				return MediaRange{}, false
+			}
+			mr.Quality = q
+			break // anything after the weight is an accept extension.
+		}
+		if mr.Params == nil {
+			mr.Params = make(map[string]string)
+		}
+		mr.Params[k] = unquote(v)
+	}
+	return mr, true
+}
+
+// splitQuoted splits s by sep, ignoring the separators inside quoted strings, and drops empty parts.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		parts  []string
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				if p := strings.TrimSpace(s[start:i]); p != "" {
+					parts = append(parts, p)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if start < len(s) {
+		if p := strings.TrimSpace(s[start:]); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// unquote removes the quotes of a quoted-string and its escapes. Other values are returned as is.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package httprouterx
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAccept(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", `text/*;q=0.3, application/vnd.myapp+json; version=2; q=0.9, */*;q=0.1, text/html, application/vnd.myapp+json;version="1";q=0.9;ext=1, image/png;q=0`)
+
+	ranges := ParseAccept(req)
+	expectTrue(t, len(ranges) == 6)
+
+	expectTrue(t, ranges[0].MediaType() == "text/html")
+	expectTrue(t, ranges[0].Quality == 1)
+
+	expectTrue(t, ranges[1].MediaType() == "application/vnd.myapp+json")
+	expectTrue(t, ranges[1].Params["version"] == "2")
+	expectTrue(t, ranges[1].Quality == 0.9)
+
+	expectTrue(t, ranges[2].Params["version"] == "1")
+	expectTrue(t, len(ranges[2].Params) == 1)
+
+	expectTrue(t, ranges[3].MediaType() == "text/*")
+	expectTrue(t, ranges[4].MediaType() == "*/*")
+	expectTrue(t, ranges[5].MediaType() == "image/png")
+	expectTrue(t, ranges[5].Quality == 0)
+}
+
+func TestParseAccept_SpecificityAndMalformed(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Accept", "*/*, text/*")
+	req.Header.Add("Accept", "text/plain;format=flowed, text/plain, invalid, */json, text/html;q=2")
+
+	ranges := ParseAccept(req)
+	expectTrue(t, len(ranges) == 4)
+	expectTrue(t, ranges[0].MediaType() == "text/plain" && ranges[0].Params["format"] == "flowed")
+	expectTrue(t, ranges[1].MediaType() == "text/plain" && ranges[1].Params == nil)
+	expectTrue(t, ranges[2].MediaType() == "text/*")
+	expectTrue(t, ranges[3].MediaType() == "*/*")
+
+	expectTrue(t, ParseAccept(httptest.NewRequest("GET", "/", nil)) == nil)
+}
+
+func TestMediaRange_Matches(t *testing.T) {
+	tests := []struct {
+		mr        MediaRange
+		mediaType string
+		want      bool
+	}{
+		{MediaRange{Type: "*", Subtype: "*"}, "application/json", true},
+		{MediaRange{Type: "application", Subtype: "*"}, "application/json", true},
+		{MediaRange{Type: "application", Subtype: "*"}, "text/json", false},
+		{MediaRange{Type: "application", Subtype: "json"}, "application/json; charset=utf-8", true},
+		{MediaRange{Type: "application", Subtype: "vnd.myapp+json", Params: map[string]string{"version": "2"}}, "application/vnd.myapp+json; version=2", true},
+		{MediaRange{Type: "application", Subtype: "vnd.myapp+json", Params: map[string]string{"version": "2"}}, "application/vnd.myapp+json; version=1", false},
+		{MediaRange{Type: "application", Subtype: "vnd.myapp+json", Params: map[string]string{"version": "2"}}, "application/vnd.myapp+json", false},
+		{MediaRange{Type: "application", Subtype: "json"}, "invalid", false},
+	}
+
+	for _, tt := range tests {
+		expectTrue(t, tt.mr.Matches(tt.mediaType) == tt.want)
+	}
+}
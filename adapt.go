@@ -0,0 +1,51 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+)
+
+// WrapHTTPMiddleware adapts a standard net/http middleware, such as the ones of other libraries, into a
+// Middleware.
+//
+// The error returned by the next Handler is captured while the standard middleware runs, and returned once it
+// returns, so it still reaches the outer middlewares and the LastResortErrorHandler. If the standard middleware
+// does not call its next handler, e.g. to reject the request, the returned error is nil. The standard middleware
+// must pass a request derived from the one it received, see http.Request.WithContext, otherwise the error is lost.
+//
+// The standard middleware is built once per Handler, like any Middleware, so it can keep state across requests,
+// including when it is a global middleware of the ServeMux, which is built once per route.
+func WrapHTTPMiddleware(m func(http.Handler) http.Handler) Middleware {
+	return func(next Handler) Handler {
+		std := m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := next.ServeHTTP(w, r)
+			if slot, ok := r.Context().Value(wrapErrKey).(*error); ok {
+				*slot = err
+			}
+		}))
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			var err error
+			std.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), wrapErrKey, &err)))
+			return err
+		})
+	}
+}
+
+// WrapHandler adapts a standard http.Handler, such as pprof or a metrics exporter, into a Handler that always
+// returns a nil error. For example:
+//
+//	mux.Handle("GET", "/debug/pprof/*path", WrapHandler(http.DefaultServeMux))
+func WrapHandler(h http.Handler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		h.ServeHTTP(w, r)
+		return nil
+	})
+}
+
+// WrapHandlerFunc is like WrapHandler, but for a standard http.HandlerFunc.
+func WrapHandlerFunc(h http.HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		h(w, r)
+		return nil
+	}
+}
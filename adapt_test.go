@@ -0,0 +1,102 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestWrapHTTPMiddleware(t *testing.T) {
+	built := 0
+	std := func(next http.Handler) http.Handler {
+		built++
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	errBoom := errors.New("boom")
+	h := WrapHTTPMiddleware(std).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/fail" {
+			return errBoom
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+	expectTrue(t, built == 1)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "token")
+	expectTrue(t, h.ServeHTTP(rec, req) == nil)
+	expectTrue(t, rec.Code == http.StatusNoContent)
+	expectTrue(t, rec.Header().Get("X-Std") == "1")
+
+	// the error of the handler is surfaced after the standard middleware returns.
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/fail", nil)
+	req.Header.Set("Authorization", "token")
+	expectTrue(t, errors.Is(h.ServeHTTP(rec, req), errBoom))
+
+	// short-circuited by the standard middleware.
+	rec = httptest.NewRecorder()
+	expectTrue(t, h.ServeHTTP(rec, httptest.NewRequest("GET", "/fail", nil)) == nil)
+	expectTrue(t, rec.Code == http.StatusUnauthorized)
+	expectTrue(t, built == 1)
+}
+
+func TestWrapHTTPMiddleware_Global(t *testing.T) {
+	built := 0
+	counter := func(next http.Handler) http.Handler {
+		built++
+		requests := 0 // the state of the middleware instance.
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("X-Requests", strconv.Itoa(requests))
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	mux := NewServeMux(Options.Middleware(WrapHTTPMiddleware(counter)))
+	mux.GET("/", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	for i := 1; i <= 3; i++ {
+		rec := mux.TestRequest("GET", "/", nil)
+		expectTrue(t, rec.Header().Get("X-Requests") == strconv.Itoa(i))
+	}
+	expectTrue(t, built == 1)
+
+	mux = NewServeMux()
+	mux.GET("/", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	mux.Use(WrapHTTPMiddleware(counter))
+	for i := 1; i <= 3; i++ {
+		rec := mux.TestRequest("GET", "/", nil)
+		expectTrue(t, rec.Header().Get("X-Requests") == strconv.Itoa(i))
+	}
+	expectTrue(t, built == 2)
+}
+
+func TestWrapHandler(t *testing.T) {
+	std := http.NewServeMux()
+	std.HandleFunc("/debug/vars", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusAccepted) })
+
+	mux := NewServeMux()
+	mux.Handle("GET", "/debug/*path", WrapHandler(std))
+	mux.HandleFunc("GET", "/ping", WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/vars", nil))
+	expectTrue(t, rec.Code == http.StatusAccepted)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/ping", nil))
+	expectTrue(t, rec.Code == http.StatusTeapot)
+}
@@ -0,0 +1,43 @@
+package httprouterx
+
+import (
+	"net/http"
+	"runtime"
+	"sync/atomic"
+)
+
+// DefaultAllocSampleRate is the sampling rate of AllocTracker: one in every DefaultAllocSampleRate requests is
+// measured.
+const DefaultAllocSampleRate = 100
+
+// AllocTracker reports the requests that allocate more than threshold bytes on the heap, to help pinpoint
+// allocation-heavy endpoints. onExceed is called after the handler returns, with the request and the number of
+// allocated bytes. The route that served the request is available with CurrentRoute.
+//
+// The allocations are measured with the difference of runtime.MemStats.TotalAlloc before and after the handler.
+// runtime.ReadMemStats briefly stops the world, so only one in every DefaultAllocSampleRate requests is measured,
+// starting with the first one; the others are not tracked at all.
+//
+// The measure is an approximation: TotalAlloc is global to the process, so it also counts what the other
+// goroutines allocated in the meantime, including the concurrent requests. It is reliable enough to spot the
+// heavy endpoints, since they exceed the threshold consistently, but a single report should not be trusted.
+func AllocTracker(threshold uint64, onExceed func(*http.Request, uint64)) Middleware {
+	var counter atomic.Uint64
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if (counter.Add(1)-1)%DefaultAllocSampleRate != 0 {
+				return next.ServeHTTP(w, r)
+			}
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			err := next.ServeHTTP(w, r)
+			runtime.ReadMemStats(&after)
+
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > threshold {
+				onExceed(r, allocated)
+			}
+			return err
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var allocSink []byte
+
+func TestAllocTracker(t *testing.T) {
+	var reports []uint64
+	h := AllocTracker(1<<20, func(r *http.Request, allocated uint64) {
+		reports = append(reports, allocated)
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Has("heavy") {
+			allocSink = make([]byte, 8<<20)
+		}
+		return nil
+	}))
+
+	serve := func(target string) {
+		err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+		expectTrue(t, err == nil)
+	}
+
+	// the first request is sampled.
+	serve("/?heavy")
+	expectTrue(t, len(reports) == 1)
+	expectTrue(t, reports[0] >= 8<<20)
+
+	// the next ones are not, until the next sample.
+	for i := 1; i < DefaultAllocSampleRate; i++ {
+		serve("/?heavy")
+	}
+	expectTrue(t, len(reports) == 1)
+
+	// sampled, but below the threshold.
+	serve("/")
+	expectTrue(t, len(reports) == 1)
+
+	for i := 1; i < DefaultAllocSampleRate; i++ {
+		serve("/")
+	}
+	serve("/?heavy")
+	expectTrue(t, len(reports) == 2)
+}
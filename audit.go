@@ -0,0 +1,143 @@
+package httprouterx
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AuditRecord is the structured record emitted by Audit for every mutating request.
+type AuditRecord struct {
+	Time      time.Time
+	Method    string
+	Path      string
+	Query     string // the query with the sensitive values redacted.
+	RouteName string
+	RoutePath string
+	Principal any
+	Status    int
+	Error     string
+	Duration  time.Duration
+	ClientIP  string
+	RequestID string
+}
+
+// DefaultAuditRedactedParams are the query parameters redacted by Audit when none are configured.
+var DefaultAuditRedactedParams = []string{"password", "token", "access_token", "refresh_token", "secret", "api_key", "apikey", "signature"}
+
+// AuditConfig is the configuration for AuditWithConfig.
+type AuditConfig struct {
+	// Sink receives the records, e.g. to publish them to a message queue. It is called from a single
+	// background goroutine, in the order of the records.
+	Sink func(AuditRecord)
+
+	// BufferSize is the number of records that can wait for the sink. Default 1024.
+	BufferSize int
+
+	// Block makes the requests wait for room in the buffer when it is full. By default, the records are dropped
+	// instead, so the sink never slows the responses down.
+	Block bool
+
+	// RedactedParams are the query parameters whose values are replaced by "REDACTED" in the record.
+	// Matching is case-insensitive. Default DefaultAuditRedactedParams.
+	RedactedParams []string
+
+	// Clock is used to timestamp the records. Default SystemClock.
+	Clock Clock
+}
+
+// Audit emits an AuditRecord to the sink for every mutating request (POST, PUT, PATCH and DELETE), which is
+// a common compliance requirement. The sink is called asynchronously, and records are dropped when the sink
+// cannot keep up. See AuditWithConfig for the available options.
+func Audit(sink func(AuditRecord)) Middleware {
+	return AuditWithConfig(AuditConfig{Sink: sink})
+}
+
+// AuditWithConfig is like Audit, but with custom buffering and redaction.
+// The background goroutine calling the sink lives as long as the process.
+func AuditWithConfig(cfg AuditConfig) Middleware {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1024
+	}
+	if cfg.RedactedParams == nil {
+		cfg.RedactedParams = DefaultAuditRedactedParams
+	}
+	clock := clockOrSystem(cfg.Clock)
+
+	redacted := make(map[string]bool, len(cfg.RedactedParams))
+	for _, p := range cfg.RedactedParams {
+		redacted[strings.ToLower(p)] = true
+	}
+
+	records := make(chan AuditRecord, cfg.BufferSize)
+	go func() {
+		for rec := range records {
+			cfg.Sink(rec)
+		}
+	}()
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				return next.ServeHTTP(w, r)
+			}
+
+			start := clock.Now()
+			sw := newStatusWriter(w)
+			err := next.ServeHTTP(sw, r)
+
+			rec := AuditRecord{
+				Time:      start,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Query:     redactQuery(r.URL.Query(), redacted),
+				Status:    sw.statusCode(),
+				Duration:  clock.Now().Sub(start),
+				ClientIP:  clientIP(r),
+				RequestID: requestIDOf(r),
+			}
+			if route, ok := CurrentRoute(r); ok {
+				rec.RouteName, rec.RoutePath = route.Name, route.Path
+			}
+			rec.Principal, _ = PrincipalFromContext(r.Context())
+			if err != nil {
+				rec.Error = err.Error()
+			}
+
+			if cfg.Block {
+				records <- rec
+			} else {
+				select {
+				case records <- rec:
+				default:
+				}
+			}
+			return err
+		})
+	}
+}
+
+// redactQuery encodes the query, replacing the values of the redacted parameters.
+func redactQuery(query url.Values, redacted map[string]bool) string {
+	for k, values := range query {
+		if redacted[strings.ToLower(k)] {
+			for i := range values {
+				values[i] = "REDACTED"
+			}
+		}
+	}
+	return query.Encode()
+}
+
+// clientIP returns the host part of the remote address of the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,98 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAudit(t *testing.T) {
+	clock := newFakeClock()
+	records := make(chan AuditRecord, 1)
+	anError := errors.New("an error")
+
+	mux := NewServeMux(Options.Middleware(AuditWithConfig(AuditConfig{
+		Sink:  func(rec AuditRecord) { records <- rec },
+		Block: true,
+		Clock: clock,
+	})))
+
+	mux.Route(Route{Method: "POST", Path: "/users/:id", Name: "users.update", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		clock.Advance(time.Second)
+		return anError
+	}})
+	mux.Route(Route{Method: "GET", Path: "/users/:id", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}})
+
+	req := httptest.NewRequest("POST", "/users/1?token=abc&view=full", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req = req.WithContext(WithPrincipal(req.Context(), "alice"))
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := <-records
+	expectTrue(t, rec.Time.Equal(newFakeClock().Now()))
+	expectTrue(t, rec.Method == "POST")
+	expectTrue(t, rec.Path == "/users/1")
+	expectTrue(t, rec.Query == "token=REDACTED&view=full")
+	expectTrue(t, rec.RouteName == "users.update")
+	expectTrue(t, rec.RoutePath == "/users/:id")
+	expectTrue(t, rec.Principal == "alice")
+	expectTrue(t, rec.Status == 200)
+	expectTrue(t, rec.Error == "an error")
+	expectTrue(t, rec.Duration == time.Second)
+	expectTrue(t, rec.ClientIP == "192.0.2.1")
+	expectTrue(t, rec.RequestID == "req-1")
+
+	// safe methods are not audited.
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+	select {
+	case <-records:
+		t.Fatal("GET must not be audited")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestAudit_RequestID(t *testing.T) {
+	records := make(chan AuditRecord, 1)
+	h := FoldMiddleware(
+		RequestIDMiddlewareWithConfig(RequestIDConfig{Generate: func() string { return "generated" }}),
+		AuditWithConfig(AuditConfig{Sink: func(rec AuditRecord) { records <- rec }, Block: true}),
+	).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+	expectTrue(t, (<-records).RequestID == "generated")
+}
+
+func TestAudit_DropsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan AuditRecord, 10)
+	h := AuditWithConfig(AuditConfig{
+		Sink: func(rec AuditRecord) {
+			<-release
+			received <- rec
+		},
+		BufferSize: 1,
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/", nil))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("requests must not block on a slow sink")
+	}
+	close(release)
+
+	// at most one record is being sunk and one is buffered.
+	time.Sleep(10 * time.Millisecond)
+	expectTrue(t, len(received) <= 2)
+}
@@ -0,0 +1,38 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// AuthorizationPolicy decides whether the principal is allowed to access the route.
+// A nil error grants the access.
+type AuthorizationPolicy func(ctx context.Context, principal any, route RouteInfo) error
+
+// Authorize runs the policy against the principal and the matched route, and denies the request with 403
+// when the policy returns an error. If the policy already returns an *HTTPError, its status is kept.
+//
+// The principal is read from the context (see WithPrincipal), so Authorize must be placed after the
+// authentication middleware that stores it, e.g.:
+//
+//	FoldMiddleware(authenticate, Authorize(policy))
+//
+// If no principal is found, the policy is called with a nil principal, which lets the policy decide whether
+// anonymous access is allowed.
+func Authorize(policy AuthorizationPolicy) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			principal, _ := PrincipalFromContext(r.Context())
+			route, _ := CurrentRoute(r)
+			if err := policy(r.Context(), principal, route); err != nil {
+				var httpErr *HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				return &HTTPError{Status: http.StatusForbidden, Message: http.StatusText(http.StatusForbidden), Err: err}
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
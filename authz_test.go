@@ -0,0 +1,57 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	authenticate := Middleware(func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if user := r.Header.Get("X-User"); user != "" {
+				r = r.WithContext(WithPrincipal(r.Context(), user))
+			}
+			return next.ServeHTTP(w, r)
+		})
+	})
+
+	policy := func(ctx context.Context, principal any, route RouteInfo) error {
+		if principal == nil {
+			return NewHTTPError(http.StatusUnauthorized, "")
+		}
+		for _, tag := range route.Tags {
+			if tag == "admin" && principal != "root" {
+				return errors.New("admin only")
+			}
+		}
+		return nil
+	}
+
+	mux := NewServeMux(Options.Middleware(FoldMiddleware(authenticate, Authorize(policy))))
+	mux.Route(Route{Method: "GET", Path: "/admin", Tags: []string{"admin"}, Handler: func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}})
+
+	tests := []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{name: "allowed", user: "root", status: 200},
+		{name: "denied", user: "bob", status: http.StatusForbidden},
+		{name: "anonymous", user: "", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin", nil)
+			req.Header.Set("X-User", tt.user)
+			mux.ServeHTTP(res, req)
+			expectTrue(t, res.Code == tt.status)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// The limits of the W3C Baggage specification. Members beyond them are dropped, in order.
+const (
+	MaxBaggageMembers = 64
+	MaxBaggageBytes   = 8192
+)
+
+// Baggage parses the W3C Baggage header of the request (https://www.w3.org/TR/baggage/), e.g.
+// baggage: tenant=acme,flags=beta%2Cdark, and stores its members in the context, so the cross-cutting metadata
+// travels across the service hops in a single standard header rather than bespoke ones. The handlers read the
+// members with BaggageGet, add or replace them with BaggageSet, and propagate them to the downstream services
+// with BaggageHeader.
+//
+// Values are percent-decoded. Malformed members are dropped, as well as the members beyond the limits of the
+// specification: MaxBaggageMembers members and MaxBaggageBytes bytes.
+func Baggage() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			b := parseBaggage(strings.Join(r.Header.Values("baggage"), ","))
+			return next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), baggageKey, b)))
+		})
+	}
+}
+
+// BaggageGet gets the value of the baggage member. It returns false if the member does not exist or the Baggage
+// middleware was not applied.
+func BaggageGet(r *http.Request, key string) (string, bool) {
+	b, ok := r.Context().Value(baggageKey).(*baggage)
+	if !ok {
+		return "", false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, m := range b.members {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return "", false
+}
+
+// BaggageSet sets the value of the baggage member, replacing the existing one along with its properties. The
+// change is visible to the whole request, including the middlewares that run before the handler. Keys that are
+// not valid tokens are ignored, as well as the calls without the Baggage middleware.
+func BaggageSet(r *http.Request, key, val string) {
+	b, ok := r.Context().Value(baggageKey).(*baggage)
+	if !ok || !isBaggageKey(key) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, m := range b.members {
+		if m.key == key {
+			b.members[i] = baggageMember{key: key, value: val}
+			return
+		}
+	}
+	b.members = append(b.members, baggageMember{key: key, value: val})
+}
+
+// BaggageHeader serializes the baggage of the request, to be sent as the baggage header of the requests to the
+// downstream services. Members beyond the limits of the specification are dropped. It returns an empty string if
+// the baggage is empty or the Baggage middleware was not applied.
+func BaggageHeader(r *http.Request) string {
+	b, ok := r.Context().Value(baggageKey).(*baggage)
+	if !ok {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		sb strings.Builder
+		n  int
+	)
+	for _, m := range b.members {
+		s := m.String()
+		size := len(s)
+		if n > 0 {
+			size++ // the comma.
+		}
+		if n == MaxBaggageMembers || sb.Len()+size > MaxBaggageBytes {
+			break
+		}
+		if n > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(s)
+		n++
+	}
+	return sb.String()
+}
+
+// baggage is the mutable baggage of a request.
+type baggage struct {
+	mu      sync.Mutex
+	members []baggageMember
+}
+
+// baggageMember is a list member of the baggage. The properties are kept as received.
+type baggageMember struct {
+	key        string
+	value      string
+	properties string
+}
+
+// String encodes the member as key=value;properties.
+func (m baggageMember) String() string {
+	s := m.key + "=" + escapeBaggageValue(m.value)
+	if m.properties != "" {
+		s += ";" + m.properties
+	}
+	return s
+}
+
+// parseBaggage parses the list members of the header, within the limits of the specification.
+func parseBaggage(header string) *baggage {
+	b := &baggage{}
+	size := 0
+	for _, member := range strings.Split(header, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		if len(b.members) == MaxBaggageMembers {
+			break
+		}
+		if size += len(member); len(b.members) > 0 {
+			size++
+		}
+		if size > MaxBaggageBytes {
+			break
+		}
+
+		m, ok := parseBaggageMember(member)
+		if !ok {
+			continue
+		}
+		replaced := false
+		for i := range b.members {
+			if b.members[i].key == m.key {
+				b.members[i], replaced = m, true
+			}
+		}
+		if !replaced {
+			b.members = append(b.members, m)
+		}
+	}
+	return b
+}
+
+// parseBaggageMember parses key=value;properties.
+func parseBaggageMember(s string) (baggageMember, bool) {
+	pair, props, _ := strings.Cut(s, ";")
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok {
+		return baggageMember{}, false
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !isBaggageKey(key) {
+		return baggageMember{}, false
+	}
+	value, err := url.PathUnescape(value)
+	if err != nil {
+		return baggageMember{}, false
+	}
+
+	var properties []string
+	for _, p := range strings.Split(props, ";") {
+		if p = strings.TrimSpace(p); p != "" {
+			properties = append(properties, p)
+		}
+	}
+	return baggageMember{key: key, value: value, properties: strings.Join(properties, ";")}, true
+}
+
+// isBaggageKey reports whether key is a token, as defined by RFC 7230.
+func isBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeBaggageValue percent-encodes the bytes that are not baggage octets: controls, space, '"', ',', ';',
+// '\', '%' and non-ASCII.
+func escapeBaggageValue(v string) string {
+	const hex = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`",;\%`, c) >= 0 {
+			sb.WriteByte('%')
+			sb.WriteByte(hex[c>>4])
+			sb.WriteByte(hex[c&0xf])
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package httprouterx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBaggage(t *testing.T) {
+	var got http.Header
+	h := Baggage().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		got = http.Header{}
+		for _, key := range []string{"tenant", "flags", "user", "missing", "bad key"} {
+			if v, ok := BaggageGet(r, key); ok {
+				got.Set(key, v)
+			}
+		}
+		got.Set("out", BaggageHeader(r))
+		return nil
+	}))
+
+	serve := func(headers ...string) {
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, v := range headers {
+			r.Header.Add("baggage", v)
+		}
+		expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), r) == nil)
+	}
+
+	serve("tenant=acme, flags = beta%2Cdark ;ttl=30", "user=J%C3%B6rg")
+	expectTrue(t, got.Get("tenant") == "acme")
+	expectTrue(t, got.Get("flags") == "beta,dark")
+	expectTrue(t, got.Get("user") == "Jörg")
+	expectTrue(t, got.Get("missing") == "")
+	expectTrue(t, got.Get("out") == "tenant=acme,flags=beta%2Cdark;ttl=30,user=J%C3%B6rg")
+
+	// malformed members are dropped.
+	serve("tenant=acme,novalue,bad key=1,=empty,user=%zz,flags=on")
+	expectTrue(t, got.Get("out") == "tenant=acme,flags=on")
+
+	serve()
+	expectTrue(t, got.Get("out") == "")
+}
+
+func TestBaggage_Limits(t *testing.T) {
+	var out string
+	var n int
+	h := Baggage().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		out = BaggageHeader(r)
+		n = 0
+		if out != "" {
+			n = strings.Count(out, ",") + 1
+		}
+		return nil
+	}))
+
+	var members []string
+	for i := 0; i < 100; i++ {
+		members = append(members, fmt.Sprintf("k%d=v", i))
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("baggage", strings.Join(members, ","))
+	_ = h.ServeHTTP(httptest.NewRecorder(), r)
+	expectTrue(t, n == MaxBaggageMembers)
+	expectTrue(t, strings.HasSuffix(out, ",k63=v"))
+
+	big := strings.Repeat("x", 5000)
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("baggage", "a="+big+",b="+big+",c=1")
+	_ = h.ServeHTTP(httptest.NewRecorder(), r)
+	expectTrue(t, out == "a="+big)
+}
+
+func TestBaggageSet(t *testing.T) {
+	var out string
+	inner := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		BaggageSet(r, "tenant", "globex")
+		BaggageSet(r, "note", "a b;c")
+		BaggageSet(r, "bad key", "ignored")
+		return nil
+	})
+	h := Baggage().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err := inner.ServeHTTP(w, r)
+		// the changes of the inner handlers are visible to the outer ones.
+		out = BaggageHeader(r)
+		return err
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("baggage", "tenant=acme;p=1,user=42")
+	_ = h.ServeHTTP(httptest.NewRecorder(), r)
+	expectTrue(t, out == "tenant=globex,user=42,note=a%20b%3Bc")
+
+	// round trip.
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("baggage", out)
+	var note string
+	_ = Baggage().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		note, _ = BaggageGet(r, "note")
+		return nil
+	})).ServeHTTP(httptest.NewRecorder(), r)
+	expectTrue(t, note == "a b;c")
+
+	// without the middleware.
+	r = httptest.NewRequest("GET", "/", nil)
+	BaggageSet(r, "tenant", "acme")
+	_, ok := BaggageGet(r, "tenant")
+	expectFalse(t, ok)
+	expectTrue(t, BaggageHeader(r) == "")
+}
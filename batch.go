@@ -0,0 +1,139 @@
+package httprouterx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxBatchSize is the maximum number of sub-requests accepted by Batch in a single request.
+	DefaultMaxBatchSize = 100
+
+	// DefaultBatchConcurrency is the maximum number of sub-requests of a batch that Batch runs at the same time.
+	DefaultBatchConcurrency = 8
+)
+
+// BatchRequest is a sub-request of a batch.
+type BatchRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResponse is the response of a sub-request of a batch.
+type BatchResponse struct {
+	Status  int             `json:"status"`
+	Headers http.Header     `json:"headers,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"`
+}
+
+// Batch returns a handler that serves several operations in one HTTP request, to save round-trips.
+// The request body is a JSON array of sub-requests:
+//
+//	[
+//	  {"method": "GET", "path": "/users/1"},
+//	  {"method": "POST", "path": "/users", "headers": {"Idempotency-Key": "k1"}, "body": {"name": "gopher"}}
+//	]
+//
+// Each sub-request is dispatched through sub, in memory, and the handler responds with 200 and a JSON array of
+// sub-responses in the same order:
+//
+//	[
+//	  {"status": 200, "headers": {"Content-Type": ["application/json"]}, "body": {"id": 1}},
+//	  {"status": 201, "headers": {...}, "body": ...}
+//	]
+//
+// A sub-request inherits the headers of the batch request, except Content-Type and Content-Length, overridden by
+// its own headers. Its body, if any, is sent as JSON. It is canceled with the batch request, and has its
+// deadline, but it carries none of its context values, such as the route, the principal or the Transaction:
+// the sub-requests run concurrently, and go through the middlewares of sub on their own. A JSON sub-response body is embedded as is, any other body
+// is embedded as a JSON string.
+//
+// Errors are reported per item: a sub-request without method, or with a path that does not start with "/", gets
+// a 400 sub-response, and the failure of a sub-request does not affect the others. The whole batch is rejected
+// with 400 only when the body is not a JSON array of sub-requests or has more than DefaultMaxBatchSize items.
+// At most DefaultBatchConcurrency sub-requests run at the same time, so they must not depend on each other.
+func Batch(sub *ServeMux) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var requests []BatchRequest
+		if err := DecodeJSON(r, &requests, 0); err != nil {
+			return err
+		}
+		if len(requests) > DefaultMaxBatchSize {
+			return NewHTTPError(http.StatusBadRequest, "too many requests in the batch")
+		}
+
+		responses := make([]BatchResponse, len(requests))
+		sem := make(chan struct{}, DefaultBatchConcurrency)
+		var wg sync.WaitGroup
+		for i, req := range requests {
+			if req.Method == "" || !strings.HasPrefix(req.Path, "/") {
+				responses[i] = batchError(http.StatusBadRequest, "sub-request must have a method and an absolute path")
+				continue
+			}
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, req BatchRequest) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				responses[i] = serveBatchRequest(sub, r, req)
+			}(i, req)
+		}
+		wg.Wait()
+
+		return WriteJSON(w, http.StatusOK, responses)
+	}
+}
+
+// serveBatchRequest dispatches the sub-request through the mux and records its response.
+func serveBatchRequest(mux *ServeMux, parent *http.Request, req BatchRequest) BatchResponse {
+	sr, err := http.NewRequestWithContext(valuelessContext{parent.Context()}, req.Method, req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		return batchError(http.StatusBadRequest, "invalid sub-request")
+	}
+	sr.Header = parent.Header.Clone()
+	sr.Header.Del("Content-Type")
+	sr.Header.Del("Content-Length")
+	if len(req.Body) > 0 {
+		sr.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range req.Headers {
+		sr.Header.Set(k, v)
+	}
+	sr.RemoteAddr = parent.RemoteAddr
+	sr.Host = parent.Host
+
+	rec := &responseBuffer{header: make(http.Header)}
+	mux.ServeHTTP(rec, sr)
+
+	res := BatchResponse{Status: rec.statusCode(), Headers: rec.header}
+	body := rec.body.Bytes()
+	switch {
+	case len(body) == 0:
+	case isJSONContentType(rec.header.Get("Content-Type")) && json.Valid(body):
+		res.Body = body
+	default:
+		res.Body, _ = json.Marshal(string(body))
+	}
+	return res
+}
+
+// valuelessContext has the deadline and the cancellation of its parent context, but none of its values.
+type valuelessContext struct {
+	context.Context
+}
+
+// Value implements context.Context.
+func (valuelessContext) Value(any) any { return nil }
+
+// batchError creates a sub-response for a sub-request that could not be dispatched.
+func batchError(status int, msg string) BatchResponse {
+	body, _ := json.Marshal(msg)
+	return BatchResponse{Status: status, Body: body}
+}
@@ -0,0 +1,137 @@
+package httprouterx
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	api := NewServeMux()
+	api.HandleFunc("GET", "/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+		return WriteJSON(w, 200, map[string]string{"id": PathParams(r).ByName("id")})
+	})
+	api.HandleFunc("POST", "/users", func(w http.ResponseWriter, r *http.Request) error {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Key", r.Header.Get("Idempotency-Key"))
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.WriteHeader(201)
+		_, _ = w.Write(b)
+		return nil
+	})
+	api.HandleFunc("DELETE", "/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		return NewHTTPError(http.StatusForbidden, "")
+	})
+
+	mux := NewServeMux()
+	mux.HandleFunc("POST", "/batch", Batch(api))
+
+	body := `[
+		{"method": "GET", "path": "/users/1"},
+		{"method": "POST", "path": "/users", "headers": {"Idempotency-Key": "k1"}, "body": {"name": "gopher"}},
+		{"method": "DELETE", "path": "/users/1"},
+		{"method": "GET", "path": "/nope"},
+		{"path": "/users/1"}
+	]`
+	req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer t")
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+	expectTrue(t, res.Code == 200)
+
+	var got []BatchResponse
+	expectTrue(t, json.Unmarshal(res.Body.Bytes(), &got) == nil)
+	expectTrue(t, len(got) == 5)
+
+	expectTrue(t, got[0].Status == 200)
+	expectTrue(t, got[0].Headers.Get("X-Auth") == "Bearer t")
+	expectTrue(t, string(got[0].Body) == `{"id":"1"}`)
+
+	expectTrue(t, got[1].Status == 201)
+	expectTrue(t, got[1].Headers.Get("X-Key") == "k1")
+	expectTrue(t, got[1].Headers.Get("Content-Type") == "application/json")
+	expectTrue(t, string(got[1].Body) == `{"name":"gopher"}`)
+
+	expectTrue(t, got[2].Status == 403)
+	var msg string
+	expectTrue(t, json.Unmarshal(got[2].Body, &msg) == nil)
+	expectTrue(t, strings.Contains(msg, "Forbidden"))
+
+	expectTrue(t, got[3].Status == 404)
+	expectTrue(t, got[4].Status == 400)
+}
+
+func TestBatch_InvalidBody(t *testing.T) {
+	h := Batch(NewServeMux())
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "not an array", body: `{"method": "GET"}`},
+		{name: "too many", body: "[" + strings.Repeat(`{"method":"GET","path":"/"},`, DefaultMaxBatchSize) + `{"method":"GET","path":"/"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/batch", strings.NewReader(tt.body)))
+			expectHTTPError(t, err, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestBatch_Concurrent(t *testing.T) {
+	type parentKey struct{}
+	var (
+		mu        sync.Mutex
+		summaries []ResponseSummary
+	)
+	api := NewServeMux(Options.OnResponse(func(s ResponseSummary) {
+		mu.Lock()
+		defer mu.Unlock()
+		summaries = append(summaries, s)
+	}))
+	api.HandleFunc("GET", "/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		// the values of the batch request are not inherited, its cancellation is.
+		if r.Context().Value(parentKey{}) != nil || r.Context().Done() == nil {
+			return NewHTTPError(http.StatusInternalServerError, "")
+		}
+		return WriteJSON(w, 200, map[string]string{"id": PathParams(r).ByName("id")})
+	})
+
+	mux := NewServeMux(Options.OnResponse(func(ResponseSummary) {}))
+	mux.HandleFunc("POST", "/batch", func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithCancel(context.WithValue(r.Context(), parentKey{}, true))
+		defer cancel()
+		return Batch(api)(w, r.WithContext(ctx))
+	})
+
+	var items []string
+	for i := 0; i < 20; i++ {
+		items = append(items, `{"method":"GET","path":"/users/`+strconv.Itoa(i)+`"}`)
+	}
+	res := mux.TestRequest("POST", "/batch", strings.NewReader("["+strings.Join(items, ",")+"]"))
+	expectTrue(t, res.Code == http.StatusOK)
+
+	var responses []BatchResponse
+	expectTrue(t, json.Unmarshal(res.Body.Bytes(), &responses) == nil)
+	expectTrue(t, len(responses) == 20)
+	for i, sub := range responses {
+		expectTrue(t, sub.Status == http.StatusOK)
+		expectTrue(t, string(sub.Body) == `{"id":"`+strconv.Itoa(i)+`"}`)
+	}
+
+	// each sub-request has its own summary.
+	expectTrue(t, len(summaries) == 20)
+	for _, s := range summaries {
+		expectTrue(t, s.Route.Path == "/users/:id" && s.Status == http.StatusOK)
+	}
+}
@@ -0,0 +1,180 @@
+package httprouterx
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BindQuery populates the struct pointed by dst from the query parameters of the request.
+//
+// Only the fields tagged with `query:"name"` are populated. The tag accepts a "required" option,
+// e.g. `query:"page,required"`, and a default value can be given with the `default:"..."` tag, which is used
+// when the parameter is absent. Supported field types are strings, bools, integers, floats, time.Duration,
+// time.Time, types implementing encoding.TextUnmarshaler, pointers to them, and slices of them, which are
+// populated from repeated parameters (e.g. ?id=1&id=2). The layout of time.Time fields is RFC 3339 by default,
+// and can be changed with the `layout:"..."` tag.
+//
+// All problems are aggregated into a single *HTTPError with status 400.
+func BindQuery(r *http.Request, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("httprouterx: BindQuery: dst must be a non-nil pointer to a struct")
+	}
+
+	query := r.URL.Query()
+	var errs []error
+	bindStruct(rv.Elem(), func(f reflect.StructField, v reflect.Value) {
+		tag, ok := f.Tag.Lookup("query")
+		if !ok || tag == "-" {
+			return
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		values, present := query[name]
+		if !present || len(values) == 0 {
+			if def, ok := f.Tag.Lookup("default"); ok {
+				values = []string{def}
+			} else if opts == "required" {
+				errs = append(errs, fmt.Errorf("%s: is required", name))
+				return
+			} else {
+				return
+			}
+		}
+
+		if err := setFieldValues(v, values, f.Tag.Get("layout")); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return &HTTPError{
+		Status:  http.StatusBadRequest,
+		Message: "invalid query parameters: " + strings.Join(msgs, "; "),
+		Err:     errors.Join(errs...),
+	}
+}
+
+// bindStruct calls fn for each settable field of the struct, including the fields of embedded structs.
+func bindStruct(v reflect.Value, fn func(reflect.StructField, reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			bindStruct(fv, fn)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		fn(f, fv)
+	}
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setFieldValues sets the field from the raw values. Slices receive all values, other types the first one.
+func setFieldValues(v reflect.Value, values []string, layout string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, raw := range values {
+			if err := setFieldValue(s.Index(i), raw, layout); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return setFieldValue(v, values[0], layout)
+}
+
+// setFieldValue converts raw to the type of v and sets it.
+func setFieldValue(v reflect.Value, raw, layout string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setFieldValue(ptr.Elem(), raw, layout); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, raw)
+		if err != nil {
+			return fmt.Errorf("invalid time %q, expected layout %q", raw, layout)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return fmt.Errorf("invalid value %q: %w", raw, err)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", raw)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pagination struct {
+	Page  int `query:"page" default:"1"`
+	Limit int `query:"limit" default:"20"`
+}
+
+type listFilter struct {
+	pagination
+	Query   string        `query:"q,required"`
+	IDs     []int64       `query:"id"`
+	Active  *bool         `query:"active"`
+	Score   float64       `query:"score"`
+	Since   time.Time     `query:"since"`
+	Day     time.Time     `query:"day" layout:"2006-01-02"`
+	Timeout time.Duration `query:"timeout" default:"5s"`
+	Ignored string
+}
+
+func TestBindQuery(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?q=go&id=1&id=2&active=true&score=1.5&since=2024-01-02T03:04:05Z&day=2024-02-03&limit=50", nil)
+
+	var f listFilter
+	err := BindQuery(req, &f)
+	expectTrue(t, err == nil)
+	expectTrue(t, f.Query == "go")
+	expectTrue(t, len(f.IDs) == 2 && f.IDs[0] == 1 && f.IDs[1] == 2)
+	expectTrue(t, f.Active != nil && *f.Active)
+	expectTrue(t, f.Score == 1.5)
+	expectTrue(t, f.Since.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	expectTrue(t, f.Day.Equal(time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)))
+	expectTrue(t, f.Timeout == 5*time.Second)
+	expectTrue(t, f.Page == 1)
+	expectTrue(t, f.Limit == 50)
+	expectTrue(t, f.Ignored == "")
+}
+
+func TestBindQuery_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		problems []string
+	}{
+		{name: "missing required", target: "/", problems: []string{"q: is required"}},
+		{name: "invalid integer", target: "/?q=a&page=x", problems: []string{"page: invalid integer"}},
+		{name: "integer overflow", target: "/?q=a&id=99999999999999999999", problems: []string{"id: invalid integer"}},
+		{name: "invalid bool", target: "/?q=a&active=maybe", problems: []string{"active: invalid bool"}},
+		{name: "invalid time layout", target: "/?q=a&day=03-02-2024", problems: []string{"day: invalid time"}},
+		{name: "aggregated", target: "/?score=x&timeout=1y", problems: []string{"q: is required", "score: invalid number", "timeout: invalid duration"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f listFilter
+			err := BindQuery(httptest.NewRequest("GET", tt.target, nil), &f)
+
+			var httpErr *HTTPError
+			expectTrue(t, errors.As(err, &httpErr))
+			expectTrue(t, httpErr.Status == 400)
+			for _, p := range tt.problems {
+				expectTrue(t, strings.Contains(httpErr.Message, p))
+			}
+		})
+	}
+}
+
+func TestBindQuery_InvalidDestination(t *testing.T) {
+	var f listFilter
+	err := BindQuery(httptest.NewRequest("GET", "/", nil), f)
+	expectTrue(t, err != nil)
+
+	var httpErr *HTTPError
+	expectFalse(t, errors.As(err, &httpErr))
+}
+
+func TestBindQuery_DefaultsExplicitZero(t *testing.T) {
+	var q struct {
+		Limit  int  `query:"limit" default:"20"`
+		Active bool `query:"active" default:"true"`
+	}
+	err := BindQuery(httptest.NewRequest("GET", "/?limit=0&active=false", nil), &q)
+	expectTrue(t, err == nil)
+	expectTrue(t, q.Limit == 0)
+	expectFalse(t, q.Active)
+}
@@ -0,0 +1,62 @@
+package httprouterx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxBodyBytes is the limit used by MaxBodyBytes when the given default is not positive.
+const DefaultMaxBodyBytes = 10 << 20
+
+// MaxBodyBytes enforces the body limit declared by the matched route in Route.MaxBodyBytes, so the limits are
+// visible in the route table and enforced in a single place, e.g. with Options.Middleware. Routes without a
+// limit get defaultMax (DefaultMaxBodyBytes if not positive), and routes with a negative limit are not limited.
+//
+// A request whose Content-Length exceeds the limit is rejected with 413 Request Entity Too Large before the
+// handler is called. Otherwise, reading the body past the limit fails with an ErrBodyTooLarge *HTTPError, which
+// the handler can return as is.
+func MaxBodyBytes(defaultMax int64) Middleware {
+	if defaultMax <= 0 {
+		defaultMax = DefaultMaxBodyBytes
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			limit := defaultMax
+			if route, ok := CurrentRoute(r); ok && route.MaxBodyBytes != 0 {
+				limit = route.MaxBodyBytes
+			}
+			if limit < 0 || r.Body == nil || r.Body == http.NoBody {
+				return next.ServeHTTP(w, r)
+			}
+
+			if r.ContentLength > limit {
+				return bodyTooLargeError(limit, nil)
+			}
+			r.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, limit), limit: limit}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitedBody converts the error of an http.MaxBytesReader to an *HTTPError.
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+}
+
+// Read implements io.Reader.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		err = bodyTooLargeError(b.limit, err)
+	}
+	return n, err
+}
+
+// bodyTooLargeError is the error of a request body over the limit.
+func bodyTooLargeError(limit int64, err error) error {
+	return ErrBodyTooLarge.withCause(fmt.Sprintf("request body must not exceed %d bytes", limit), err)
+}
@@ -0,0 +1,52 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	mux := NewServeMux(Options.Middleware(MaxBodyBytes(8)))
+	read := func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.ReadAll(r.Body)
+		return err
+	}
+	mux.Route(Route{Method: "POST", Path: "/default", Handler: read})
+	mux.Route(Route{Method: "POST", Path: "/upload", Handler: read, MaxBodyBytes: 16})
+	mux.Route(Route{Method: "POST", Path: "/unlimited", Handler: read, MaxBodyBytes: -1})
+
+	tests := []struct {
+		path    string
+		size    int
+		chunked bool
+		status  int
+	}{
+		{path: "/default", size: 8, status: 200},
+		{path: "/default", size: 9, status: 413},
+		{path: "/default", size: 9, chunked: true, status: 413},
+		{path: "/upload", size: 16, status: 200},
+		{path: "/upload", size: 17, status: 413},
+		{path: "/upload", size: 17, chunked: true, status: 413},
+		{path: "/unlimited", size: 1 << 10, status: 200},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", tt.path, strings.NewReader(strings.Repeat("x", tt.size)))
+		if tt.chunked {
+			req.ContentLength = -1
+		}
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, req)
+		expectTrue(t, res.Code == tt.status)
+	}
+
+	// the routes expose their limit.
+	for _, route := range mux.Routes() {
+		if route.Path == "/upload" {
+			expectTrue(t, route.MaxBodyBytes == 16)
+		}
+	}
+}
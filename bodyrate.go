@@ -0,0 +1,127 @@
+package httprouterx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultMinBodyRateWindow is the window over which MinBodyRate measures the upload rate.
+const DefaultMinBodyRateWindow = 5 * time.Second
+
+// ErrBodyTooSlow is wrapped by the error returned when the client sends the body too slowly.
+var ErrBodyTooSlow = errors.New("request body is sent too slowly")
+
+// MinBodyRateConfig is the configuration for MinBodyRateWithConfig.
+type MinBodyRateConfig struct {
+	// BytesPerSec is the minimum rate.
+	BytesPerSec int64
+
+	// Window is the period over which the rate is averaged. Default DefaultMinBodyRateWindow.
+	Window time.Duration
+
+	// Clock is used to measure the rate. Default SystemClock.
+	Clock Clock
+}
+
+// MinBodyRate defends against slow upload (slow loris) attacks, where the client holds a connection by sending
+// the body of the request very slowly. The body of POST, PUT and PATCH requests is wrapped to measure the rate at
+// which it is read, and reading fails with a 408 Request Timeout *HTTPError (wrapping ErrBodyTooSlow) when less
+// than bytesPerSec were received on average over a window of DefaultMinBodyRateWindow. The connection is then
+// closed after the response.
+//
+// The rate is averaged over a window sliding with each read, tracked in ten sub-intervals, so a client cannot
+// burst once per window and stall the rest of it. The first window acts as a grace period for slow starts, and
+// short pauses are tolerated as long as the average over the last window is high enough. A longer window is more
+// tolerant with bursty clients, a shorter one catches the attacks sooner.
+//
+// The check happens when a read returns, so a client that stops sending altogether is not caught by this
+// middleware: combine it with the ReadTimeout of the http.Server.
+func MinBodyRate(bytesPerSec int64) Middleware {
+	return MinBodyRateWithConfig(MinBodyRateConfig{BytesPerSec: bytesPerSec})
+}
+
+// MinBodyRateWithConfig is like MinBodyRate, but with a custom window and clock.
+func MinBodyRateWithConfig(cfg MinBodyRateConfig) Middleware {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultMinBodyRateWindow
+	}
+	clock := clockOrSystem(cfg.Clock)
+	minBytes := int64(float64(cfg.BytesPerSec) * cfg.Window.Seconds())
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				return next.ServeHTTP(w, r)
+			}
+			if r.Body == nil || r.Body == http.NoBody {
+				return next.ServeHTTP(w, r)
+			}
+
+			r.Body = &minRateReader{
+				ReadCloser: r.Body,
+				w:          w,
+				clock:      clock,
+				window:     cfg.Window,
+				minBytes:   minBytes,
+				start:      clock.Now(),
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// minRateBuckets is the number of sub-intervals of the window of MinBodyRate.
+const minRateBuckets = 10
+
+// minRateReader fails once less than minBytes are read during the last window. The bytes are counted per
+// sub-interval of the window, in a ring indexed by the number of the sub-interval since start.
+type minRateReader struct {
+	io.ReadCloser
+	w        http.ResponseWriter
+	clock    Clock
+	window   time.Duration
+	minBytes int64
+	start    time.Time
+	counts   [minRateBuckets]int64
+	slots    [minRateBuckets]int64 // the sub-interval counted by each bucket.
+	err      error
+}
+
+func (m *minRateReader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	n, err := m.ReadCloser.Read(p)
+
+	elapsed := m.clock.Now().Sub(m.start)
+	sub := max(m.window/minRateBuckets, 1)
+	slot := int64(elapsed / sub)
+	if i := slot % minRateBuckets; m.slots[i] != slot {
+		m.slots[i], m.counts[i] = slot, int64(n)
+	} else {
+		m.counts[i] += int64(n)
+	}
+	if err != nil || elapsed < m.window {
+		return n, err
+	}
+
+	// the last window spans the current sub-interval, up to now, and the previous ones.
+	var received int64
+	for i, c := range m.counts {
+		if m.slots[i] > slot-minRateBuckets {
+			received += c
+		}
+	}
+	span := elapsed - time.Duration(slot-minRateBuckets+1)*sub
+	if float64(received) < float64(m.minBytes)*float64(span)/float64(m.window) {
+		m.w.Header().Set("Connection", "close")
+		m.err = &HTTPError{Status: http.StatusRequestTimeout, Message: "request body is sent too slowly", Err: ErrBodyTooSlow}
+		return n, m.err
+	}
+	return n, nil
+}
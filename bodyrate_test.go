@@ -0,0 +1,104 @@
+package httprouterx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tickingReader returns one chunk per read and advances the clock before each read.
+type tickingReader struct {
+	clock *fakeClock
+	delay time.Duration
+	chunk string
+	count int
+}
+
+func (t *tickingReader) Read(p []byte) (int, error) {
+	if t.count == 0 {
+		return 0, io.EOF
+	}
+	t.count--
+	t.clock.Advance(t.delay)
+	return copy(p, t.chunk), nil
+}
+
+func TestMinBodyRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		delay  time.Duration
+		count  int
+		status int
+	}{
+		{name: "fast", method: "POST", delay: 100 * time.Millisecond, count: 100},
+		{name: "slow but within grace", method: "POST", delay: time.Second, count: 4},
+		{name: "slow", method: "PUT", delay: time.Second, count: 100, status: http.StatusRequestTimeout},
+		{name: "stall", method: "PATCH", delay: 10 * time.Second, count: 100, status: http.StatusRequestTimeout},
+		{name: "no body method", method: "GET", delay: time.Second, count: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			h := MinBodyRateWithConfig(MinBodyRateConfig{BytesPerSec: 100, Window: 5 * time.Second, Clock: clock}).
+				Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+					_, err := io.ReadAll(r.Body)
+					return err
+				}))
+
+			// 10 bytes per read.
+			req := httptest.NewRequest(tt.method, "/upload", strings.NewReader(""))
+			req.Body = io.NopCloser(&tickingReader{clock: clock, delay: tt.delay, chunk: "0123456789", count: tt.count})
+			res := httptest.NewRecorder()
+			err := h.ServeHTTP(res, req)
+			if tt.status == 0 {
+				expectTrue(t, err == nil)
+				return
+			}
+			expectHTTPError(t, err, tt.status)
+			expectTrue(t, errors.Is(err, ErrBodyTooSlow))
+			expectTrue(t, res.Header().Get("Connection") == "close")
+		})
+	}
+}
+
+// burstReader sends burst chunks without delay, then stalls for delay, and repeats.
+type burstReader struct {
+	clock *fakeClock
+	delay time.Duration
+	burst int
+	count int
+	sent  int
+}
+
+func (b *burstReader) Read(p []byte) (int, error) {
+	if b.count == 0 {
+		return 0, io.EOF
+	}
+	b.count--
+	if b.sent++; b.sent%(b.burst+1) == 0 {
+		b.clock.Advance(b.delay)
+	}
+	return copy(p, "0123456789"), nil
+}
+
+func TestMinBodyRate_Burst(t *testing.T) {
+	clock := newFakeClock()
+	h := MinBodyRateWithConfig(MinBodyRateConfig{BytesPerSec: 100, Window: 5 * time.Second, Clock: clock}).
+		Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			_, err := io.ReadAll(r.Body)
+			return err
+		}))
+
+	// 500 bytes at the start of each window, enough for the average of a window, then a stall for the whole
+	// window: the bursts fall out of the sliding window during the stall.
+	req := httptest.NewRequest("POST", "/upload", nil)
+	req.Body = io.NopCloser(&burstReader{clock: clock, delay: 5 * time.Second, burst: 50, count: 1000})
+	err := h.ServeHTTP(httptest.NewRecorder(), req)
+	expectTrue(t, errors.Is(err, ErrBodyTooSlow))
+}
@@ -0,0 +1,193 @@
+package httprouterx
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets all requests through, while tracking the failures.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all requests until the cooldown elapses.
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probe requests through to test the recovery.
+	BreakerHalfOpen
+)
+
+// String implements fmt.Stringer.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig is the configuration for CircuitBreaker.
+type BreakerConfig struct {
+	// FailureRatio is the ratio of failed requests in the window that trips the breaker. Default 0.5.
+	FailureRatio float64
+
+	// MinRequests is the minimum number of requests in the window before the breaker can trip. Default 20.
+	MinRequests int
+
+	// Window is the duration of the rolling window tracking the failures. Default 10 seconds.
+	Window time.Duration
+
+	// Cooldown is how long the breaker stays open before testing the recovery. Default 30 seconds.
+	Cooldown time.Duration
+
+	// HalfOpenRequests is the number of probe requests let through while half-open. The breaker closes when all
+	// of them succeed, and opens again as soon as one fails. Default 1.
+	HalfOpenRequests int
+
+	// IsFailure classifies a response. Default: a returned error or a 5xx status.
+	IsFailure func(status int, err error) bool
+
+	// OnStateChange is called on every state transition, e.g. to export the state to a monitoring system.
+	// It is called while holding the breaker lock, so it must not block.
+	OnStateChange func(from, to BreakerState)
+
+	// Clock is used for the window and the cooldown. Default SystemClock.
+	Clock Clock
+}
+
+// CircuitBreaker protects a struggling service by fast-failing requests with 503 Service Unavailable once the
+// failure ratio exceeds the threshold, instead of piling more load on it. After the cooldown, it lets a few
+// probe requests through (half-open), and closes again if they succeed. The state machine is safe for
+// concurrent use.
+func CircuitBreaker(cfg BreakerConfig) Middleware {
+	if cfg.FailureRatio <= 0 || cfg.FailureRatio > 1 {
+		cfg.FailureRatio = 0.5
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 20
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(status int, err error) bool { return err != nil || status >= 500 }
+	}
+	clock := clockOrSystem(cfg.Clock)
+	cb := &circuitBreaker{cfg: cfg, clock: clock, window: newRollingWindow(cfg.Window, 10, clock)}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			state, retryAfter, ok := cb.allow()
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				return NewHTTPError(http.StatusServiceUnavailable, "circuit breaker is "+state.String())
+			}
+
+			// a panic is recorded as a failure, otherwise a panicking probe would keep the breaker half-open.
+			recorded := false
+			defer func() {
+				if !recorded {
+					cb.record(state, true)
+				}
+			}()
+
+			sw := newStatusWriter(w)
+			err := next.ServeHTTP(sw, r)
+			recorded = true
+			cb.record(state, cfg.IsFailure(sw.statusCode(), err))
+			return err
+		})
+	}
+}
+
+type circuitBreaker struct {
+	cfg    BreakerConfig
+	clock  Clock
+	window *rollingWindow
+
+	mu        sync.Mutex
+	state     BreakerState
+	openedAt  time.Time
+	probes    int // in-flight probes while half-open.
+	successes int // successful probes while half-open.
+}
+
+// allow reports whether the request can go through, and the state it goes through in.
+func (cb *circuitBreaker) allow() (BreakerState, time.Duration, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == BreakerOpen {
+		elapsed := cb.clock.Now().Sub(cb.openedAt)
+		if elapsed < cb.cfg.Cooldown {
+			return BreakerOpen, cb.cfg.Cooldown - elapsed, false
+		}
+		cb.transition(BreakerHalfOpen)
+	}
+
+	if cb.state == BreakerHalfOpen {
+		if cb.probes+cb.successes >= cb.cfg.HalfOpenRequests {
+			return BreakerHalfOpen, time.Second, false
+		}
+		cb.probes++
+	}
+	return cb.state, 0, true
+}
+
+// record records the result of a request that went through in the given state.
+func (cb *circuitBreaker) record(state BreakerState, failed bool) {
+	if state == BreakerClosed {
+		cb.window.add(failed)
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch {
+	case state == BreakerHalfOpen && cb.state == BreakerHalfOpen:
+		cb.probes--
+		if failed {
+			cb.transition(BreakerOpen)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.cfg.HalfOpenRequests {
+			cb.transition(BreakerClosed)
+		}
+	case state == BreakerClosed && cb.state == BreakerClosed && failed:
+		total, failures := cb.window.counts()
+		if total >= cb.cfg.MinRequests && float64(failures)/float64(total) >= cb.cfg.FailureRatio {
+			cb.transition(BreakerOpen)
+		}
+	}
+}
+
+// transition moves to the new state. It must be called with the lock held.
+func (cb *circuitBreaker) transition(to BreakerState) {
+	from := cb.state
+	cb.state = to
+	cb.probes, cb.successes = 0, 0
+	switch to {
+	case BreakerOpen:
+		cb.openedAt = cb.clock.Now()
+	case BreakerClosed:
+		cb.window.reset()
+	}
+	if cb.cfg.OnStateChange != nil {
+		cb.cfg.OnStateChange(from, to)
+	}
+}
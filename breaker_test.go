@@ -0,0 +1,134 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := newFakeClock()
+	var transitions []string
+	mid := CircuitBreaker(BreakerConfig{
+		FailureRatio:     0.5,
+		MinRequests:      4,
+		Window:           10 * time.Second,
+		Cooldown:         5 * time.Second,
+		HalfOpenRequests: 2,
+		Clock:            clock,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+
+	status := 200
+	h := mid.Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(status)
+		return nil
+	}))
+
+	serve := func() (int, string) {
+		res := httptest.NewRecorder()
+		if err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil)); err != nil {
+			return expectHTTPError(t, err, 503).Status, res.Header().Get("Retry-After")
+		}
+		return res.Code, ""
+	}
+
+	// closed: 2 failures out of 4 trips the breaker.
+	expectTrue(t, first(serve()) == 200)
+	expectTrue(t, first(serve()) == 200)
+	status = 500
+	expectTrue(t, first(serve()) == 500)
+	expectTrue(t, len(transitions) == 0)
+	expectTrue(t, first(serve()) == 500)
+	expectTrue(t, len(transitions) == 1 && transitions[0] == "closed->open")
+
+	// open: requests fail fast until the cooldown elapses.
+	code, retryAfter := serve()
+	expectTrue(t, code == 503)
+	expectTrue(t, retryAfter == "5")
+	clock.Advance(3 * time.Second)
+	_, retryAfter = serve()
+	expectTrue(t, retryAfter == "2")
+
+	// half-open: a failed probe opens the breaker again.
+	clock.Advance(2 * time.Second)
+	expectTrue(t, first(serve()) == 500)
+	expectTrue(t, transitions[1] == "open->half-open" && transitions[2] == "half-open->open")
+	expectTrue(t, first(serve()) == 503)
+
+	// half-open: successful probes close the breaker.
+	clock.Advance(5 * time.Second)
+	status = 200
+	expectTrue(t, first(serve()) == 200)
+	expectTrue(t, first(serve()) == 200)
+	expectTrue(t, transitions[len(transitions)-1] == "half-open->closed")
+
+	// closed: the window was reset, so one failure does not trip it.
+	status = 500
+	expectTrue(t, first(serve()) == 500)
+	expectTrue(t, first(serve()) == 500)
+	expectTrue(t, transitions[len(transitions)-1] == "half-open->closed")
+}
+
+func TestCircuitBreaker_LimitsProbes(t *testing.T) {
+	clock := newFakeClock()
+	cb := &circuitBreaker{cfg: BreakerConfig{Cooldown: time.Second, HalfOpenRequests: 1}, clock: clock, window: newRollingWindow(time.Second, 1, clock)}
+	cb.transition(BreakerOpen)
+	clock.Advance(time.Second)
+
+	state, _, ok := cb.allow()
+	expectTrue(t, ok && state == BreakerHalfOpen)
+
+	// the probe is still in flight.
+	_, _, ok = cb.allow()
+	expectFalse(t, ok)
+}
+
+func TestCircuitBreaker_PanickingProbe(t *testing.T) {
+	clock := newFakeClock()
+	var transitions []string
+	mid := CircuitBreaker(BreakerConfig{
+		MinRequests: 1,
+		Cooldown:    time.Second,
+		Clock:       clock,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+
+	panics := true
+	h := mid.Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if panics {
+			panic("boom")
+		}
+		return nil
+	}))
+
+	serve := func() (recovered any, err error) {
+		defer func() { recovered = recover() }()
+		return nil, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+
+	// the panic is propagated, and counted as a failure.
+	recovered, _ := serve()
+	expectTrue(t, recovered == "boom")
+	expectTrue(t, len(transitions) == 1 && transitions[0] == "closed->open")
+
+	// the panicking probe opens the breaker again, instead of keeping it half-open.
+	clock.Advance(time.Second)
+	recovered, _ = serve()
+	expectTrue(t, recovered == "boom")
+	expectTrue(t, transitions[1] == "open->half-open" && transitions[2] == "half-open->open")
+
+	// so the next probe, after the cooldown, can close it.
+	clock.Advance(time.Second)
+	panics = false
+	_, err := serve()
+	expectTrue(t, err == nil)
+	expectTrue(t, transitions[len(transitions)-1] == "half-open->closed")
+}
+
+func first(code int, _ string) int { return code }
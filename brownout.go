@@ -0,0 +1,98 @@
+package httprouterx
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BrownoutWindow is a time range during which a percentage of the requests is rejected.
+type BrownoutWindow struct {
+	// Start is inclusive, End is exclusive.
+	Start, End time.Time
+
+	// Percent is the percentage of requests to reject, from 0 to 100.
+	Percent int
+}
+
+// BrownoutConfig is the configuration for BrownoutWithConfig.
+type BrownoutConfig struct {
+	// Schedule is the list of brownout windows. If windows overlap, the first one wins.
+	Schedule []BrownoutWindow
+
+	// Logger is used to log each rejection. Default slog.Default().
+	Logger *slog.Logger
+
+	// Clock is used to find the current window. Default SystemClock.
+	Clock Clock
+}
+
+// Brownout helps retiring an endpoint: during each window of the schedule, it rejects the given percentage of
+// requests with 503 Service Unavailable and a Retry-After header pointing to the end of the window. Scheduling
+// windows of increasing percentage before the removal forces the remaining clients to notice, while there is
+// still time to migrate. Outside the windows, requests are served normally.
+//
+// Rejections are spread evenly rather than randomly: with 25%, exactly one request in four is rejected.
+// Each rejection is logged with the route, so the clients that still depend on the endpoint can be tracked.
+func Brownout(schedule []BrownoutWindow) Middleware {
+	return BrownoutWithConfig(BrownoutConfig{Schedule: schedule})
+}
+
+// BrownoutWithConfig is like Brownout, but with a custom logger and clock.
+func BrownoutWithConfig(cfg BrownoutConfig) Middleware {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	clock := clockOrSystem(cfg.Clock)
+	schedule := append([]BrownoutWindow(nil), cfg.Schedule...)
+
+	var (
+		mu     sync.Mutex
+		counts = make([]uint64, len(schedule))
+	)
+
+	// reject reports whether the request must be rejected, and the window it falls into.
+	reject := func(now time.Time) (BrownoutWindow, bool) {
+		for i, window := range schedule {
+			if now.Before(window.Start) || !now.Before(window.End) {
+				continue
+			}
+			percent := uint64(min(max(window.Percent, 0), 100))
+
+			mu.Lock()
+			n := counts[i]
+			counts[i]++
+			mu.Unlock()
+
+			// rejects when the running total of rejections should increase, which spreads them evenly.
+			return window, (n+1)*percent/100 > n*percent/100
+		}
+		return BrownoutWindow{}, false
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			now := clock.Now()
+			window, ok := reject(now)
+			if !ok {
+				return next.ServeHTTP(w, r)
+			}
+
+			route, _ := CurrentRoute(r)
+			cfg.Logger.InfoContext(r.Context(), "brownout rejected request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"route", route.Path,
+				"percent", window.Percent,
+				"window_end", window.End,
+				"client_ip", clientIP(r),
+				"user_agent", r.UserAgent(),
+			)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(window.End.Sub(now).Seconds()))))
+			return NewHTTPError(http.StatusServiceUnavailable, "endpoint is being retired, scheduled brownout in progress")
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package httprouterx
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBrownout(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now().Add(time.Hour)
+
+	var logs bytes.Buffer
+	mux := NewServeMux()
+	mux.Route(Route{Method: "GET", Path: "/v1/legacy", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(200)
+		return nil
+	}}, BrownoutWithConfig(BrownoutConfig{
+		Schedule: []BrownoutWindow{
+			{Start: start, End: start.Add(time.Hour), Percent: 25},
+			{Start: start.Add(24 * time.Hour), End: start.Add(25 * time.Hour), Percent: 100},
+		},
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		Clock:  clock,
+	}))
+
+	rejected := func(n int) (count int, retryAfter string) {
+		for i := 0; i < n; i++ {
+			res := httptest.NewRecorder()
+			mux.ServeHTTP(res, httptest.NewRequest("GET", "/v1/legacy", nil))
+			if res.Code == http.StatusServiceUnavailable {
+				count++
+				retryAfter = res.Header().Get("Retry-After")
+			}
+		}
+		return count, retryAfter
+	}
+
+	// before the first window.
+	n, _ := rejected(100)
+	expectTrue(t, n == 0)
+
+	clock.Advance(time.Hour + 30*time.Minute)
+	n, retryAfter := rejected(100)
+	expectTrue(t, n == 25)
+	expectTrue(t, retryAfter == "1800")
+	expectTrue(t, strings.Count(logs.String(), "brownout rejected request") == 25)
+	expectTrue(t, strings.Contains(logs.String(), "route=/v1/legacy"))
+
+	// between the windows.
+	clock.Advance(2 * time.Hour)
+	n, _ = rejected(100)
+	expectTrue(t, n == 0)
+
+	clock.Advance(22 * time.Hour)
+	n, _ = rejected(100)
+	expectTrue(t, n == 100)
+
+	// after the schedule.
+	clock.Advance(2 * time.Hour)
+	n, _ = rejected(100)
+	expectTrue(t, n == 0)
+}
+
+func TestBrownout_Spread(t *testing.T) {
+	clock := newFakeClock()
+	h := BrownoutWithConfig(BrownoutConfig{
+		Schedule: []BrownoutWindow{{Start: clock.Now(), End: clock.Now().Add(time.Hour), Percent: 50}},
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Clock:    clock,
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	for i := 0; i < 10; i++ {
+		err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, (err != nil) == (i%2 == 1))
+	}
+}
@@ -0,0 +1,188 @@
+package httprouterx
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// responseBuffer is an http.ResponseWriter that keeps the whole response in memory, so middlewares can
+// inspect or rewrite it before sending it to the client with flushTo.
+//
+// A buffer created by bufferResponse honors DisableBuffering: once the handler has opted out, the buffer sends
+// what it holds to the underlying writer at the next write or flush, and passes everything through from then on.
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+
+	dst       http.ResponseWriter
+	buffering *bufferingFlag
+	streaming bool
+
+	// merge makes the passthrough keep the headers of dst that are not in the buffer, for buffers that do not
+	// start with a copy of them.
+	merge bool
+
+	// limit makes the buffer switch to the passthrough once the body would exceed it, like after DisableBuffering,
+	// for middlewares that give up on large responses. Zero means no limit.
+	limit    int64
+	overflow bool
+}
+
+// bufferingFlag is the flag set by DisableBuffering, shared by all the buffers of a request.
+type bufferingFlag struct {
+	disabled bool
+}
+
+// DisableBuffering tells the response-buffering middlewares that the handler streams its response, e.g. for
+// server-sent events or large downloads, so they must pass it through instead of holding it in memory. The
+// handler calls it before writing, or at least before flushing, the response: the bytes written so far are sent
+// right away, and the following writes and flushes go straight to the client.
+//
+// The middlewares then skip their processing of the response: FieldFilter, JSONKeyCase, UTCTimestamps and
+// ValidateResponse send it untouched, ContentDigest does not digest it, ETagMiddleware does not tag it, Cache
+// and ServeStaleOnError neither store nor replace it, WithFallback (and so ReadReplicaFailover) can no longer
+// fall back, and Transaction sends it before the commit. It is a no-op when no such middleware is used.
+func DisableBuffering(r *http.Request) {
+	if flag, ok := r.Context().Value(bufferingKey).(*bufferingFlag); ok {
+		flag.disabled = true
+	}
+}
+
+// newResponseBuffer creates a responseBuffer that starts with a copy of the headers of w, so the buffered
+// handler sees the headers already set by the outer middlewares.
+func newResponseBuffer(w http.ResponseWriter) *responseBuffer {
+	return &responseBuffer{header: w.Header().Clone()}
+}
+
+// bufferResponse is like newResponseBuffer, but the buffer honors DisableBuffering. The returned request must be
+// passed to the buffered handler.
+func bufferResponse(w http.ResponseWriter, r *http.Request) (*responseBuffer, *http.Request) {
+	flag, ok := r.Context().Value(bufferingKey).(*bufferingFlag)
+	if !ok {
+		flag = &bufferingFlag{}
+		r = r.WithContext(context.WithValue(r.Context(), bufferingKey, flag))
+	}
+	buf := newResponseBuffer(w)
+	buf.dst, buf.buffering = w, flag
+	return buf, r
+}
+
+// Header implements http.ResponseWriter.
+func (b *responseBuffer) Header() http.Header { return b.header }
+
+// Write implements http.ResponseWriter.
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	if b.limit > 0 && int64(b.body.Len()+len(p)) > b.limit {
+		b.overflow = true
+	}
+	if b.stream() {
+		return b.dst.Write(p)
+	}
+	return b.body.Write(p)
+}
+
+// WriteHeader implements http.ResponseWriter. Only the first call is recorded, just like the real writer.
+// Informational (1xx) statuses are not the final status: they are sent right away to the underlying writer, if
+// any, e.g. for SendContinue.
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.stream() {
+		if b.status == 0 && (status < 100 || status > 199) {
+			b.status = status
+		}
+		b.dst.WriteHeader(status)
+		return
+	}
+	if status >= 100 && status < 200 {
+		if b.dst != nil && b.status == 0 {
+			b.dst.WriteHeader(status)
+		}
+		return
+	}
+	if b.status != 0 {
+		return
+	}
+	b.status = status
+}
+
+// Flush implements http.Flusher. It is a no-op unless the handler has called DisableBuffering.
+func (b *responseBuffer) Flush() {
+	if !b.stream() {
+		return
+	}
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	if f, ok := b.dst.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// stream reports whether the buffer passes the response through, switching to it if the handler has called
+// DisableBuffering, or the body has exceeded the limit, since the last call.
+func (b *responseBuffer) stream() bool {
+	if b.streaming || b.buffering == nil || !b.buffering.disabled && !b.overflow {
+		return b.streaming
+	}
+	b.streaming = true
+	if b.merge {
+		for k, v := range b.header {
+			b.dst.Header()[k] = v
+		}
+	} else {
+		b.copyHeader(b.dst)
+	}
+	b.header = b.dst.Header() // the later changes go straight to the client.
+	if b.status != 0 {
+		b.dst.WriteHeader(b.status)
+	}
+	if b.body.Len() > 0 {
+		_, _ = b.dst.Write(b.body.Bytes())
+		b.body.Reset()
+	}
+	return true
+}
+
+// written reports whether the handler has written the status or any part of the body.
+func (b *responseBuffer) written() bool { return b.status != 0 || b.streaming }
+
+// statusCode returns the buffered status, or 200 if nothing has been written, which is what the
+// http.Server sends in that case.
+func (b *responseBuffer) statusCode() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
+
+// flushTo sends the buffered headers, status and body to w. It is a no-op if the response has been passed
+// through, see DisableBuffering.
+func (b *responseBuffer) flushTo(w http.ResponseWriter) error {
+	if b.streaming {
+		return nil
+	}
+	b.copyHeader(w)
+	w.WriteHeader(b.statusCode())
+	if b.body.Len() == 0 {
+		return nil // e.g. 304 Not Modified, for which any write fails.
+	}
+	_, err := w.Write(b.body.Bytes())
+	return err
+}
+
+// copyHeader replaces the headers of w with the buffered ones.
+func (b *responseBuffer) copyHeader(w http.ResponseWriter) {
+	dst := w.Header()
+	for k := range dst {
+		if _, ok := b.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range b.header {
+		dst[k] = v
+	}
+}
@@ -0,0 +1,130 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseBuffer_FlushTo(t *testing.T) {
+	res := httptest.NewRecorder()
+	res.Header().Set("X-Outer", "1")
+	res.Header().Set("X-Removed", "1")
+
+	buf := newResponseBuffer(res)
+	expectFalse(t, buf.written())
+	expectTrue(t, buf.statusCode() == 200)
+	expectTrue(t, buf.Header().Get("X-Outer") == "1")
+
+	buf.Header().Del("X-Removed")
+	buf.Header().Set("X-Inner", "1")
+	buf.WriteHeader(100)
+	expectFalse(t, buf.written())
+	buf.WriteHeader(202)
+	buf.WriteHeader(500)
+	_, _ = buf.Write([]byte("body"))
+
+	// nothing is sent before flushing.
+	expectTrue(t, res.Body.Len() == 0)
+	expectTrue(t, res.Header().Get("X-Inner") == "")
+
+	err := buf.flushTo(res)
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Code == 202)
+	expectTrue(t, res.Body.String() == "body")
+	expectTrue(t, res.Header().Get("X-Outer") == "1")
+	expectTrue(t, res.Header().Get("X-Inner") == "1")
+	expectTrue(t, res.Header().Get("X-Removed") == "")
+}
+
+func TestDisableBuffering(t *testing.T) {
+	rec := &flushRecorder{header: make(http.Header)}
+	var seen []string
+	h := FoldMiddleware(
+		CacheWithConfig(CacheConfig{TTL: time.Minute}),
+		FieldFilter(),
+		UTCTimestamps(),
+	).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		DisableBuffering(r)
+		w.Header().Set("Content-Type", "application/json")
+		for _, chunk := range []string{`{"a":1}`, `{"b":2}`} {
+			_, _ = w.Write([]byte(chunk + "\n"))
+			w.(http.Flusher).Flush()
+			flushed, _ := rec.snapshot()
+			seen = append(seen, flushed)
+		}
+		return nil
+	}))
+
+	expectTrue(t, h.ServeHTTP(rec, httptest.NewRequest("GET", "/events?fields=a", nil)) == nil)
+
+	// every chunk reaches the client as soon as it is flushed, untouched.
+	expectTrue(t, len(seen) == 2)
+	expectTrue(t, seen[0] == "{\"a\":1}\n")
+	expectTrue(t, seen[1] == "{\"a\":1}\n{\"b\":2}\n")
+	expectTrue(t, rec.body.String() == "{\"a\":1}\n{\"b\":2}\n")
+	expectTrue(t, rec.header.Get("Content-Type") == "application/json")
+
+	// the streamed response is not cached.
+	rec = &flushRecorder{header: make(http.Header)}
+	seen = nil
+	expectTrue(t, h.ServeHTTP(rec, httptest.NewRequest("GET", "/events?fields=a", nil)) == nil)
+	expectTrue(t, len(seen) == 2)
+	expectTrue(t, rec.header.Get("X-Cache") == "")
+}
+
+func TestDisableBuffering_AfterWrite(t *testing.T) {
+	res := httptest.NewRecorder()
+	res.Header().Set("X-Outer", "1")
+	h := FieldFilter().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"a":1,`))
+		expectTrue(t, res.Body.Len() == 0)
+
+		DisableBuffering(r)
+		_, _ = w.Write([]byte(`"b":2}`))
+		expectTrue(t, res.Body.String() == `{"a":1,"b":2}`)
+		return nil
+	}))
+
+	expectTrue(t, h.ServeHTTP(res, httptest.NewRequest("GET", "/?fields=a", nil)) == nil)
+	expectTrue(t, res.Code == http.StatusAccepted)
+	expectTrue(t, res.Body.String() == `{"a":1,"b":2}`)
+	expectTrue(t, res.Header().Get("X-Outer") == "1")
+
+	// without the middlewares, it is a no-op.
+	DisableBuffering(httptest.NewRequest("GET", "/", nil))
+}
+
+// interimRecorder records the statuses written, including the informational ones, which are not passed to the
+// httptest.ResponseRecorder since it takes them as the final status.
+type interimRecorder struct {
+	*httptest.ResponseRecorder
+	statuses []int
+}
+
+func (r *interimRecorder) WriteHeader(status int) {
+	r.statuses = append(r.statuses, status)
+	if status >= 200 {
+		r.ResponseRecorder.WriteHeader(status)
+	}
+}
+
+func TestResponseBuffer_Interim(t *testing.T) {
+	res := &interimRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h := ContentDigest("sha-256").Then(ExpectContinue(func(*http.Request) error { return nil }).Then(
+		HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte("ok"))
+			return err
+		})))
+
+	req := httptest.NewRequest("PUT", "/", nil)
+	req.Header.Set("Expect", "100-continue")
+	expectTrue(t, h.ServeHTTP(res, req) == nil)
+
+	// the interim response is sent right away, the final one once buffered.
+	expectTrue(t, len(res.statuses) == 2 && res.statuses[0] == 100 && res.statuses[1] == 200)
+	expectTrue(t, res.Header().Get("Content-Digest") != "")
+}
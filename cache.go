@@ -0,0 +1,430 @@
+package httprouterx
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a cached response.
+type CacheEntry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// StoredAt is when the response was produced.
+	StoredAt time.Time
+
+	// Expires is when the response stops being fresh.
+	Expires time.Time
+
+	// StaleUntil is when the response stops being usable, even stale. It is Expires when stale responses
+	// are not served.
+	StaleUntil time.Time
+
+	// Vary holds the request headers listed by the Vary header of the response, with their values in the
+	// request that produced it. The response is only reused for requests with the same values.
+	Vary http.Header
+}
+
+// CacheStore stores the responses of Cache.
+type CacheStore interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheConfig is the configuration for CacheWithConfig.
+type CacheConfig struct {
+	// TTL is how long a response stays fresh, unless its Cache-Control directives say otherwise.
+	TTL time.Duration
+
+	// StaleWhileRevalidate is how long after its expiry a response is still served, while it is refreshed in the
+	// background. Zero disables it.
+	StaleWhileRevalidate time.Duration
+
+	// Key computes the cache key of the request. Default the method, the host, the path and the CanonicalQuery,
+	// so the responses of different hosts, e.g. tenants, are kept apart.
+	Key func(*http.Request) string
+
+	// Store keeps the responses. Default a new in-memory store, with the default size limit.
+	Store CacheStore
+
+	// Clock is used to expire the responses. Default SystemClock.
+	Clock Clock
+}
+
+// Cache caches the successful (200) responses to GET requests for ttl, and serves them without calling the
+// handler while they are fresh. The X-Cache response header tells whether the response was a HIT, a MISS, or
+// STALE, and the Age header tells how old a cached response is, in seconds.
+//
+// The Cache-Control directives set by the handler take precedence over ttl, as for any shared cache:
+//   - no-store, private and no-cache: the response is not cached. Revalidation is not supported, so no-cache
+//     responses are never reused.
+//   - s-maxage: the response is fresh for the given number of seconds.
+//   - max-age: the same, when s-maxage is absent.
+//
+// A freshness lifetime of zero means the response is not cached.
+//
+// Responses that could leak between users are not cached: the responses with a Set-Cookie header, and the
+// responses to requests with credentials (an Authorization or Cookie header) unless they are marked public or
+// carry s-maxage; such requests are only served the cached responses marked so too. The Vary header of the
+// response is honored, only one variant is kept per key: a response is reused for the requests with the same
+// values of the listed headers, and a response with Vary: * is not cached.
+func Cache(ttl time.Duration) Middleware {
+	return CacheWithConfig(CacheConfig{TTL: ttl})
+}
+
+// CacheWithConfig is like Cache, but with more control. With StaleWhileRevalidate, an expired response is
+// still served right away during that period, while the handler runs again in the background to refresh it:
+// clients get a fast response, at the price of getting an outdated one, for at most TTL plus
+// StaleWhileRevalidate. Only one refresh runs at a time per key, the other requests keep getting the stale
+// response until it is done. The background refresh runs with the request of the first client, detached from its
+// cancellation.
+func CacheWithConfig(cfg CacheConfig) Middleware {
+	if cfg.Key == nil {
+		cfg.Key = defaultCacheKey
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryCacheStore(0)
+	}
+	clock := clockOrSystem(cfg.Clock)
+
+	var (
+		mu         sync.Mutex
+		refreshing = make(map[string]bool)
+	)
+
+	// fill runs the handler into a buffer, and stores the response if it is cacheable.
+	fill := func(next Handler, buf *responseBuffer, r *http.Request, key string) error {
+		if err := next.ServeHTTP(buf, r); err != nil {
+			return err
+		}
+		if buf.statusCode() != http.StatusOK || buf.streaming {
+			return nil
+		}
+		ttl, ok := responseTTL(buf.header, cfg.TTL)
+		if !ok || buf.header.Get("Set-Cookie") != "" || hasCredentials(r) && !sharedCacheable(buf.header) {
+			return nil
+		}
+		vary, ok := varyValues(buf.header, r)
+		if !ok {
+			return nil
+		}
+		now := clock.Now()
+		cfg.Store.Set(key, &CacheEntry{
+			Status:     http.StatusOK,
+			Header:     buf.header.Clone(),
+			Body:       append([]byte(nil), buf.body.Bytes()...),
+			StoredAt:   now,
+			Expires:    now.Add(ttl),
+			StaleUntil: now.Add(ttl + cfg.StaleWhileRevalidate),
+			Vary:       vary,
+		})
+		return nil
+	}
+
+	refresh := func(next Handler, r *http.Request, key string) {
+		mu.Lock()
+		if refreshing[key] {
+			mu.Unlock()
+			return
+		}
+		refreshing[key] = true
+		mu.Unlock()
+
+		r = r.Clone(context.WithoutCancel(r.Context()))
+		go func() {
+			defer func() {
+				mu.Lock()
+				delete(refreshing, key)
+				mu.Unlock()
+			}()
+			defer func() {
+				if v := recover(); v != nil {
+					slog.Warn("cache refresh panicked", "key", key, "panic", v)
+				}
+			}()
+			if err := fill(next, &responseBuffer{header: make(http.Header)}, r, key); err != nil {
+				slog.Warn("cache refresh failed", "key", key, "error", err)
+			}
+		}()
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Method != http.MethodGet {
+				return next.ServeHTTP(w, r)
+			}
+
+			key := cfg.Key(r)
+			now := clock.Now()
+			if entry, ok := cfg.Store.Get(key); ok && entry.usableFor(r) {
+				switch {
+				case now.Before(entry.Expires):
+					return writeCacheEntry(w, entry, "HIT", now)
+				case now.Before(entry.StaleUntil):
+					refresh(next, r, key)
+					return writeCacheEntry(w, entry, "STALE", now)
+				}
+			}
+
+			// the buffer starts empty, so the stored response does not hold the headers of the outer middlewares.
+			buf, r := bufferResponse(w, r)
+			buf.header, buf.merge = make(http.Header), true
+			if err := fill(next, buf, r, key); err != nil || buf.streaming {
+				if buf.written() {
+					_ = buf.flushTo(w)
+				}
+				return err
+			}
+			for k, v := range w.Header() {
+				if _, ok := buf.header[k]; !ok {
+					buf.header[k] = v
+				}
+			}
+			buf.header.Set("X-Cache", "MISS")
+			return buf.flushTo(w)
+		})
+	}
+}
+
+// ServeStaleOnError degrades gracefully during backend outages: when the handler of a GET request fails, with
+// an error or a 5xx status, it sends the response cached in store for the request instead, if any, with the
+// "Warning: 110 - \"Response is Stale\"" header. The error is then logged with slog and not returned. Requests
+// without a cached response get the failure as is.
+//
+// It does not cache responses itself: it is meant to be placed in front of CacheWithConfig, sharing its Store
+// and using the default Key, see ServeStaleOnErrorWithConfig otherwise. Cached responses are served regardless of
+// their expiry, as long as the store keeps them. The response of the handler is buffered to detect the failure
+// before anything is sent.
+func ServeStaleOnError(store CacheStore) Middleware {
+	return ServeStaleOnErrorWithConfig(CacheConfig{Store: store})
+}
+
+// ServeStaleOnErrorWithConfig is like ServeStaleOnError, but it takes the configuration given to CacheWithConfig,
+// so that it finds the responses stored with a custom Key, and computes their Age with the same Clock. The Store
+// is required, and the TTL and StaleWhileRevalidate are ignored.
+func ServeStaleOnErrorWithConfig(cfg CacheConfig) Middleware {
+	if cfg.Store == nil {
+		panic("httprouterx: ServeStaleOnError: Store is required")
+	}
+	if cfg.Key == nil {
+		cfg.Key = defaultCacheKey
+	}
+	clock := clockOrSystem(cfg.Clock)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Method != http.MethodGet {
+				return next.ServeHTTP(w, r)
+			}
+
+			buf, br := bufferResponse(w, r)
+			err := next.ServeHTTP(buf, br)
+			if buf.streaming {
+				return err
+			}
+			if err == nil && buf.statusCode() < 500 {
+				return buf.flushTo(w)
+			}
+
+			key := cfg.Key(r)
+			entry, ok := cfg.Store.Get(key)
+			if !ok || !entry.usableFor(r) {
+				if err != nil {
+					if buf.written() {
+						_ = buf.flushTo(w)
+					}
+					return err
+				}
+				return buf.flushTo(w)
+			}
+
+			if err == nil {
+				err = NewHTTPError(buf.statusCode(), "")
+			}
+			slog.Warn("serving stale response on error", "key", key, "error", err)
+			w.Header().Set("Warning", `110 - "Response is Stale"`)
+			return writeCacheEntry(w, entry, "STALE", clock.Now())
+		})
+	}
+}
+
+// defaultCacheKey is the default cache key: the method, and the target URI made of the case-insensitive host, the
+// path and the CanonicalQuery.
+func defaultCacheKey(r *http.Request) string {
+	return r.Method + " " + strings.ToLower(r.Host) + r.URL.Path + "?" + CanonicalQuery(r)
+}
+
+// hasCredentials reports whether the request carries credentials, whose responses may be specific to the user.
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+}
+
+// sharedCacheable reports whether the Cache-Control directives of the response allow a shared cache to store it
+// even for a request with credentials, i.e. public or s-maxage.
+func sharedCacheable(h http.Header) bool {
+	for _, v := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "public", "s-maxage":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// varyValues returns the values in the request of the headers listed by the Vary header of the response. It
+// returns false for Vary: *, which matches no other request.
+func varyValues(h http.Header, r *http.Request) (http.Header, bool) {
+	var vary http.Header
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case "":
+				continue
+			case "*":
+				return nil, false
+			}
+			if vary == nil {
+				vary = make(http.Header)
+			}
+			vary[http.CanonicalHeaderKey(name)] = append([]string(nil), r.Header.Values(name)...)
+		}
+	}
+	return vary, true
+}
+
+// usableFor reports whether the cached response can be sent for the request: it must have the same values of
+// the headers listed by Vary, and be cacheable by a shared cache if the request has credentials.
+func (e *CacheEntry) usableFor(r *http.Request) bool {
+	if hasCredentials(r) && !sharedCacheable(e.Header) {
+		return false
+	}
+	for name, values := range e.Vary {
+		if !slices.Equal(values, r.Header.Values(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// responseTTL returns the freshness lifetime of the response according to its Cache-Control directives, or ttl
+// if they do not set one. It returns false if the response must not be cached.
+func responseTTL(h http.Header, ttl time.Duration) (time.Duration, bool) {
+	var maxAge, sMaxAge = -1, -1
+	for _, v := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "no-store", "private", "no-cache":
+				return 0, false
+			case "max-age":
+				maxAge = parseDeltaSeconds(arg, maxAge)
+			case "s-maxage":
+				sMaxAge = parseDeltaSeconds(arg, sMaxAge)
+			}
+		}
+	}
+	switch {
+	case sMaxAge >= 0:
+		ttl = time.Duration(sMaxAge) * time.Second
+	case maxAge >= 0:
+		ttl = time.Duration(maxAge) * time.Second
+	}
+	return ttl, ttl > 0
+}
+
+// parseDeltaSeconds parses the argument of a Cache-Control directive, which may be quoted. It returns fallback
+// if the argument is invalid.
+func parseDeltaSeconds(arg string, fallback int) int {
+	n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(arg), `"`))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+// writeCacheEntry sends the cached response.
+func writeCacheEntry(w http.ResponseWriter, entry *CacheEntry, status string, now time.Time) error {
+	h := w.Header()
+	for k, v := range entry.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	h.Set("X-Cache", status)
+	h.Set("Age", strconv.Itoa(int(now.Sub(entry.StoredAt).Seconds())))
+	w.WriteHeader(entry.Status)
+	_, err := w.Write(entry.Body)
+	return err
+}
+
+// DefaultMemoryCacheMaxEntries is the number of responses kept by a MemoryCacheStore by default.
+const DefaultMemoryCacheMaxEntries = 10000
+
+// MemoryCacheStore is an in-memory CacheStore, which keeps a bounded number of responses. When it is full, the
+// least recently used response is evicted.
+type MemoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List // of *memoryCacheItem, the most recently used first.
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCacheStore creates an empty MemoryCacheStore that keeps at most maxEntries responses, or
+// DefaultMemoryCacheMaxEntries if not positive.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheMaxEntries
+	}
+	return &MemoryCacheStore{maxEntries: maxEntries, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// Get implements CacheStore.
+func (s *MemoryCacheStore) Get(key string) (*CacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).entry, true
+}
+
+// Set implements CacheStore.
+func (s *MemoryCacheStore) Set(key string, entry *CacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		s.lru.MoveToFront(elem)
+		return
+	}
+	s.entries[key] = s.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Len returns the number of responses in the store.
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
@@ -0,0 +1,334 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	clock := newFakeClock()
+	var calls atomic.Int32
+	h := CacheWithConfig(CacheConfig{TTL: time.Minute, Clock: clock}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		n := calls.Add(1)
+		if r.URL.Query().Has("fail") {
+			return NewHTTPError(http.StatusBadGateway, "")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("v" + strconv.Itoa(int(n))))
+		return nil
+	}))
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		_ = h.ServeHTTP(res, httptest.NewRequest(method, target, nil))
+		return res
+	}
+
+	res := serve("GET", "/data")
+	expectTrue(t, res.Body.String() == "v1")
+	expectTrue(t, res.Header().Get("X-Cache") == "MISS")
+	expectTrue(t, res.Header().Get("Content-Type") == "text/plain")
+
+	clock.Advance(30 * time.Second)
+	res = serve("GET", "/data")
+	expectTrue(t, res.Body.String() == "v1")
+	expectTrue(t, res.Header().Get("X-Cache") == "HIT")
+	expectTrue(t, res.Header().Get("Age") == "30")
+	expectTrue(t, res.Header().Get("Content-Type") == "text/plain")
+
+	// other keys and methods are not served from the cache.
+	expectTrue(t, serve("GET", "/data?page=2").Body.String() == "v2")
+	expectTrue(t, serve("POST", "/data").Body.String() == "v3")
+
+	// errors are not cached.
+	serve("GET", "/data?fail")
+	serve("GET", "/data?fail")
+	expectTrue(t, calls.Load() == 5)
+
+	// expired, without stale-while-revalidate.
+	clock.Advance(time.Minute)
+	res = serve("GET", "/data")
+	expectTrue(t, res.Body.String() == "v6")
+	expectTrue(t, res.Header().Get("X-Cache") == "MISS")
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	clock := newFakeClock()
+	var (
+		calls   atomic.Int32
+		release = make(chan struct{})
+		once    sync.Once
+	)
+	h := CacheWithConfig(CacheConfig{
+		TTL:                  time.Minute,
+		StaleWhileRevalidate: time.Minute,
+		Clock:                clock,
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		n := calls.Add(1)
+		if n == 2 {
+			<-release // the refresh is slow.
+		}
+		_, _ = w.Write([]byte("v" + strconv.Itoa(int(n))))
+		return nil
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		_ = h.ServeHTTP(res, httptest.NewRequest("GET", "/data", nil))
+		return res
+	}
+
+	expectTrue(t, serve().Body.String() == "v1")
+
+	// stale: served right away while a single refresh runs in the background.
+	clock.Advance(90 * time.Second)
+	for i := 0; i < 3; i++ {
+		res := serve()
+		expectTrue(t, res.Body.String() == "v1")
+		expectTrue(t, res.Header().Get("X-Cache") == "STALE")
+	}
+	once.Do(func() { close(release) })
+	eventually(t, func() bool { return serve().Header().Get("X-Cache") == "HIT" })
+	expectTrue(t, calls.Load() == 2)
+	expectTrue(t, serve().Body.String() == "v2")
+
+	// beyond the stale period, the handler is called synchronously.
+	clock.Advance(3 * time.Minute)
+	res := serve()
+	expectTrue(t, res.Body.String() == "v3")
+	expectTrue(t, res.Header().Get("X-Cache") == "MISS")
+}
+
+func TestCache_CanonicalKey(t *testing.T) {
+	var calls int
+	h := Cache(time.Minute).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		calls++
+		return nil
+	}))
+
+	for _, target := range []string{"/search?q=go&page=2", "/search?page=2&q=go", "/search?page=2&q=%67o"} {
+		_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	}
+	expectTrue(t, calls == 1)
+}
+
+func TestCache_Hosts(t *testing.T) {
+	h := Cache(time.Minute).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte(r.Host))
+		return err
+	}))
+
+	serve := func(target string) string {
+		res := httptest.NewRecorder()
+		_ = h.ServeHTTP(res, httptest.NewRequest("GET", target, nil))
+		return res.Body.String()
+	}
+
+	// the response of a tenant is not served to another.
+	expectTrue(t, serve("http://acme.example.com/settings") == "acme.example.com")
+	expectTrue(t, serve("http://globex.example.com/settings") == "globex.example.com")
+	expectTrue(t, serve("http://ACME.example.com/settings") == "acme.example.com")
+}
+
+func TestCache_CacheControl(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		cachedFor    time.Duration // zero if not cached.
+	}{
+		{"", time.Minute},
+		{"public", time.Minute},
+		{"max-age=10", 10 * time.Second},
+		{"public, max-age=\"300\"", 5 * time.Minute},
+		{"max-age=10, s-maxage=20", 20 * time.Second},
+		{"s-maxage=20, max-age=10", 20 * time.Second},
+		{"max-age=invalid", time.Minute},
+		{"max-age=0", 0},
+		{"no-store", 0},
+		{"max-age=60, No-Store", 0},
+		{"private", 0},
+		{"private=\"Set-Cookie\", max-age=60", 0},
+		{"no-cache", 0},
+	}
+	for _, tt := range tests {
+		clock := newFakeClock()
+		var calls int
+		h := CacheWithConfig(CacheConfig{TTL: time.Minute, Clock: clock}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			calls++
+			if tt.cacheControl != "" {
+				w.Header().Set("Cache-Control", tt.cacheControl)
+			}
+			return nil
+		}))
+		serve := func() {
+			_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/data", nil))
+		}
+
+		serve()
+		if tt.cachedFor == 0 {
+			serve()
+			expectTrue(t, calls == 2)
+			continue
+		}
+		clock.Advance(tt.cachedFor - time.Second)
+		serve()
+		expectTrue(t, calls == 1)
+		clock.Advance(time.Second)
+		serve()
+		expectTrue(t, calls == 2)
+	}
+}
+
+func TestCache_PrivateResponses(t *testing.T) {
+	var calls atomic.Int32
+	h := Cache(time.Minute).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		n := calls.Add(1)
+		for k, v := range r.URL.Query() {
+			w.Header().Set(k, v[0])
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Authorization") + r.Header.Get("Accept-Language") + strconv.Itoa(int(n))))
+		return nil
+	}))
+
+	serve := func(target string, header ...string) string {
+		req := httptest.NewRequest("GET", target, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		res := httptest.NewRecorder()
+		_ = h.ServeHTTP(res, req)
+		return res.Body.String()
+	}
+
+	// responses to requests with credentials are not stored, nor served from the cache, unless public.
+	expectTrue(t, serve("/me", "Authorization", "alice") == "alice1")
+	expectTrue(t, serve("/me", "Authorization", "bob") == "bob2")
+	expectTrue(t, serve("/me") == "3")
+	expectTrue(t, serve("/me", "Cookie", "session=alice") == "4")
+	expectTrue(t, serve("/me") == "3")
+	expectTrue(t, serve("/public?Cache-Control=public", "Authorization", "alice") == "alice5")
+	expectTrue(t, serve("/public?Cache-Control=public", "Authorization", "bob") == "alice5")
+
+	// responses setting cookies are not stored.
+	expectTrue(t, serve("/login?Set-Cookie=session") == "6")
+	expectTrue(t, serve("/login?Set-Cookie=session") == "7")
+
+	// a single variant is kept per key.
+	expectTrue(t, serve("/hello?Vary=Accept-Language", "Accept-Language", "fr") == "fr8")
+	expectTrue(t, serve("/hello?Vary=Accept-Language", "Accept-Language", "fr") == "fr8")
+	expectTrue(t, serve("/hello?Vary=Accept-Language", "Accept-Language", "en") == "en9")
+	expectTrue(t, serve("/hello?Vary=Accept-Language", "Accept-Language", "en") == "en9")
+	expectTrue(t, serve("/hello?Vary=Accept-Language") == "10")
+
+	expectTrue(t, serve("/any?Vary=*") == "11")
+	expectTrue(t, serve("/any?Vary=*") == "12")
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+	store.Set("a", &CacheEntry{Status: 1})
+	store.Set("b", &CacheEntry{Status: 2})
+	_, _ = store.Get("a")
+	store.Set("c", &CacheEntry{Status: 3})
+
+	// b was the least recently used.
+	expectTrue(t, store.Len() == 2)
+	_, ok := store.Get("b")
+	expectFalse(t, ok)
+	entry, ok := store.Get("a")
+	expectTrue(t, ok && entry.Status == 1)
+
+	store.Set("c", &CacheEntry{Status: 4})
+	entry, ok = store.Get("c")
+	expectTrue(t, ok && entry.Status == 4)
+	expectTrue(t, store.Len() == 2)
+}
+
+func TestServeStaleOnError(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryCacheStore(0)
+	var failure atomic.Int32 // 0: ok, 1: error, 2: 503 status.
+	h := FoldMiddleware(
+		ServeStaleOnError(store),
+		CacheWithConfig(CacheConfig{TTL: time.Minute, Store: store, Clock: clock}),
+	).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		switch failure.Load() {
+		case 1:
+			return NewHTTPError(http.StatusBadGateway, "")
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return nil
+		}
+		_, _ = w.Write([]byte("fresh"))
+		return nil
+	}))
+
+	serve := func(target string) (*httptest.ResponseRecorder, error) {
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", target, nil))
+		return res, err
+	}
+
+	res, err := serve("/data")
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Body.String() == "fresh")
+	expectTrue(t, res.Header().Get("Warning") == "")
+
+	// the cached response expires, then the backend fails.
+	clock.Advance(2 * time.Minute)
+	failure.Store(1)
+	res, err = serve("/data")
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Code == http.StatusOK)
+	expectTrue(t, res.Body.String() == "fresh")
+	expectTrue(t, res.Header().Get("Warning") == `110 - "Response is Stale"`)
+	expectTrue(t, res.Header().Get("X-Cache") == "STALE")
+
+	failure.Store(2)
+	res, err = serve("/data")
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Body.String() == "fresh")
+
+	// without a prior success, the failure is sent as is.
+	res, err = serve("/other")
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Code == http.StatusServiceUnavailable)
+	expectTrue(t, res.Header().Get("Warning") == "")
+
+	failure.Store(1)
+	_, err = serve("/other")
+	expectHTTPError(t, err, http.StatusBadGateway)
+}
+
+func TestServeStaleOnErrorWithConfig(t *testing.T) {
+	clock := newFakeClock()
+	failing := false
+	cfg := CacheConfig{
+		TTL:   time.Minute,
+		Store: NewMemoryCacheStore(0),
+		Key:   func(r *http.Request) string { return r.URL.Path },
+		Clock: clock,
+	}
+	h := FoldMiddleware(ServeStaleOnErrorWithConfig(cfg), CacheWithConfig(cfg)).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if failing {
+			return NewHTTPError(http.StatusBadGateway, "")
+		}
+		_, _ = w.Write([]byte("fresh"))
+		return nil
+	}))
+
+	res := httptest.NewRecorder()
+	expectTrue(t, h.ServeHTTP(res, httptest.NewRequest("GET", "/data", nil)) == nil)
+
+	// found with the custom key, and aged with the clock.
+	clock.Advance(2 * time.Minute)
+	failing = true
+	res = httptest.NewRecorder()
+	expectTrue(t, h.ServeHTTP(res, httptest.NewRequest("GET", "/data?page=1", nil)) == nil)
+	expectTrue(t, res.Body.String() == "fresh")
+	expectTrue(t, res.Header().Get("Age") == "120")
+}
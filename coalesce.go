@@ -0,0 +1,110 @@
+package httprouterx
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+)
+
+// BufferSmallWrites coalesces the writes of the handler into a buffer of up to threshold bytes. If the whole
+// response fits in the buffer, it is sent with a single write and an accurate Content-Length, so the response is
+// not chunked. Once the response grows above threshold, the buffer is sent and the following writes are passed
+// through as is. An explicit Flush also sends the buffer and switches to pass-through, so streaming handlers
+// keep working.
+//
+// This is mostly useful for handlers that produce small responses with many small writes, e.g. encoding JSON
+// field by field.
+func BufferSmallWrites(threshold int) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			cw := &coalescingWriter{ResponseWriter: w, threshold: threshold, head: r.Method == http.MethodHead}
+			err := next.ServeHTTP(cw, r)
+			if ferr := cw.finish(); err == nil {
+				err = ferr
+			}
+			return err
+		})
+	}
+}
+
+// coalescingWriter buffers the status and the body until the body exceeds the threshold, or the response ends.
+type coalescingWriter struct {
+	http.ResponseWriter
+	threshold   int
+	head        bool
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+// WriteHeader implements http.ResponseWriter. Informational (1xx) statuses are sent right away.
+func (w *coalescingWriter) WriteHeader(status int) {
+	if w.passthrough || (status >= 100 && status < 200) {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (w *coalescingWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.buf.Len()+len(b) <= w.threshold {
+		return w.buf.Write(b)
+	}
+	if err := w.switchToPassthrough(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher. It sends the buffered response, then flushes the underlying writer if it is an
+// http.Flusher.
+func (w *coalescingWriter) Flush() {
+	if !w.passthrough {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.switchToPassthrough(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *coalescingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// switchToPassthrough sends the buffered status and body.
+func (w *coalescingWriter) switchToPassthrough() error {
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish sends the response if it is still buffered, with its Content-Length. The Content-Length is left alone
+// for the responses without a body, HEAD, 1xx, 204 and 304, whose length would be the one of the omitted body.
+func (w *coalescingWriter) finish() error {
+	if w.passthrough || w.status == 0 {
+		return nil
+	}
+	noBody := w.head || w.status < 200 || w.status == http.StatusNoContent || w.status == http.StatusNotModified
+	if !noBody && w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	}
+	return w.switchToPassthrough()
+}
@@ -0,0 +1,128 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// countingWriter counts the writes that reach the underlying writer.
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	c.writes++
+	return c.ResponseRecorder.Write(b)
+}
+
+func TestBufferSmallWrites(t *testing.T) {
+	writeParts := func(n int) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(201)
+			for i := 0; i < n; i++ {
+				_, _ = w.Write([]byte("0123456789"))
+			}
+			return nil
+		})
+	}
+
+	t.Run("fits in the buffer", func(t *testing.T) {
+		res := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+		err := BufferSmallWrites(100).Then(writeParts(10)).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.writes == 1)
+		expectTrue(t, res.Code == 201)
+		expectTrue(t, res.Header().Get("Content-Length") == "100")
+		expectTrue(t, res.Body.String() == strings.Repeat("0123456789", 10))
+	})
+
+	t.Run("above the threshold", func(t *testing.T) {
+		res := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+		err := BufferSmallWrites(100).Then(writeParts(15)).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.writes == 6) // the buffer, then 5 pass-through writes.
+		expectTrue(t, res.Code == 201)
+		expectTrue(t, res.Header().Get("Content-Length") == "")
+		expectTrue(t, res.Body.String() == strings.Repeat("0123456789", 15))
+	})
+
+	t.Run("explicit flush", func(t *testing.T) {
+		res := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+		h := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			_, _ = w.Write([]byte("event: 1\n"))
+			w.(http.Flusher).Flush()
+			expectTrue(t, res.Flushed)
+			expectTrue(t, res.Body.String() == "event: 1\n")
+			_, _ = w.Write([]byte("event: 2\n"))
+			return nil
+		})
+		err := BufferSmallWrites(100).Then(h).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.writes == 2)
+		expectTrue(t, res.Header().Get("Content-Length") == "")
+	})
+
+	t.Run("head", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		h := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(200)
+			return nil
+		})
+		err := BufferSmallWrites(100).Then(h).ServeHTTP(res, httptest.NewRequest("HEAD", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == 200)
+		expectTrue(t, res.Header().Get("Content-Length") == "")
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		for _, status := range []int{204, 304} {
+			res := httptest.NewRecorder()
+			h := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(status)
+				return nil
+			})
+			err := BufferSmallWrites(100).Then(h).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+			expectTrue(t, err == nil)
+			expectTrue(t, res.Code == status)
+			expectTrue(t, res.Header().Get("Content-Length") == "")
+		}
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		anError := errors.New("an error")
+		res := httptest.NewRecorder()
+		h := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return anError })
+		err := BufferSmallWrites(100).Then(h).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, errors.Is(err, anError))
+		expectFalse(t, res.Flushed)
+		expectTrue(t, res.Body.Len() == 0)
+	})
+}
+
+func BenchmarkBufferSmallWrites(b *testing.B) {
+	h := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		for i := 0; i < 50; i++ {
+			_, _ = w.Write([]byte(`{"k":"v"},`))
+		}
+		return nil
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+
+	run := func(b *testing.B, h Handler) {
+		var writes int
+		for i := 0; i < b.N; i++ {
+			res := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+			_ = h.ServeHTTP(res, req)
+			writes += res.writes
+		}
+		b.ReportMetric(float64(writes)/float64(b.N), "writes/op")
+	}
+
+	b.Run("unbuffered", func(b *testing.B) { run(b, h) })
+	b.Run("buffered", func(b *testing.B) { run(b, BufferSmallWrites(4096).Then(h)) })
+}
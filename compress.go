@@ -0,0 +1,220 @@
+package httprouterx
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressMiddleware compresses the responses with gzip, or deflate, when the client accepts it in
+// Accept-Encoding, at the given compression level, e.g. gzip.DefaultCompression or gzip.BestSpeed. It adds
+// Accept-Encoding to the Vary header, and sets the Content-Encoding of the compressed responses, whose
+// Content-Length is removed and strong ETag made weak, since they no longer match the body.
+//
+// The responses that are already compressed are sent as is: the ones with a Content-Encoding, and the content
+// types that do not compress, such as images, videos, audio and archives. The responses without a body (HEAD
+// requests, 204 and 304) are not compressed either. The content type is sniffed from the first write if the
+// handler did not set it, like the http.Server does.
+//
+// The body is compressed as it is written, and the writer passed to the handler supports http.Flusher, which
+// flushes the compressed data written so far. It panics if the level is invalid.
+func CompressMiddleware(level int) Middleware {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		panic("httprouterx: CompressMiddleware: " + err.Error())
+	}
+	pools := map[string]*sync.Pool{
+		"gzip": {New: func() any {
+			zw, _ := gzip.NewWriterLevel(io.Discard, level)
+			return zw
+		}},
+		"deflate": {New: func() any {
+			zw, _ := flate.NewWriter(io.Discard, level)
+			return zw
+		}},
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Values("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				return next.ServeHTTP(w, r)
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, pool: pools[encoding]}
+			err := next.ServeHTTP(cw, r)
+			if cerr := cw.finish(); err == nil {
+				err = cerr
+			}
+			return err
+		})
+	}
+}
+
+// compressor is implemented by gzip.Writer and flate.Writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// compressWriter compresses the response, if it is compressible. The decision is made at the first write, when
+// the headers are final, so the status is held until then.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+
+	status  int
+	decided bool
+	enc     compressor
+}
+
+// WriteHeader implements http.ResponseWriter. The status is sent at the first write. Informational (1xx)
+// statuses are sent right away.
+func (cw *compressWriter) WriteHeader(status int) {
+	if status >= 100 && status <= 199 {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.decide(p)
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher. It is a no-op if the underlying writer is not an http.Flusher.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(nil)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter { return cw.ResponseWriter }
+
+// decide chooses whether to compress, given the first bytes of the body, and sends the headers.
+func (cw *compressWriter) decide(p []byte) {
+	cw.decided = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(p) > 0 && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Type", http.DetectContentType(p))
+	}
+	if isCompressible(cw.status, h) {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = cw.pool.Get().(compressor)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// finish sends the status if nothing was written, and completes the compressed stream.
+func (cw *compressWriter) finish() error {
+	if !cw.decided {
+		if cw.status == 0 {
+			return nil // nothing written, e.g. the handler returned an error.
+		}
+		// an empty body is not worth compressing.
+		cw.decided = true
+		cw.ResponseWriter.WriteHeader(cw.status)
+		return nil
+	}
+	if cw.enc == nil {
+		return nil
+	}
+	err := cw.enc.Close()
+	cw.enc.Reset(io.Discard)
+	cw.pool.Put(cw.enc)
+	cw.enc = nil
+	return err
+}
+
+// isCompressible reports whether the response is worth compressing.
+func isCompressible(status int, h http.Header) bool {
+	if status == http.StatusNoContent || status == http.StatusNotModified || h.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"), strings.HasPrefix(mediaType, "font/woff"):
+		return false
+	}
+	switch mediaType {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/zstd", "application/x-bzip2",
+		"application/x-xz", "application/x-7z-compressed", "application/x-rar-compressed", "application/pdf",
+		"application/octet-stream":
+		return false
+	}
+	return true
+}
+
+// negotiateEncoding picks the preferred of gzip and deflate in the Accept-Encoding values, or "" if none is
+// acceptable. On equal quality, gzip is preferred.
+func negotiateEncoding(values []string) string {
+	q := map[string]float64{"gzip": -1, "deflate": -1}
+	wildcard := -1.0
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			coding, params, _ := strings.Cut(part, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			quality := 1.0
+			if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					quality = f
+				}
+			}
+			switch coding {
+			case "gzip", "x-gzip":
+				q["gzip"] = quality
+			case "deflate":
+				q["deflate"] = quality
+			case "*":
+				wildcard = quality
+			}
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{"gzip", "deflate"} {
+		quality := q[coding]
+		if quality < 0 {
+			quality = wildcard
+		}
+		if quality > bestQ {
+			best, bestQ = coding, quality
+		}
+	}
+	return best
+}
@@ -0,0 +1,113 @@
+package httprouterx
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"name":"gopher"},`, 100)
+	h := CompressMiddleware(gzip.DefaultCompression).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if ct := r.URL.Query().Get("type"); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+		w.Header().Set("Content-Length", "1800")
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, body)
+		return nil
+	}))
+
+	serve := func(target, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		res := httptest.NewRecorder()
+		expectTrue(t, h.ServeHTTP(res, req) == nil)
+		expectTrue(t, res.Code == http.StatusCreated)
+		expectTrue(t, res.Header().Get("Vary") == "Accept-Encoding")
+		return res
+	}
+
+	res := serve("/?type=application/json", "deflate;q=0.5, gzip")
+	expectTrue(t, res.Header().Get("Content-Encoding") == "gzip")
+	expectTrue(t, res.Header().Get("Content-Length") == "")
+	expectTrue(t, res.Header().Get("ETag") == `W/"v1"`)
+	zr, err := gzip.NewReader(res.Body)
+	expectTrue(t, err == nil)
+	b, _ := io.ReadAll(zr)
+	expectTrue(t, string(b) == body)
+
+	res = serve("/", "gzip;q=0.1, deflate")
+	expectTrue(t, res.Header().Get("Content-Encoding") == "deflate")
+	expectTrue(t, strings.HasPrefix(res.Header().Get("Content-Type"), "text/plain"))
+	b, _ = io.ReadAll(flate.NewReader(res.Body))
+	expectTrue(t, string(b) == body)
+
+	for _, acceptEncoding := range []string{"", "br", "gzip;q=0, deflate;q=0", "*;q=0", "identity"} {
+		res = serve("/", acceptEncoding)
+		expectTrue(t, res.Header().Get("Content-Encoding") == "")
+		expectTrue(t, res.Body.String() == body)
+	}
+	expectTrue(t, serve("/", "*").Header().Get("Content-Encoding") == "gzip")
+
+	// already compressed content types.
+	for _, ct := range []string{"image/png", "application/zip", "video/mp4"} {
+		res = serve("/?type="+ct, "gzip")
+		expectTrue(t, res.Header().Get("Content-Encoding") == "")
+		expectTrue(t, res.Header().Get("Content-Length") == "1800")
+	}
+	expectTrue(t, serve("/?type=image/svg%2Bxml", "gzip").Header().Get("Content-Encoding") == "gzip")
+}
+
+func TestCompressMiddleware_Flush(t *testing.T) {
+	rec := &flushRecorder{header: make(http.Header)}
+	h := CompressMiddleware(gzip.BestSpeed).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: 1\n\n")
+		w.(http.Flusher).Flush()
+
+		flushed, flushes := rec.snapshot()
+		expectTrue(t, flushes == 1)
+		zr, err := gzip.NewReader(strings.NewReader(flushed))
+		expectTrue(t, err == nil)
+		buf := make([]byte, 64)
+		n, _ := zr.Read(buf)
+		expectTrue(t, string(buf[:n]) == "data: 1\n\n")
+		return errors.New("stream ended")
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	err := h.ServeHTTP(rec, req)
+	expectTrue(t, err != nil && err.Error() == "stream ended")
+
+	// the stream is complete.
+	zr, err := gzip.NewReader(strings.NewReader(rec.body.String()))
+	expectTrue(t, err == nil)
+	b, err := io.ReadAll(zr)
+	expectTrue(t, err == nil)
+	expectTrue(t, string(b) == "data: 1\n\n")
+}
+
+func TestCompressMiddleware_NoBody(t *testing.T) {
+	h := CompressMiddleware(gzip.DefaultCompression).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	expectTrue(t, h.ServeHTTP(res, req) == nil)
+	expectTrue(t, res.Code == http.StatusNoContent)
+	expectTrue(t, res.Header().Get("Content-Encoding") == "")
+	expectTrue(t, res.Body.Len() == 0)
+
+	defer func() { expectTrue(t, recover() != nil) }()
+	CompressMiddleware(42)
+}
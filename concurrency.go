@@ -0,0 +1,82 @@
+package httprouterx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// LimitMode is what LimitConcurrencyMiddlewareWithConfig does with the requests over the limit.
+type LimitMode int
+
+const (
+	// LimitBlock makes the requests over the limit wait for a slot.
+	LimitBlock LimitMode = iota
+	// LimitReject rejects the requests over the limit right away.
+	LimitReject
+)
+
+// LimitConfig is the configuration for LimitConcurrencyMiddlewareWithConfig.
+type LimitConfig struct {
+	// Max is the maximum number of requests handled at once. Required.
+	Max int
+
+	// Mode is what happens to the requests over the limit. Default LimitBlock.
+	Mode LimitMode
+
+	// MaxWait is how long a blocked request waits for a slot before being rejected. Zero means it waits until a
+	// slot is free or the request is canceled.
+	MaxWait time.Duration
+}
+
+// LimitConcurrencyMiddleware protects a downstream resource, such as a database with a small connection pool, by
+// handling at most max requests at once. The requests over the limit wait for a slot, in no particular order, as
+// long as their context is not canceled. See LimitConcurrencyMiddlewareWithConfig to reject them instead.
+func LimitConcurrencyMiddleware(max int) Middleware {
+	return LimitConcurrencyMiddlewareWithConfig(LimitConfig{Max: max})
+}
+
+// LimitConcurrencyMiddlewareWithConfig is like LimitConcurrencyMiddleware, but the requests over the limit can be
+// rejected right away (LimitReject), or after waiting for MaxWait (LimitBlock), with 503 Service Unavailable and a
+// Retry-After header. A waiting request whose context is canceled gets the context error. The slot is released
+// when the handler returns, even if it panics. It panics if Max is not positive.
+func LimitConcurrencyMiddlewareWithConfig(cfg LimitConfig) Middleware {
+	if cfg.Max <= 0 {
+		panic("httprouterx: LimitConcurrencyMiddleware: the limit must be positive, got " + strconv.Itoa(cfg.Max))
+	}
+
+	sem := make(chan struct{}, cfg.Max)
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			select {
+			case sem <- struct{}{}:
+			default:
+				if cfg.Mode == LimitReject {
+					return concurrencyLimitError(w)
+				}
+
+				var timeout <-chan time.Time
+				if cfg.MaxWait > 0 {
+					timer := time.NewTimer(cfg.MaxWait)
+					defer timer.Stop()
+					timeout = timer.C
+				}
+				select {
+				case sem <- struct{}{}:
+				case <-timeout:
+					return concurrencyLimitError(w)
+				case <-r.Context().Done():
+					return r.Context().Err()
+				}
+			}
+			defer func() { <-sem }()
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// concurrencyLimitError sets Retry-After and returns the 503 error of a request over the limit.
+func concurrencyLimitError(w http.ResponseWriter) error {
+	w.Header().Set("Retry-After", "1")
+	return NewHTTPError(http.StatusServiceUnavailable, "too many concurrent requests, try again later")
+}
@@ -0,0 +1,107 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// blockingHandler returns a handler that signals started and waits for release.
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+}
+
+func TestLimitConcurrencyMiddleware_Block(t *testing.T) {
+	started, release := make(chan struct{}, 3), make(chan struct{})
+	h := LimitConcurrencyMiddleware(2).Then(blockingHandler(started, release))
+
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { errs <- h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)) }()
+	}
+	<-started
+	<-started
+	select {
+	case <-started:
+		t.Fatal("the third request must wait for a slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release <- struct{}{}
+	<-started // the waiting request gets the freed slot.
+	close(release)
+	for i := 0; i < 3; i++ {
+		expectTrue(t, <-errs == nil)
+	}
+}
+
+func TestLimitConcurrencyMiddleware_Reject(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	h := LimitConcurrencyMiddlewareWithConfig(LimitConfig{Max: 1, Mode: LimitReject}).Then(blockingHandler(started, release))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	<-started
+
+	res := httptest.NewRecorder()
+	err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	expectHTTPError(t, err, http.StatusServiceUnavailable)
+	expectTrue(t, res.Header().Get("Retry-After") == "1")
+
+	close(release)
+	<-done
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)) == nil)
+}
+
+func TestLimitConcurrencyMiddleware_MaxWaitAndCancel(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	h := LimitConcurrencyMiddlewareWithConfig(LimitConfig{Max: 1, MaxWait: 10 * time.Millisecond}).Then(blockingHandler(started, release))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	<-started
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	expectHTTPError(t, err, http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	expectTrue(t, errors.Is(err, context.Canceled))
+
+	close(release)
+	<-done
+}
+
+func TestLimitConcurrencyMiddleware_Panic(t *testing.T) {
+	h := LimitConcurrencyMiddlewareWithConfig(LimitConfig{Max: 1, Mode: LimitReject}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}))
+
+	for i := 0; i < 3; i++ {
+		func() {
+			defer func() {
+				expectTrue(t, recover() == "boom") // not a 503: the slot was released.
+			}()
+			_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}()
+	}
+
+	defer func() {
+		expectTrue(t, recover() != nil)
+	}()
+	LimitConcurrencyMiddleware(0)
+}
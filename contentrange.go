@@ -0,0 +1,99 @@
+package httprouterx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingContentRange is the underlying error of the *HTTPError returned by ParseContentRange when the
+// request has no Content-Range header.
+var ErrMissingContentRange = errors.New("missing Content-Range header")
+
+// ParseContentRange parses the Content-Range header of a chunk upload, as used by resumable uploads:
+//
+//	bytes 0-499/1234   the first 500 bytes of a 1234 bytes file.
+//	bytes 500-999/*    bytes 500 to 999 of a file with unknown size, total is -1.
+//	bytes */1234       no bytes, used to query the status of an upload, start and end are -1.
+//
+// The range must be valid (start <= end < total), and match the Content-Length of the request when it is known.
+// Errors are *HTTPError values with status 400.
+func ParseContentRange(r *http.Request) (start, end, total int64, err error) {
+	header := r.Header.Get("Content-Range")
+	if header == "" {
+		return 0, 0, 0, &HTTPError{Status: http.StatusBadRequest, Message: ErrMissingContentRange.Error(), Err: ErrMissingContentRange}
+	}
+
+	invalid := func(reason string) (int64, int64, int64, error) {
+		return 0, 0, 0, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid Content-Range header %q: %s", header, reason))
+	}
+
+	unit, spec, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || unit != "bytes" {
+		return invalid("unit must be bytes")
+	}
+	rng, size, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return invalid("missing total size")
+	}
+
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil || total < 0 {
+			return invalid("total size must be a non-negative integer or *")
+		}
+	}
+
+	if rng == "*" {
+		if total < 0 {
+			return invalid("range and total size cannot both be unknown")
+		}
+		return -1, -1, total, nil
+	}
+
+	first, last, ok := strings.Cut(rng, "-")
+	if !ok {
+		return invalid("range must be start-end")
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil || start < 0 {
+		return invalid("start must be a non-negative integer")
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return invalid("end must be an integer not less than start")
+	}
+	if total >= 0 && end >= total {
+		return invalid("end must be less than the total size")
+	}
+	if r.ContentLength >= 0 && r.ContentLength != end-start+1 {
+		return invalid("range length does not match the Content-Length")
+	}
+	return start, end, total, nil
+}
+
+// WriteChunkAt parses the Content-Range of the request, and writes the body at the range offset of dst, e.g. an
+// *os.File holding the upload being assembled. It returns the number of bytes written, and an *HTTPError with
+// status 400 if the header is invalid, or if the body is shorter than the range.
+//
+// Requests querying the upload status (bytes */total) write nothing.
+func WriteChunkAt(dst io.WriterAt, r *http.Request) (int64, error) {
+	start, end, _, err := ParseContentRange(r)
+	if err != nil {
+		return 0, err
+	}
+	if start < 0 {
+		return 0, nil
+	}
+
+	want := end - start + 1
+	n, err := io.Copy(io.NewOffsetWriter(dst, start), io.LimitReader(r.Body, want))
+	if err != nil {
+		return n, err
+	}
+	if n < want {
+		return n, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("request body is shorter than the range: got %d of %d bytes", n, want))
+	}
+	return n, nil
+}
@@ -0,0 +1,73 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header            string
+		body              string
+		start, end, total int64
+		invalid           bool
+	}{
+		{header: "bytes 0-4/10", body: "01234", start: 0, end: 4, total: 10},
+		{header: "bytes 5-9/10", body: "56789", start: 5, end: 9, total: 10},
+		{header: "bytes 5-9/*", body: "56789", start: 5, end: 9, total: -1},
+		{header: "bytes */10", start: -1, end: -1, total: 10},
+		{header: "bytes */*", invalid: true},
+		{header: "items 0-4/10", body: "01234", invalid: true},
+		{header: "bytes 0-4", body: "01234", invalid: true},
+		{header: "bytes 4-0/10", body: "01234", invalid: true},
+		{header: "bytes -1-4/10", body: "01234", invalid: true},
+		{header: "bytes 6-10/10", body: "67890", invalid: true},
+		{header: "bytes 0-4/x", body: "01234", invalid: true},
+		{header: "bytes 0-4/10", body: "0123", invalid: true},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("PUT", "/upload", strings.NewReader(tt.body))
+		req.Header.Set("Content-Range", tt.header)
+
+		start, end, total, err := ParseContentRange(req)
+		if tt.invalid {
+			expectHTTPError(t, err, 400)
+			continue
+		}
+		expectTrue(t, err == nil)
+		expectTrue(t, start == tt.start && end == tt.end && total == tt.total)
+	}
+
+	_, _, _, err := ParseContentRange(httptest.NewRequest("PUT", "/upload", nil))
+	expectTrue(t, errors.Is(err, ErrMissingContentRange))
+}
+
+type memoryFile []byte
+
+func (f memoryFile) WriteAt(p []byte, off int64) (int, error) { return copy(f[off:], p), nil }
+
+func TestWriteChunkAt(t *testing.T) {
+	file := make(memoryFile, 10)
+	for _, chunk := range []struct{ header, body string }{
+		{"bytes 5-9/10", "56789"},
+		{"bytes 0-4/10", "01234"},
+		{"bytes */10", ""},
+	} {
+		req := httptest.NewRequest("PUT", "/upload", strings.NewReader(chunk.body))
+		req.Header.Set("Content-Range", chunk.header)
+		n, err := WriteChunkAt(file, req)
+		expectTrue(t, err == nil)
+		expectTrue(t, n == int64(len(chunk.body)))
+	}
+	expectTrue(t, string(file) == "0123456789")
+
+	// the body is shorter than the range, and the length is unknown.
+	req := httptest.NewRequest("PUT", "/upload", strings.NewReader("012"))
+	req.ContentLength = -1
+	req.Header.Set("Content-Range", "bytes 0-4/10")
+	_, err := WriteChunkAt(file, req)
+	expectHTTPError(t, err, 400)
+}
@@ -0,0 +1,51 @@
+package httprouterx
+
+import "context"
+
+// ctxKey is an internal type for the context keys of this package.
+type ctxKey int
+
+const (
+	routeInfoKey ctxKey = iota
+	principalKey
+	preferencesKey
+	fingerprintKey
+	tenantKey
+	scopesKey
+	contentVersionKey
+	txKey
+	bufferingKey
+	summaryKey
+	baggageKey
+	tracerKey
+	requestIDKey
+	languageKey
+	wrapErrKey
+)
+
+// WithPrincipal returns a copy of ctx that carries the authenticated principal.
+// It is meant to be used by authentication middlewares, so that the following middlewares (e.g. Authorize)
+// and handlers can get the principal using PrincipalFromContext.
+func WithPrincipal(ctx context.Context, principal any) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext gets the principal stored by WithPrincipal.
+func PrincipalFromContext(ctx context.Context) (any, bool) {
+	principal := ctx.Value(principalKey)
+	return principal, principal != nil
+}
+
+// WithScopes returns a copy of ctx that carries the scopes granted to the token of the request, e.g. the
+// space-separated "scope" claim of an OAuth access token, split into a slice. It is meant to be used by
+// authentication middlewares, along with WithPrincipal, so RequireScopes and RequireAnyScope can enforce them.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey, scopes)
+}
+
+// ScopesFromContext gets the scopes stored by WithScopes. It returns false if WithScopes has not been called,
+// which is different from a token without scopes.
+func ScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(scopesKey).([]string)
+	return scopes, ok
+}
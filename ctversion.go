@@ -0,0 +1,60 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// ContentTypeVersion dispatches the request to the handler of the payload version announced by its vendor
+// media type. The expected Content-Type format is:
+//
+//	application/vnd.<vendor>.v<N>+<suffix>, e.g. application/vnd.api.v2+json
+//
+// The version is "v<N>" (e.g. "v2"), which is also the key of the supported map, and is available to the
+// handlers with ContentVersion. Requests without a versioned vendor media type, such as a plain
+// application/json body or no body at all, go to the next handler, which usually handles the latest version.
+// Versions missing from supported are rejected with 415 Unsupported Media Type.
+func ContentTypeVersion(supported map[string]HandlerFunc) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			version, ok := parseContentTypeVersion(r.Header.Get("Content-Type"))
+			if !ok {
+				return next.ServeHTTP(w, r)
+			}
+			handler, ok := supported[version]
+			if !ok {
+				return NewHTTPError(http.StatusUnsupportedMediaType, "unsupported content type version: "+version)
+			}
+			return handler(w, r.WithContext(context.WithValue(r.Context(), contentVersionKey, version)))
+		})
+	}
+}
+
+// ContentVersion gets the payload version of the request dispatched by ContentTypeVersion, e.g. "v2".
+// It returns an empty string if the request was not dispatched by version.
+func ContentVersion(r *http.Request) string {
+	version, _ := r.Context().Value(contentVersionKey).(string)
+	return version
+}
+
+// parseContentTypeVersion extracts the "v<N>" version of a vendor media type.
+func parseContentTypeVersion(contentType string) (string, bool) {
+	mr, ok := parseMediaRange(contentType)
+	if !ok || !strings.HasPrefix(mr.Subtype, "vnd.") {
+		return "", false
+	}
+
+	subtype, _, _ := strings.Cut(mr.Subtype, "+")
+	i := strings.LastIndexByte(subtype, '.')
+	version := subtype[i+1:]
+	if i < len("vnd.") || len(version) < 2 || version[0] != 'v' {
+		return "", false
+	}
+	for _, c := range version[1:] {
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+	return version, true
+}
@@ -0,0 +1,70 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseContentTypeVersion(t *testing.T) {
+	tests := []struct {
+		contentType string
+		version     string
+		ok          bool
+	}{
+		{contentType: "application/vnd.api.v2+json", version: "v2", ok: true},
+		{contentType: "application/vnd.acme.orders.v10+json; charset=utf-8", version: "v10", ok: true},
+		{contentType: "Application/VND.API.V3+JSON", version: "v3", ok: true},
+		{contentType: "application/vnd.api.v1", version: "v1", ok: true},
+		{contentType: "application/vnd.api+json"},
+		{contentType: "application/vnd.v2+json"},
+		{contentType: "application/vnd.api.vx+json"},
+		{contentType: "application/json"},
+		{contentType: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			version, ok := parseContentTypeVersion(tt.contentType)
+			expectTrue(t, ok == tt.ok)
+			expectTrue(t, version == tt.version)
+		})
+	}
+}
+
+func TestContentTypeVersion(t *testing.T) {
+	versioned := func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("handled " + ContentVersion(r)))
+		return nil
+	}
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "POST", Path: "/orders", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("latest"))
+		return nil
+	}}, ContentTypeVersion(map[string]HandlerFunc{"v1": versioned, "v2": versioned}))
+
+	tests := []struct {
+		contentType string
+		status      int
+		body        string
+	}{
+		{contentType: "application/vnd.api.v1+json", status: 200, body: "handled v1"},
+		{contentType: "application/vnd.api.v2+json", status: 200, body: "handled v2"},
+		{contentType: "application/json", status: 200, body: "latest"},
+		{contentType: "application/vnd.api.v9+json", status: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/orders", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+			res := httptest.NewRecorder()
+			mux.ServeHTTP(res, req)
+			expectTrue(t, res.Code == tt.status)
+			if tt.body != "" {
+				expectTrue(t, res.Body.String() == tt.body)
+			}
+		})
+	}
+}
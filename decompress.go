@@ -0,0 +1,159 @@
+package httprouterx
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxDecompressedBytes is the limit of the decompressed body used by DecompressRequestAuto.
+const DefaultMaxDecompressedBytes = 10 << 20
+
+// RequestDecoder decodes a request body compressed with a content coding.
+type RequestDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// DefaultRequestDecoders returns a new registry with the built-in decoders: gzip (and its x-gzip alias) and
+// deflate. Other codings, such as br, can be added to it before passing it to DecompressRequestWithConfig.
+func DefaultRequestDecoders() map[string]RequestDecoder {
+	gz := func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+	return map[string]RequestDecoder{
+		"gzip":   gz,
+		"x-gzip": gz,
+		// the "deflate" coding is the zlib format, see RFC 9110, section 8.4.1.2.
+		"deflate": func(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) },
+	}
+}
+
+// DecompressConfig is the configuration for DecompressRequestWithConfig.
+type DecompressConfig struct {
+	// Decoders maps the content codings, in lower case, to their decoder. Default DefaultRequestDecoders().
+	Decoders map[string]RequestDecoder
+
+	// MaxBytes limits the size of the decompressed body. Default DefaultMaxDecompressedBytes.
+	MaxBytes int64
+}
+
+// DecompressRequestAuto decompresses the request bodies according to their Content-Encoding, with the built-in
+// decoders, so the handlers always read the plain body. See DecompressRequestWithConfig for the details.
+func DecompressRequestAuto() Middleware {
+	return DecompressRequestWithConfig(DecompressConfig{})
+}
+
+// DecompressRequestWithConfig is like DecompressRequestAuto, but with a custom registry of decoders and limit.
+//
+// Stacked codings, e.g. "gzip, br", are decoded in the reverse order of their application. Bodies with a coding
+// that is not in the registry are rejected with an ErrUnsupportedMediaType *HTTPError (415). The handler reads
+// the decompressed body, and the reads fail with an ErrMalformedBody *HTTPError (400) if the data is corrupt, or
+// with an ErrBodyTooLarge *HTTPError (413) past MaxBytes, which protects against decompression bombs. The
+// Content-Encoding header is removed and the Content-Length is unknown (-1) once the body is decompressed.
+func DecompressRequestWithConfig(cfg DecompressConfig) Middleware {
+	if cfg.Decoders == nil {
+		cfg.Decoders = DefaultRequestDecoders()
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxDecompressedBytes
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			codings := contentCodings(r.Header.Values("Content-Encoding"))
+			if len(codings) == 0 || r.Body == nil || r.Body == http.NoBody {
+				return next.ServeHTTP(w, r)
+			}
+
+			decoders := make([]RequestDecoder, len(codings))
+			for i, coding := range codings {
+				dec, ok := cfg.Decoders[coding]
+				if !ok {
+					return ErrUnsupportedMediaType.withCause("unsupported content encoding: "+coding, nil)
+				}
+				decoders[i] = dec
+			}
+
+			body := &decompressedBody{closers: []io.Closer{r.Body}, remaining: cfg.MaxBytes + 1, max: cfg.MaxBytes}
+			var src io.Reader = r.Body
+			for i := len(decoders) - 1; i >= 0; i-- {
+				dec, err := decoders[i](src)
+				if err != nil {
+					_ = body.Close()
+					return decompressError(err)
+				}
+				body.closers = append(body.closers, dec)
+				src = dec
+			}
+			body.src = src
+
+			r.Body = body
+			r.ContentLength = -1
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// contentCodings parses the Content-Encoding values, in the order of their application, without identity.
+func contentCodings(values []string) []string {
+	var codings []string
+	for _, v := range values {
+		for _, coding := range strings.Split(v, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	return codings
+}
+
+// decompressedBody reads the decompressed body, up to a limit, and maps the decoding errors.
+type decompressedBody struct {
+	src       io.Reader
+	closers   []io.Closer
+	remaining int64 // the limit plus one byte, to detect the overflow.
+	max       int64
+}
+
+// Read implements io.Reader.
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, ErrBodyTooLarge.withCause(fmt.Sprintf("decompressed request body must not exceed %d bytes", b.max), nil)
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.src.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining <= 0 {
+		return n - 1, ErrBodyTooLarge.withCause(fmt.Sprintf("decompressed request body must not exceed %d bytes", b.max), nil)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = decompressError(err)
+	}
+	return n, err
+}
+
+// Close closes the decoders, then the original body.
+func (b *decompressedBody) Close() error {
+	var err error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if cerr := b.closers[i].Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// decompressError maps an error of a decoder: the errors of the original body, such as a limit, are kept, and
+// the others mean corrupt data.
+func decompressError(err error) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return err
+	}
+	return ErrMalformedBody.withCause("request body contains corrupt compressed data", err)
+}
@@ -0,0 +1,109 @@
+package httprouterx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(b)
+	expectTrue(t, err == nil)
+	expectTrue(t, zw.Close() == nil)
+	return buf.Bytes()
+}
+
+func deflated(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, err := zw.Write(b)
+	expectTrue(t, err == nil)
+	expectTrue(t, zw.Close() == nil)
+	return buf.Bytes()
+}
+
+func TestDecompressRequestAuto(t *testing.T) {
+	var got string
+	h := DecompressRequestAuto().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		expectFalse(t, strings.Contains(r.Header.Get("Content-Encoding"), "gzip"))
+		b, err := io.ReadAll(r.Body)
+		got = string(b)
+		return err
+	}))
+
+	plain := []byte(`{"name":"gopher"}`)
+	tests := []struct {
+		encoding string
+		body     []byte
+		status   int
+	}{
+		{encoding: "", body: plain},
+		{encoding: "identity", body: plain},
+		{encoding: "gzip", body: gzipped(t, plain)},
+		{encoding: "x-gzip", body: gzipped(t, plain)},
+		{encoding: "deflate", body: deflated(t, plain)},
+		{encoding: "deflate, gzip", body: gzipped(t, deflated(t, plain))},
+		{encoding: "br", body: plain, status: http.StatusUnsupportedMediaType},
+		{encoding: "gzip", body: plain, status: http.StatusBadRequest},
+		{encoding: "gzip", body: gzipped(t, plain)[:20], status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		got = ""
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(tt.body))
+		req.Header.Set("Content-Encoding", tt.encoding)
+		err := h.ServeHTTP(httptest.NewRecorder(), req)
+		if tt.status == 0 {
+			expectTrue(t, err == nil)
+			expectTrue(t, got == string(plain))
+			continue
+		}
+		expectHTTPError(t, err, tt.status)
+	}
+}
+
+func TestDecompressRequestWithConfig(t *testing.T) {
+	decoders := DefaultRequestDecoders()
+	// a registered coding, standing in for brotli.
+	decoders["upper"] = func(r io.Reader) (io.ReadCloser, error) {
+		b, err := io.ReadAll(r)
+		return io.NopCloser(strings.NewReader(strings.ToUpper(string(b)))), err
+	}
+
+	var got []byte
+	h := DecompressRequestWithConfig(DecompressConfig{Decoders: decoders, MaxBytes: 1 << 10}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var err error
+		got, err = io.ReadAll(r.Body)
+		return err
+	}))
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(gzipped(t, []byte("gopher"))))
+	req.Header.Set("Content-Encoding", "upper, GZIP")
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), req) == nil)
+	expectTrue(t, string(got) == "GOPHER")
+
+	// a bomb: 1 MiB of zeros compresses to about 1 KiB.
+	bomb := gzipped(t, make([]byte, 1<<20))
+	expectTrue(t, len(bomb) < 4<<10)
+	req = httptest.NewRequest("POST", "/", bytes.NewReader(bomb))
+	req.Header.Set("Content-Encoding", "gzip")
+	err := h.ServeHTTP(httptest.NewRecorder(), req)
+	expectTrue(t, errors.Is(err, ErrBodyTooLarge))
+	expectTrue(t, len(got) == 1<<10)
+
+	// exactly at the limit.
+	req = httptest.NewRequest("POST", "/", bytes.NewReader(gzipped(t, make([]byte, 1<<10))))
+	req.Header.Set("Content-Encoding", "gzip")
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), req) == nil)
+	expectTrue(t, len(got) == 1<<10)
+}
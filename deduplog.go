@@ -0,0 +1,91 @@
+package httprouterx
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DedupErrorLogConfig is the configuration for DedupErrorLogWithConfig.
+type DedupErrorLogConfig struct {
+	// Window is the period during which identical errors are collapsed.
+	Window time.Duration
+
+	// Logger receives the log lines. Default slog.Default().
+	Logger *slog.Logger
+
+	// Render writes the error response. Default DefaultHandlers.LastResortError.
+	Render LastResortErrorHandler
+
+	// Clock is used to track the windows. Default SystemClock.
+	Clock Clock
+}
+
+// DedupErrorLog is a LastResortErrorHandler that logs the errors without flooding the logs when the same error
+// happens over and over, e.g. when a downstream service is down. The first occurrence of an error is logged
+// right away, and the identical errors (same type and message) that follow within the window are only counted.
+// Once the window has elapsed, the count is logged as a single "error repeated" line, on the next error handled.
+//
+// Only the logging is throttled: the error response is rendered every time, with
+// DefaultHandlers.LastResortError.
+func DedupErrorLog(window time.Duration, logger *slog.Logger) LastResortErrorHandler {
+	return DedupErrorLogWithConfig(DedupErrorLogConfig{Window: window, Logger: logger})
+}
+
+// DedupErrorLogWithConfig is like DedupErrorLog, but with a custom renderer and clock.
+func DedupErrorLogWithConfig(cfg DedupErrorLogConfig) LastResortErrorHandler {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Render == nil {
+		cfg.Render = DefaultHandlers.LastResortError
+	}
+	clock := clockOrSystem(cfg.Clock)
+
+	type occurrence struct {
+		err        error
+		start      time.Time
+		suppressed int
+	}
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]*occurrence)
+	)
+
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		now := clock.Now()
+		key := fmt.Sprintf("%T: %s", err, err.Error())
+
+		mu.Lock()
+		for k, o := range seen {
+			if now.Sub(o.start) < cfg.Window {
+				continue
+			}
+			if o.suppressed > 0 {
+				cfg.Logger.ErrorContext(r.Context(), "error repeated",
+					"error", o.err,
+					"count", o.suppressed,
+					"window", cfg.Window,
+				)
+			}
+			delete(seen, k)
+		}
+
+		if o, ok := seen[key]; ok {
+			o.suppressed++
+			mu.Unlock()
+		} else {
+			seen[key] = &occurrence{err: err, start: now}
+			mu.Unlock()
+			cfg.Logger.ErrorContext(r.Context(), "request failed",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"error", err,
+			)
+		}
+
+		cfg.Render(w, r, err)
+	}
+}
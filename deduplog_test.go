@@ -0,0 +1,64 @@
+package httprouterx
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupErrorLog(t *testing.T) {
+	var logs bytes.Buffer
+	clock := newFakeClock()
+	handler := DedupErrorLogWithConfig(DedupErrorLogConfig{
+		Window: time.Minute,
+		Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})),
+		Clock: clock,
+	})
+
+	dbDown := errors.New("db is down")
+	fail := func(err error) {
+		res := httptest.NewRecorder()
+		handler(res, httptest.NewRequest("GET", "/users", nil), err)
+		expectTrue(t, res.Code == http.StatusInternalServerError)
+		expectTrue(t, strings.Contains(res.Body.String(), err.Error()))
+	}
+	lines := func() []string {
+		out := strings.Split(strings.TrimSpace(logs.String()), "\n")
+		logs.Reset()
+		return out
+	}
+
+	for i := 0; i < 5; i++ {
+		fail(dbDown)
+		clock.Advance(time.Second)
+	}
+	fail(errors.New("cache is down"))
+
+	got := lines()
+	expectTrue(t, len(got) == 2)
+	expectTrue(t, strings.Contains(got[0], `msg="request failed"`) && strings.Contains(got[0], `error="db is down"`))
+	expectTrue(t, strings.Contains(got[1], `error="cache is down"`))
+
+	// after the window, the count is reported and the next error is logged again.
+	clock.Advance(time.Minute)
+	fail(dbDown)
+	got = lines()
+	expectTrue(t, len(got) == 2)
+	expectTrue(t, strings.Contains(got[0], `msg="error repeated" error="db is down" count=4 window=1m0s`))
+	expectTrue(t, strings.Contains(got[1], `msg="request failed"`) && strings.Contains(got[1], `error="db is down"`))
+
+	// an error that was not repeated is not reported.
+	expectFalse(t, strings.Contains(strings.Join(got, "\n"), "cache is down"))
+}
@@ -0,0 +1,120 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defaultSetter is implemented by the field types that handle their own default value, such as Optional.
+type defaultSetter interface {
+	setDefault(raw, layout string) error
+}
+
+var defaultSetterType = reflect.TypeOf((*defaultSetter)(nil)).Elem()
+
+// applyJSONDefaults sets the fields of the struct pointed by dst that are tagged with `default:"..."` and are
+// absent from the JSON object body. The fields of nested structs are handled too, including when the parent
+// field is absent. Fields explicitly sent by the client, even with a zero value or null, are left alone.
+func applyJSONDefaults(dst any, body []byte) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct || !hasDefaults(rv.Elem().Type()) {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil // not an object, there is no field to default.
+	}
+	return applyStructDefaults(rv.Elem(), raw)
+}
+
+func applyStructDefaults(v reflect.Value, raw map[string]json.RawMessage) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && name == "" {
+			if err := applyStructDefaults(fv, raw); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		value, present := lookupJSONField(raw, name)
+		if present {
+			nested := isNestedStruct(f.Type)
+			if !nested || !bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+				continue
+			}
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(value, &obj); err != nil {
+				continue
+			}
+			if err := applyStructDefaults(fv, obj); err != nil {
+				return err
+			}
+			continue
+		}
+
+		def, ok := f.Tag.Lookup("default")
+		switch {
+		case ok && reflect.PointerTo(f.Type).Implements(defaultSetterType):
+			if err := fv.Addr().Interface().(defaultSetter).setDefault(def, f.Tag.Get("layout")); err != nil {
+				return fmt.Errorf("httprouterx: invalid default of the field %s: %w", f.Name, err)
+			}
+		case ok:
+			if err := setFieldValue(fv, def, f.Tag.Get("layout")); err != nil {
+				return fmt.Errorf("httprouterx: invalid default of the field %s: %w", f.Name, err)
+			}
+		case isNestedStruct(f.Type):
+			if err := applyStructDefaults(fv, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookupJSONField finds the key of the field, with the same case-insensitive matching as encoding/json.
+func lookupJSONField(raw map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if v, ok := raw[name]; ok {
+		return v, true
+	}
+	for k, v := range raw {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// isNestedStruct reports whether the defaults of the struct fields of t must be handled.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(defaultSetterType)
+}
+
+// hasDefaults reports whether the struct type, or one of its nested structs, has a default tag.
+func hasDefaults(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("default"); ok {
+			return true
+		}
+		if isNestedStruct(f.Type) && hasDefaults(f.Type) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package httprouterx
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeJSON_Defaults(t *testing.T) {
+	type Paging struct {
+		Limit int    `json:"limit" default:"20"`
+		Sort  string `json:"sort" default:"created_at"`
+	}
+	type Request struct {
+		Paging
+		Name    string           `json:"name" default:"anonymous"`
+		Active  bool             `json:"active" default:"true"`
+		Ratio   float64          `json:"ratio" default:"0.5"`
+		Timeout time.Duration    `json:"timeout" default:"5s"`
+		Retries Optional[int]    `json:"retries" default:"3"`
+		Nested  struct{ N int8 } `json:"nested"`
+		Filter  struct {
+			Status string `json:"status" default:"open"`
+		} `json:"filter"`
+		Ignored string `json:"-" default:"x"`
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		check func(t *testing.T, req Request)
+	}{
+		{
+			name: "absent",
+			body: `{}`,
+			check: func(t *testing.T, req Request) {
+				expectTrue(t, req.Limit == 20 && req.Sort == "created_at")
+				expectTrue(t, req.Name == "anonymous")
+				expectTrue(t, req.Active)
+				expectTrue(t, req.Ratio == 0.5)
+				expectTrue(t, req.Timeout == 5*time.Second)
+				expectTrue(t, req.Retries.Value == 3 && !req.Retries.Set)
+				expectTrue(t, req.Filter.Status == "open")
+				expectTrue(t, req.Ignored == "")
+			},
+		},
+		{
+			name: "present",
+			body: `{"limit": 50, "name": "gopher", "ratio": 0.9, "retries": 1, "filter": {"status": "closed"}}`,
+			check: func(t *testing.T, req Request) {
+				expectTrue(t, req.Limit == 50 && req.Sort == "created_at")
+				expectTrue(t, req.Name == "gopher")
+				expectTrue(t, req.Ratio == 0.9)
+				expectTrue(t, req.Retries.Value == 1 && req.Retries.Set)
+				expectTrue(t, req.Filter.Status == "closed")
+			},
+		},
+		{
+			name: "explicit zero",
+			body: `{"LIMIT": 0, "name": "", "active": false, "ratio": 0, "retries": null, "filter": {}}`,
+			check: func(t *testing.T, req Request) {
+				expectTrue(t, req.Limit == 0)
+				expectTrue(t, req.Name == "")
+				expectFalse(t, req.Active)
+				expectTrue(t, req.Ratio == 0)
+				expectTrue(t, req.Retries.Set && req.Retries.Null && req.Retries.Value == 0)
+				expectTrue(t, req.Filter.Status == "open")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req Request
+			err := DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader(tt.body)), &req, 0)
+			expectTrue(t, err == nil)
+			tt.check(t, req)
+		})
+	}
+}
+
+func TestDecodeJSON_InvalidDefault(t *testing.T) {
+	var req struct {
+		Limit int `json:"limit" default:"twenty"`
+	}
+	err := DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader(`{}`)), &req, 0)
+	expectTrue(t, err != nil)
+	expectTrue(t, strings.Contains(err.Error(), "invalid default of the field Limit"))
+}
@@ -0,0 +1,138 @@
+package httprouterx
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProbeInterval is the interval between two health probes of the dependencies of DependencyGuard.
+const DefaultProbeInterval = 5 * time.Second
+
+// DependencyGuardConfig is the configuration for DependencyGuardWithConfig.
+type DependencyGuardConfig struct {
+	// Dependencies are the health probes of the dependencies, by name. A probe reports whether the dependency
+	// is healthy, and must return promptly once its context is done.
+	Dependencies map[string]func(context.Context) bool
+
+	// Interval is the interval between two probes. Default DefaultProbeInterval.
+	Interval time.Duration
+
+	// Timeout is the timeout of a probe. Default Interval.
+	Timeout time.Duration
+
+	// Context stops the probes when done. Default context.Background(), i.e. they run for the life of the process.
+	Context context.Context
+}
+
+// DependencyGuard fails fast with 503 Service Unavailable when one of the dependencies is known to be down,
+// instead of letting the requests pile up until they time out. All the dependencies are required by the routes
+// it wraps; use DependencyGuardWithConfig to require them per route. The probes run in the background for the
+// life of the process; use DependencyGuardWithConfig with a Context to stop them.
+func DependencyGuard(deps []func(context.Context) bool) Middleware {
+	g := newDependencyGuard(DependencyGuardConfig{}, len(deps))
+	for i, probe := range deps {
+		g.start(i, probe)
+	}
+	return g.middleware(func(RouteInfo) ([]int, bool) { return g.all, true })
+}
+
+// DependencyGuardWithConfig is like DependencyGuard, but the dependencies are named, and each route declares the
+// ones it needs in Route.Requires. Routes without Requires need all the dependencies. A route that requires an
+// unknown dependency fails with 500, since it is a programming error.
+//
+// The health of the dependencies is cached: the probes run in the background every Interval, never during a
+// request, so the guard adds no latency. Until its first probe completes, a dependency is considered healthy.
+func DependencyGuardWithConfig(cfg DependencyGuardConfig) Middleware {
+	names := make(map[string]int, len(cfg.Dependencies))
+	g := newDependencyGuard(cfg, len(cfg.Dependencies))
+	for name, probe := range cfg.Dependencies {
+		i := len(names)
+		names[name] = i
+		g.start(i, probe)
+	}
+
+	return g.middleware(func(route RouteInfo) ([]int, bool) {
+		if len(route.Requires) == 0 {
+			return g.all, true
+		}
+		required := make([]int, 0, len(route.Requires))
+		for _, name := range route.Requires {
+			i, ok := names[name]
+			if !ok {
+				return nil, false
+			}
+			required = append(required, i)
+		}
+		return required, true
+	})
+}
+
+// dependencyGuard keeps the cached health of the dependencies.
+type dependencyGuard struct {
+	cfg       DependencyGuardConfig
+	unhealthy []atomic.Bool
+	all       []int
+}
+
+func newDependencyGuard(cfg DependencyGuardConfig, n int) *dependencyGuard {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultProbeInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = cfg.Interval
+	}
+	if cfg.Context == nil {
+		cfg.Context = context.Background()
+	}
+
+	g := &dependencyGuard{cfg: cfg, unhealthy: make([]atomic.Bool, n), all: make([]int, n)}
+	for i := range g.all {
+		g.all[i] = i
+	}
+	return g
+}
+
+// start probes the i-th dependency right away, then every interval, until the context is done.
+func (g *dependencyGuard) start(i int, probe func(context.Context) bool) {
+	check := func() {
+		ctx, cancel := context.WithTimeout(g.cfg.Context, g.cfg.Timeout)
+		defer cancel()
+		g.unhealthy[i].Store(!probe(ctx))
+	}
+
+	go func() {
+		ticker := time.NewTicker(g.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			check()
+			select {
+			case <-ticker.C:
+			case <-g.cfg.Context.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (g *dependencyGuard) middleware(required func(RouteInfo) ([]int, bool)) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			route, _ := CurrentRoute(r)
+			deps, ok := required(route)
+			if !ok {
+				return NewHTTPError(http.StatusInternalServerError, "route requires an unknown dependency")
+			}
+			for _, i := range deps {
+				if g.unhealthy[i].Load() {
+					w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(g.cfg.Interval.Seconds())))))
+					return NewHTTPError(http.StatusServiceUnavailable, "a required dependency is unavailable")
+				}
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
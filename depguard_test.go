@@ -0,0 +1,108 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventually polls cond until it is true or the deadline is exceeded.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.FailNow()
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDependencyGuard(t *testing.T) {
+	db := func(ctx context.Context) bool { return true }
+	cache := func(ctx context.Context) bool { return true }
+
+	h := DependencyGuard([]func(context.Context) bool{db, cache}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, err == nil)
+}
+
+func TestDependencyGuardWithConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var dbDown, searchDown atomic.Bool
+	guard := DependencyGuardWithConfig(DependencyGuardConfig{
+		Dependencies: map[string]func(context.Context) bool{
+			"db":     func(ctx context.Context) bool { return !dbDown.Load() },
+			"search": func(ctx context.Context) bool { return !searchDown.Load() },
+		},
+		Interval: 5 * time.Millisecond,
+		Context:  ctx,
+	})
+
+	ok := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	mux := NewServeMux(Options.Middleware(guard))
+	mux.Route(Route{Method: "GET", Path: "/users", Handler: ok, Requires: []string{"db"}})
+	mux.Route(Route{Method: "GET", Path: "/search", Handler: ok, Requires: []string{"search"}})
+	mux.Route(Route{Method: "GET", Path: "/all", Handler: ok})
+	mux.Route(Route{Method: "GET", Path: "/typo", Handler: ok, Requires: []string{"dbb"}})
+
+	status := func(path string) int {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("GET", path, nil))
+		return res.Code
+	}
+
+	expectTrue(t, status("/users") == 200)
+	expectTrue(t, status("/search") == 200)
+	expectTrue(t, status("/all") == 200)
+	expectTrue(t, status("/typo") == 500)
+
+	searchDown.Store(true)
+	eventually(t, func() bool { return status("/search") == 503 })
+	expectTrue(t, status("/users") == 200)
+	expectTrue(t, status("/all") == 503)
+
+	searchDown.Store(false)
+	eventually(t, func() bool { return status("/search") == 200 })
+	expectTrue(t, status("/all") == 200)
+}
+
+func TestDependencyGuard_Unhealthy(t *testing.T) {
+	h := DependencyGuard([]func(context.Context) bool{
+		func(ctx context.Context) bool { return false },
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	res := httptest.NewRecorder()
+	eventually(t, func() bool {
+		res = httptest.NewRecorder()
+		return h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil)) != nil
+	})
+	expectTrue(t, res.Header().Get("Retry-After") == "5")
+}
+
+func TestDependencyGuard_Stop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var probes atomic.Int32
+	DependencyGuardWithConfig(DependencyGuardConfig{
+		Dependencies: map[string]func(context.Context) bool{
+			"db": func(ctx context.Context) bool { probes.Add(1); return true },
+		},
+		Interval: time.Millisecond,
+		Context:  ctx,
+	})
+	eventually(t, func() bool { return probes.Load() >= 3 })
+
+	// the probes stop once the context is done.
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	stopped := probes.Load()
+	time.Sleep(20 * time.Millisecond)
+	expectTrue(t, probes.Load() == stopped)
+}
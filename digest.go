@@ -0,0 +1,89 @@
+package httprouterx
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// DefaultContentDigestMaxBytes is the size of the largest response digested by ContentDigest.
+const DefaultContentDigestMaxBytes = 4 << 20
+
+// ContentDigestConfig is the configuration for ContentDigestWithConfig.
+type ContentDigestConfig struct {
+	// Algorithm is the digest algorithm: sha-256 or sha-512, case-insensitive. Required.
+	Algorithm string
+
+	// MaxBytes is the size of the largest response digested. Larger responses are sent without digest, as soon
+	// as they exceed it. Default DefaultContentDigestMaxBytes.
+	MaxBytes int64
+
+	// Legacy also sets the obsolete Digest header (RFC 3230), e.g. Digest: SHA-256=X48E9q..., for the clients
+	// that do not support Content-Digest yet.
+	Legacy bool
+}
+
+// ContentDigest lets the clients verify the integrity of the responses: it sets the Content-Digest header of
+// RFC 9530 on the successful (2xx) responses, e.g. Content-Digest: sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:
+// with the digest of the body, computed with algo, sha-256 or sha-512. It panics on other algorithms.
+//
+// The response is buffered to be digested, up to DefaultContentDigestMaxBytes; larger responses are sent as they
+// are written, without digest. Responses to HEAD requests, 204 No Content responses, responses that already have
+// a Content-Digest, and responses of handlers that call DisableBuffering are not digested either.
+func ContentDigest(algo string) Middleware {
+	return ContentDigestWithConfig(ContentDigestConfig{Algorithm: algo})
+}
+
+// ContentDigestWithConfig is like ContentDigest, but with a custom size limit and the legacy Digest header.
+func ContentDigestWithConfig(cfg ContentDigestConfig) Middleware {
+	var (
+		algo       = strings.ToLower(cfg.Algorithm)
+		newHash    func() hash.Hash
+		legacyName string
+	)
+	switch algo {
+	case "sha-256":
+		newHash, legacyName = sha256.New, "SHA-256"
+	case "sha-512":
+		newHash, legacyName = sha512.New, "SHA-512"
+	default:
+		panic("httprouterx: ContentDigest: unsupported algorithm " + cfg.Algorithm)
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultContentDigestMaxBytes
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Method == http.MethodHead {
+				return next.ServeHTTP(w, r)
+			}
+
+			buf, br := bufferResponse(w, r)
+			buf.limit = cfg.MaxBytes
+			if err := next.ServeHTTP(buf, br); err != nil || buf.streaming {
+				if buf.written() {
+					_ = buf.flushTo(w)
+				}
+				return err
+			}
+
+			status := buf.statusCode()
+			if status < 200 || status > 299 || status == http.StatusNoContent || buf.header.Get("Content-Digest") != "" {
+				return buf.flushTo(w)
+			}
+
+			h := newHash()
+			h.Write(buf.body.Bytes())
+			digest := base64.StdEncoding.EncodeToString(h.Sum(nil))
+			buf.header.Set("Content-Digest", algo+"=:"+digest+":")
+			if cfg.Legacy {
+				buf.header.Set("Digest", legacyName+"="+digest)
+			}
+			return buf.flushTo(w)
+		})
+	}
+}
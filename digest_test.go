@@ -0,0 +1,78 @@
+package httprouterx
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContentDigest(t *testing.T) {
+	h := ContentDigestWithConfig(ContentDigestConfig{Algorithm: "SHA-256", MaxBytes: 16, Legacy: true}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/fail":
+			return errors.New("boom")
+		}
+		_, _ = io.WriteString(w, strings.TrimPrefix(r.URL.RawQuery, "body="))
+		return nil
+	}))
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		_ = h.ServeHTTP(res, httptest.NewRequest(method, target, nil))
+		return res
+	}
+
+	res := serve("GET", "/?body=hello")
+	expectTrue(t, res.Body.String() == "hello")
+	sum := sha256.Sum256([]byte("hello"))
+	digest := base64.StdEncoding.EncodeToString(sum[:])
+	expectTrue(t, res.Header().Get("Content-Digest") == "sha-256=:"+digest+":")
+	expectTrue(t, res.Header().Get("Digest") == "SHA-256="+digest)
+
+	res = serve("GET", "/created?body=ok")
+	expectTrue(t, res.Code == http.StatusCreated)
+	expectTrue(t, res.Header().Get("Content-Digest") != "")
+
+	// the empty body has a digest too.
+	sum = sha256.Sum256(nil)
+	expectTrue(t, serve("GET", "/").Header().Get("Content-Digest") == "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+
+	// above the cap, the response is sent without digest.
+	res = serve("GET", "/?body="+strings.Repeat("x", 17))
+	expectTrue(t, res.Body.String() == strings.Repeat("x", 17))
+	expectTrue(t, res.Header().Get("Content-Digest") == "")
+	expectTrue(t, serve("GET", "/?body="+strings.Repeat("x", 16)).Header().Get("Content-Digest") != "")
+
+	// not successful, HEAD, or errors.
+	expectTrue(t, serve("GET", "/missing?body=no").Header().Get("Content-Digest") == "")
+	expectTrue(t, serve("HEAD", "/?body=head").Header().Get("Content-Digest") == "")
+	expectTrue(t, serve("GET", "/fail").Header().Get("Content-Digest") == "")
+}
+
+func TestContentDigest_SHA512(t *testing.T) {
+	h := ContentDigest("sha-512").Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = io.WriteString(w, `{"hello": "world"}`)
+		return nil
+	}))
+	res := httptest.NewRecorder()
+	_ = h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+
+	sum := sha512.Sum512([]byte(`{"hello": "world"}`))
+	expectTrue(t, res.Header().Get("Content-Digest") == "sha-512=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+	expectTrue(t, res.Header().Get("Digest") == "")
+
+	defer func() {
+		expectTrue(t, recover() != nil)
+	}()
+	ContentDigest("md5")
+}
@@ -0,0 +1,46 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// SuppressDisconnectErrors swallows the errors caused by the client going away, such as a "broken pipe" when
+// writing the response, instead of passing them to the LastResortErrorHandler: there is nobody left to read the
+// error response, and logging them as errors is only noise. They are logged at debug level with slog.Default.
+//
+// An error is considered a disconnection if it is, or wraps, syscall.EPIPE, syscall.ECONNRESET or
+// net.ErrClosed, or if it is the cancellation of the request context, which the http.Server cancels when the
+// connection is closed. Use it as the outermost middleware, e.g. with Options.Middleware.
+func SuppressDisconnectErrors() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := next.ServeHTTP(w, r)
+			if err == nil || !isDisconnectError(r, err) {
+				return err
+			}
+			slog.Default().DebugContext(r.Context(), "client disconnected",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"error", err,
+			)
+			return nil
+		})
+	}
+}
+
+// isDisconnectError reports whether err is caused by the client closing the connection.
+func isDisconnectError(r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, syscall.EPIPE), errors.Is(err, syscall.ECONNRESET), errors.Is(err, net.ErrClosed):
+		return true
+	case errors.Is(err, context.Canceled):
+		return errors.Is(r.Context().Err(), context.Canceled)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,67 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestSuppressDisconnectErrors(t *testing.T) {
+	anError := errors.New("an error")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		err        error
+		ctx        context.Context
+		suppressed bool
+	}{
+		{name: "broken pipe", err: &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}, suppressed: true},
+		{name: "connection reset", err: fmt.Errorf("write response: %w", syscall.ECONNRESET), suppressed: true},
+		{name: "closed", err: net.ErrClosed, suppressed: true},
+		{name: "client canceled", err: fmt.Errorf("query: %w", context.Canceled), ctx: canceled, suppressed: true},
+		{name: "canceled by the handler", err: context.Canceled},
+		{name: "other error", err: anError},
+		{name: "no error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := SuppressDisconnectErrors().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.ctx != nil {
+				req = req.WithContext(tt.ctx)
+			}
+			err := h.ServeHTTP(httptest.NewRecorder(), req)
+			if tt.suppressed {
+				expectTrue(t, err == nil)
+			} else {
+				expectTrue(t, errors.Is(err, tt.err) || (err == nil && tt.err == nil))
+			}
+		})
+	}
+}
+
+func TestSuppressDisconnectErrors_SkipsLastResort(t *testing.T) {
+	called := false
+	mux := NewServeMux(
+		Options.Middleware(SuppressDisconnectErrors()),
+		Options.LastResortErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) { called = true }),
+	)
+	mux.HandleFunc("GET", "/", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("write: %w", syscall.EPIPE)
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	expectFalse(t, called)
+}
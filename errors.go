@@ -0,0 +1,82 @@
+package httprouterx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// HTTPError is an error that carries the HTTP status code that should be sent to the client.
+// Handlers and middlewares return it to tell the LastResortErrorHandler which status to render,
+// instead of letting it guess.
+//
+// The optional Code is a stable, machine-readable identifier of the kind of error, such as "malformed_body".
+// Errors with a Code are rendered by DefaultHandlers.LastResortError as a JSON object, and match the sentinel of
+// the same Code with errors.Is, see ErrMalformedBody.
+type HTTPError struct {
+	Status  int
+	Code    string
+	Message string
+	Err     error
+}
+
+// The errors of the request parsing helpers, such as DecodeJSON and MultipartForm. The helpers return a new
+// *HTTPError with the Status and the Code of the sentinel, a message describing the problem, and the cause, so
+// the kind of error can be checked with errors.Is, e.g. errors.Is(err, ErrMalformedBody).
+var (
+	// ErrEmptyBody is the error of a missing request body.
+	ErrEmptyBody = &HTTPError{Status: http.StatusBadRequest, Code: "empty_body", Message: "request body must not be empty"}
+
+	// ErrMalformedBody is the error of a request body that cannot be parsed, or does not fit the destination.
+	ErrMalformedBody = &HTTPError{Status: http.StatusBadRequest, Code: "malformed_body", Message: "request body is malformed"}
+
+	// ErrBodyTooLarge is the error of a request body over the limit.
+	ErrBodyTooLarge = &HTTPError{Status: http.StatusRequestEntityTooLarge, Code: "body_too_large", Message: "request body is too large"}
+
+	// ErrUnsupportedMediaType is the error of a request body whose content type is not the expected one.
+	ErrUnsupportedMediaType = &HTTPError{Status: http.StatusUnsupportedMediaType, Code: "unsupported_media_type", Message: "unsupported media type"}
+
+	// ErrTooManyFields is the error of a form with more fields than allowed.
+	ErrTooManyFields = &HTTPError{Status: http.StatusBadRequest, Code: "too_many_fields", Message: "request has too many fields"}
+)
+
+// NewHTTPError creates a new HTTPError with the given status and message.
+// If msg is empty, the standard status text is used.
+func NewHTTPError(status int, msg string) *HTTPError {
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	return &HTTPError{Status: status, Message: msg}
+}
+
+// Error implements error.
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("http error: status: %d, message: %s: %v", e.Status, e.Message, e.Err)
+	}
+	return fmt.Sprintf("http error: status: %d, message: %s", e.Status, e.Message)
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *HTTPError) Unwrap() error { return e.Err }
+
+// Is reports whether target is an *HTTPError with the same non-empty Code.
+func (e *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// withCause creates an *HTTPError of the same kind as e, with the given message and cause.
+func (e *HTTPError) withCause(msg string, err error) *HTTPError {
+	return &HTTPError{Status: e.Status, Code: e.Code, Message: msg, Err: err}
+}
+
+// errorStatus returns the status the LastResortErrorHandler is expected to send for err: the Status of an
+// *HTTPError, or 500.
+func errorStatus(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status
+	}
+	return http.StatusInternalServerError
+}
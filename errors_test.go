@@ -0,0 +1,96 @@
+package httprouterx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPError(t *testing.T) {
+	err := NewHTTPError(http.StatusNotFound, "")
+	expectTrue(t, err.Message == "Not Found")
+	expectTrue(t, err.Error() == "http error: status: 404, message: Not Found")
+	expectTrue(t, err.Unwrap() == nil)
+
+	err = &HTTPError{Status: http.StatusBadRequest, Message: "bad body", Err: io.ErrUnexpectedEOF}
+	expectTrue(t, err.Error() == "http error: status: 400, message: bad body: unexpected EOF")
+	expectTrue(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	expectTrue(t, errorStatus(err) == http.StatusBadRequest)
+	expectTrue(t, errorStatus(io.EOF) == http.StatusInternalServerError)
+}
+
+func TestParseErrors(t *testing.T) {
+	mux := NewServeMux()
+	mux.Route(Route{Method: "POST", Path: "/json", MaxBodyBytes: 64, Handler: func(w http.ResponseWriter, r *http.Request) error {
+		var v struct {
+			Count int `json:"count"`
+		}
+		return DecodeJSON(r, &v, 32)
+	}}, MaxBodyBytes(0))
+	mux.Route(Route{Method: "POST", Path: "/form", MaxBodyBytes: 512, Handler: func(w http.ResponseWriter, r *http.Request) error {
+		_, err := MultipartForm(r, 0)
+		return err
+	}}, MaxBodyBytes(0))
+
+	body, contentType := multipartBody(t, map[string]string{"title": "hello"})
+	large, largeType := multipartBody(t, map[string]string{"file": strings.Repeat("x", 1<<10)})
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		kind        *HTTPError
+	}{
+		{name: "json ok", path: "/json", body: `{"count":1}`},
+		{name: "json empty", path: "/json", body: ``, kind: ErrEmptyBody},
+		{name: "json syntax", path: "/json", body: `{"count":`, kind: ErrMalformedBody},
+		{name: "json type", path: "/json", body: `{"count":"one"}`, kind: ErrMalformedBody},
+		{name: "json trailing", path: "/json", body: `{} {}`, kind: ErrMalformedBody},
+		{name: "json too large", path: "/json", body: `{"count":1,"padding":"` + strings.Repeat("x", 32) + `"}`, kind: ErrBodyTooLarge},
+		{name: "json over the route limit", path: "/json", body: strings.Repeat(" ", 100), kind: ErrBodyTooLarge},
+		{name: "form ok", path: "/form", contentType: contentType, body: body.String()},
+		{name: "form media type", path: "/form", contentType: "application/json", body: `{}`, kind: ErrUnsupportedMediaType},
+		{name: "form malformed", path: "/form", contentType: contentType, body: "garbage", kind: ErrMalformedBody},
+		{name: "form too large", path: "/form", contentType: largeType, body: large.String(), kind: ErrBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.ContentLength = -1 // so the limits are enforced while reading.
+			res := httptest.NewRecorder()
+			mux.ServeHTTP(res, req)
+
+			if tt.kind == nil {
+				expectTrue(t, res.Code == http.StatusOK)
+				return
+			}
+			expectTrue(t, res.Code == tt.kind.Status)
+			expectTrue(t, res.Header().Get("Content-Type") == "application/json")
+			var payload map[string]string
+			expectTrue(t, json.Unmarshal(res.Body.Bytes(), &payload) == nil)
+			expectTrue(t, payload["error"] == tt.kind.Code)
+			expectTrue(t, payload["message"] != "")
+		})
+	}
+}
+
+func TestHTTPError_Is(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", ErrMalformedBody.withCause("bad", io.ErrUnexpectedEOF))
+	expectTrue(t, errors.Is(err, ErrMalformedBody))
+	expectTrue(t, errors.Is(err, io.ErrUnexpectedEOF))
+	expectFalse(t, errors.Is(err, ErrBodyTooLarge))
+
+	// errors without a code only match themselves.
+	plain := NewHTTPError(http.StatusBadRequest, "bad")
+	expectTrue(t, errors.Is(plain, plain))
+	expectFalse(t, errors.Is(NewHTTPError(http.StatusBadRequest, "bad"), plain))
+}
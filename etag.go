@@ -0,0 +1,85 @@
+package httprouterx
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// DefaultETagMaxBufferSize is the size of the largest response given an ETag by ETagMiddleware.
+const DefaultETagMaxBufferSize = 1 << 20
+
+// ETagConfig is the configuration for ETagMiddlewareWithConfig.
+type ETagConfig struct {
+	// MaxBufferSize is the size of the largest response given an ETag. Larger responses are sent as they are
+	// written, without ETag, as soon as they exceed it. Default DefaultETagMaxBufferSize.
+	MaxBufferSize int64
+}
+
+// ETagMiddleware saves bandwidth on GET requests: it sets a strong ETag, the hash of the body, on the successful
+// (2xx) responses, and replaces them with 304 Not Modified, keeping their headers, when the If-None-Match header
+// of the request matches it, with the weak comparison of RFC 9110. A response that already has an ETag keeps it,
+// and is compared as is.
+//
+// The response is buffered to be hashed, up to DefaultETagMaxBufferSize; larger responses are sent as they are
+// written, without ETag. 204 No Content and 206 Partial Content responses, and responses of handlers that call
+// DisableBuffering, are sent untouched.
+func ETagMiddleware() Middleware {
+	return ETagMiddlewareWithConfig(ETagConfig{})
+}
+
+// ETagMiddlewareWithConfig is like ETagMiddleware, but with a custom size limit.
+func ETagMiddlewareWithConfig(cfg ETagConfig) Middleware {
+	if cfg.MaxBufferSize <= 0 {
+		cfg.MaxBufferSize = DefaultETagMaxBufferSize
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Method != http.MethodGet {
+				return next.ServeHTTP(w, r)
+			}
+
+			buf, br := bufferResponse(w, r)
+			buf.limit = cfg.MaxBufferSize
+			if err := next.ServeHTTP(buf, br); err != nil || buf.streaming {
+				if buf.written() {
+					_ = buf.flushTo(w)
+				}
+				return err
+			}
+
+			status := buf.statusCode()
+			if status < 200 || status > 299 || status == http.StatusNoContent || status == http.StatusPartialContent {
+				return buf.flushTo(w)
+			}
+
+			etag := buf.header.Get("ETag")
+			if etag == "" {
+				sum := sha256.Sum256(buf.body.Bytes())
+				etag = `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+				buf.header.Set("ETag", etag)
+			}
+
+			if ifNoneMatchSatisfied(r.Header.Values("If-None-Match"), etag) {
+				buf.header.Del("Content-Length")
+				buf.status = http.StatusNotModified
+				buf.body.Reset()
+			}
+			return buf.flushTo(w)
+		})
+	}
+}
+
+// ifNoneMatchSatisfied reports whether one of the entity tags of the If-None-Match header values matches the
+// current ETag, using the weak comparison function.
+func ifNoneMatchSatisfied(ifNoneMatch []string, current string) bool {
+	current = strings.TrimPrefix(quoteETag(current), "W/")
+	for _, tag := range parseETags(strings.Join(ifNoneMatch, ",")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return true
+		}
+	}
+	return false
+}
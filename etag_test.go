@@ -0,0 +1,66 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	mux := NewServeMux(Options.Middleware(ETagMiddlewareWithConfig(ETagConfig{MaxBufferSize: 8})))
+	mux.HandleFunc("GET", "/small", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, err := io.WriteString(w, "hello")
+		return err
+	})
+	mux.HandleFunc("GET", "/large", func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.WriteString(w, strings.Repeat("x", 16))
+		return err
+	})
+	mux.HandleFunc("GET", "/tagged", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("ETag", `W/"v1"`)
+		_, err := io.WriteString(w, "hello")
+		return err
+	})
+	mux.HandleFunc("GET", "/missing", func(w http.ResponseWriter, r *http.Request) error {
+		return NewHTTPError(http.StatusNotFound, "missing")
+	})
+
+	rec := mux.TestRequest("GET", "/small", nil)
+	etag := rec.Header().Get("ETag")
+	expectTrue(t, rec.Code == http.StatusOK && rec.Body.String() == "hello")
+	expectTrue(t, strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`))
+
+	req := httptest.NewRequest("GET", "/small", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	expectTrue(t, rec.Code == http.StatusNotModified && rec.Body.Len() == 0)
+	expectTrue(t, rec.Header().Get("ETag") == etag)
+	expectTrue(t, rec.Header().Get("Cache-Control") == "max-age=60")
+
+	req = httptest.NewRequest("GET", "/small", nil)
+	req.Header.Set("If-None-Match", `"other"`)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	expectTrue(t, rec.Code == http.StatusOK && rec.Body.String() == "hello")
+
+	// the ETag of the handler is kept.
+	req = httptest.NewRequest("GET", "/tagged", nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	expectTrue(t, rec.Code == http.StatusNotModified)
+	expectTrue(t, rec.Header().Get("ETag") == `W/"v1"`)
+
+	// larger than MaxBufferSize.
+	rec = mux.TestRequest("GET", "/large", nil)
+	expectTrue(t, rec.Code == http.StatusOK && rec.Body.Len() == 16)
+	expectTrue(t, rec.Header().Get("ETag") == "")
+
+	rec = mux.TestRequest("GET", "/missing", nil)
+	expectTrue(t, rec.Code == http.StatusNotFound)
+	expectTrue(t, rec.Header().Get("ETag") == "")
+}
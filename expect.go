@@ -0,0 +1,52 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// SendContinue sends the 100 Continue interim response, telling the client to send the request body. It is
+// written through w: the writers of this package pass the interim responses through to the connection, even the
+// ones that buffer the final response, as custom writers wrapping w must do. It requires Go 1.19 or later, which
+// added support for sending 1xx responses with WriteHeader.
+//
+// The Go server also sends 100 Continue on the first read of the body, so this is only needed to send it
+// earlier. It must be called before the response header is written.
+func SendContinue(w http.ResponseWriter) error {
+	if w == nil {
+		return errors.New("httprouterx: SendContinue: nil response writer")
+	}
+	w.WriteHeader(http.StatusContinue)
+	return nil
+}
+
+// ExpectContinue lets the route decide whether to accept the body of requests with the Expect: 100-continue
+// header, before the client sends it. This allows rejecting large uploads early, e.g. after checking
+// authorization or the Content-Length.
+//
+// When accept returns nil, 100 Continue is sent and the request is served. Otherwise, the error is returned
+// without reading the body: an *HTTPError keeps its status, and any other error is mapped to
+// 417 Expectation Failed.
+func ExpectContinue(accept func(*http.Request) error) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if !strings.EqualFold(r.Header.Get("Expect"), "100-continue") {
+				return next.ServeHTTP(w, r)
+			}
+
+			if err := accept(r); err != nil {
+				var httpErr *HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				return &HTTPError{Status: http.StatusExpectationFailed, Message: http.StatusText(http.StatusExpectationFailed), Err: err}
+			}
+
+			if err := SendContinue(w); err != nil {
+				return err
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package httprouterx
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectContinue(t *testing.T) {
+	errTooLarge := errors.New("too large")
+	accept := func(r *http.Request) error {
+		if r.ContentLength > 10 {
+			return errTooLarge
+		}
+		return nil
+	}
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "PUT", Path: "/upload", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}}, ExpectContinue(accept))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	send := func(t *testing.T, body string) (status string, continued bool) {
+		t.Helper()
+		conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+		expectTrue(t, err == nil)
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		_, err = io.WriteString(conn, "PUT /upload HTTP/1.1\r\nHost: example.com\r\nExpect: 100-continue\r\n"+
+			"Content-Length: "+strconv.Itoa(len(body))+"\r\n\r\n")
+		expectTrue(t, err == nil)
+
+		br := bufio.NewReader(conn)
+		line, err := br.ReadString('\n')
+		expectTrue(t, err == nil)
+		if strings.HasPrefix(line, "HTTP/1.1 100") {
+			continued = true
+			_, _ = br.ReadString('\n') // blank line after the interim response.
+			_, err = io.WriteString(conn, body)
+			expectTrue(t, err == nil)
+			line, err = br.ReadString('\n')
+			expectTrue(t, err == nil)
+		}
+		return strings.TrimSpace(line), continued
+	}
+
+	t.Run("accepted", func(t *testing.T) {
+		status, continued := send(t, "small")
+		expectTrue(t, continued)
+		expectTrue(t, status == "HTTP/1.1 200 OK")
+	})
+
+	t.Run("rejected before the body is sent", func(t *testing.T) {
+		status, continued := send(t, "this body is too large")
+		expectFalse(t, continued)
+		expectTrue(t, status == "HTTP/1.1 417 Expectation Failed")
+	})
+}
+
+func TestExpectContinue_WithoutExpectation(t *testing.T) {
+	called := false
+	h := ExpectContinue(func(r *http.Request) error { called = true; return nil }).
+		Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/", strings.NewReader("body")))
+	expectTrue(t, err == nil)
+	expectFalse(t, called)
+}
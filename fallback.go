@@ -0,0 +1,73 @@
+package httprouterx
+
+import "net/http"
+
+// WithFallback serves the request with primary, and falls back to secondary when primary returns an error that
+// matches any of the optional predicates (or any error if no predicate is given). This enables graceful
+// degradation, e.g. serving a stale cache when the database is down.
+//
+// The primary response is buffered, and the fallback only happens if primary has not written anything
+// (neither the status nor the body) before returning the error. Otherwise, whatever primary has written is sent
+// and its error is returned, since the response can no longer be replaced.
+func WithFallback(primary, secondary HandlerFunc, when ...func(error) bool) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		buf, br := bufferResponse(w, r)
+		err := primary(buf, br)
+		if err == nil {
+			return buf.flushTo(w)
+		}
+
+		if buf.written() || !shouldFallback(err, when) {
+			if buf.written() {
+				_ = buf.flushTo(w)
+			}
+			return err
+		}
+		return secondary(w, r)
+	}
+}
+
+func shouldFallback(err error, predicates []func(error) bool) bool {
+	if len(predicates) == 0 {
+		return true
+	}
+	for _, match := range predicates {
+		if match(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadReplicaFailover retries a failed read once against a replica. When the handler returns an error matching
+// shouldFailover before writing anything, the handler is called again with the request returned by withReplica,
+// which typically sets a context flag that the handler reads to choose the replica datasource.
+//
+// Only safe methods (GET, HEAD and OPTIONS) are retried, since they are idempotent and do not need the
+// primary datasource. The retry happens at most once, and its result is final. A nil shouldFailover fails over
+// on any error.
+func ReadReplicaFailover(shouldFailover func(error) bool, withReplica func(*http.Request) *http.Request) Middleware {
+	if withReplica == nil {
+		panic("httprouterx: ReadReplicaFailover: withReplica is required")
+	}
+	var when []func(error) bool
+	if shouldFailover != nil {
+		when = append(when, shouldFailover)
+	}
+
+	return func(next Handler) Handler {
+		replica := func(w http.ResponseWriter, r *http.Request) error {
+			return next.ServeHTTP(w, withReplica(r))
+		}
+		failover := WithFallback(next.ServeHTTP, replica, when...)
+
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return failover(w, r)
+			default:
+				return next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
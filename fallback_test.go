@@ -0,0 +1,132 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithFallback(t *testing.T) {
+	errDown := errors.New("database is down")
+	errOther := errors.New("other error")
+
+	secondary := func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.WriteString(w, "stale")
+		return err
+	}
+
+	isDown := func(err error) bool { return errors.Is(err, errDown) }
+
+	t.Run("primary succeeds", func(t *testing.T) {
+		h := WithFallback(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("X-Source", "primary")
+			w.WriteHeader(201)
+			_, err := io.WriteString(w, "fresh")
+			return err
+		}, secondary, isDown)
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == 201)
+		expectTrue(t, res.Header().Get("X-Source") == "primary")
+		expectTrue(t, res.Body.String() == "fresh")
+	})
+
+	t.Run("primary fails before writing", func(t *testing.T) {
+		h := WithFallback(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("X-Source", "primary")
+			return errDown
+		}, secondary, isDown)
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Body.String() == "stale")
+		expectTrue(t, res.Header().Get("X-Source") == "")
+	})
+
+	t.Run("primary fails after writing", func(t *testing.T) {
+		h := WithFallback(func(w http.ResponseWriter, r *http.Request) error {
+			_, _ = io.WriteString(w, "partial")
+			return errDown
+		}, secondary, isDown)
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, errors.Is(err, errDown))
+		expectTrue(t, res.Body.String() == "partial")
+	})
+
+	t.Run("error does not match predicate", func(t *testing.T) {
+		h := WithFallback(func(w http.ResponseWriter, r *http.Request) error {
+			return errOther
+		}, secondary, isDown)
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, errors.Is(err, errOther))
+		expectTrue(t, res.Body.Len() == 0)
+	})
+}
+
+func TestReadReplicaFailover(t *testing.T) {
+	type replicaKey struct{}
+	errPrimaryDown := errors.New("primary is down")
+
+	withReplica := func(r *http.Request) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), replicaKey{}, true))
+	}
+
+	var attempts int
+	h := ReadReplicaFailover(func(err error) bool { return errors.Is(err, errPrimaryDown) }, withReplica).
+		Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			attempts++
+			if r.Context().Value(replicaKey{}) == nil {
+				return errPrimaryDown
+			}
+			_, err := io.WriteString(w, "from replica")
+			return err
+		}))
+
+	t.Run("GET is retried on the replica", func(t *testing.T) {
+		attempts = 0
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, attempts == 2)
+		expectTrue(t, res.Body.String() == "from replica")
+	})
+
+	t.Run("POST is not retried", func(t *testing.T) {
+		attempts = 0
+		err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+		expectTrue(t, errors.Is(err, errPrimaryDown))
+		expectTrue(t, attempts == 1)
+	})
+}
+
+func TestReadReplicaFailover_AnyError(t *testing.T) {
+	type replicaKey struct{}
+	withReplica := func(r *http.Request) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), replicaKey{}, true))
+	}
+
+	h := ReadReplicaFailover(nil, withReplica).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Context().Value(replicaKey{}) == nil {
+			return errors.New("any error")
+		}
+		_, err := io.WriteString(w, "from replica")
+		return err
+	}))
+
+	res := httptest.NewRecorder()
+	expectTrue(t, h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil)) == nil)
+	expectTrue(t, res.Body.String() == "from replica")
+
+	defer func() { expectTrue(t, recover() != nil) }()
+	ReadReplicaFailover(nil, nil)
+}
@@ -0,0 +1,111 @@
+package httprouterx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// FieldFilter implements partial responses: when the request has a fields query parameter, such as
+// ?fields=id,name,address.city, only the selected fields of successful (2xx) JSON responses are sent.
+//
+// The fields are comma-separated and nested fields are selected with dots. Selecting a field selects all of its
+// children. When the response, or a selected field, is an array, the selection is applied to each element.
+// A malformed selection, such as an empty or invalid field name, is rejected with 400 Bad Request before the
+// handler is called. Requests without fields, and non-2xx or non-JSON responses, are left untouched.
+//
+// The response is buffered to be filtered, and the Content-Length is adjusted to the filtered body. Note that the
+// fields of the filtered objects are sent in alphabetical order.
+func FieldFilter() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if !r.URL.Query().Has("fields") {
+				return next.ServeHTTP(w, r)
+			}
+
+			selection, err := parseFieldSelection(r.URL.Query().Get("fields"))
+			if err != nil {
+				return err
+			}
+
+			return rewriteJSONResponse(w, r, next, isJSONContentType, selection.apply)
+		})
+	}
+}
+
+// fieldSelection is a tree of selected fields. A nil child means the whole field is selected.
+type fieldSelection map[string]fieldSelection
+
+// parseFieldSelection parses a comma-separated list of dotted field paths.
+func parseFieldSelection(s string) (fieldSelection, error) {
+	selection := make(fieldSelection)
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		names := strings.Split(field, ".")
+		for _, name := range names {
+			if !isValidFieldName(name) {
+				return nil, NewHTTPError(http.StatusBadRequest, "invalid field selection: "+strconv.Quote(field))
+			}
+		}
+
+		node := selection
+		for i, name := range names {
+			child, seen := node[name]
+			if seen && child == nil {
+				break // the whole field is already selected.
+			}
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+			if child == nil {
+				child = make(fieldSelection)
+				node[name] = child
+			}
+			node = child
+		}
+	}
+	return selection, nil
+}
+
+// isValidFieldName reports whether name is a non-empty sequence of ASCII letters, digits, '_' and '-'.
+func isValidFieldName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the selected part of the JSON value.
+func (s fieldSelection) apply(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(s))
+		for name, child := range s {
+			value, ok := v[name]
+			if !ok {
+				continue
+			}
+			if child == nil {
+				out[name] = value
+			} else {
+				out[name] = child.apply(value)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = s.apply(elem)
+		}
+		return out
+	default:
+		return v
+	}
+}
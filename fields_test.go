@@ -0,0 +1,96 @@
+package httprouterx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestFieldFilter(t *testing.T) {
+	body := `{"id":1,"name":"gopher","email":"g@example.com","address":{"city":"Jakarta","zip":"10110"},` +
+		`"orders":[{"id":10,"total":5},{"id":11,"total":7}]}`
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "GET", Path: "/users/1", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write([]byte(body))
+		return nil
+	}}, FieldFilter())
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   string
+	}{
+		{name: "no fields", query: "", status: 200, want: body},
+		{name: "top level", query: "?fields=id,name", status: 200, want: `{"id":1,"name":"gopher"}`},
+		{name: "nested", query: "?fields=id,address.city", status: 200, want: `{"address":{"city":"Jakarta"},"id":1}`},
+		{name: "nested in array", query: "?fields=orders.id", status: 200, want: `{"orders":[{"id":10},{"id":11}]}`},
+		{name: "parent wins", query: "?fields=address.city,address", status: 200, want: `{"address":{"city":"Jakarta","zip":"10110"}}`},
+		{name: "unknown field", query: "?fields=id,nope", status: 200, want: `{"id":1}`},
+		{name: "empty name", query: "?fields=id,,name", status: 400},
+		{name: "empty segment", query: "?fields=address..city", status: 400},
+		{name: "invalid name", query: "?fields=na%20me", status: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			mux.ServeHTTP(res, httptest.NewRequest("GET", "/users/1"+tt.query, nil))
+			expectTrue(t, res.Code == tt.status)
+			if tt.status == 200 {
+				expectTrue(t, res.Body.String() == tt.want)
+				expectTrue(t, res.Header().Get("Content-Length") == strconv.Itoa(len(tt.want)))
+			}
+		})
+	}
+}
+
+func TestFieldFilter_Untouched(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+	}{
+		{name: "error status", status: 404, contentType: "application/json"},
+		{name: "not json", status: 200, contentType: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"id":1,"name":"gopher"}`
+			h := FieldFilter().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(body))
+				return nil
+			}))
+
+			res := httptest.NewRecorder()
+			err := h.ServeHTTP(res, httptest.NewRequest("GET", "/?fields=id", nil))
+			expectTrue(t, err == nil)
+			expectTrue(t, res.Code == tt.status)
+			expectTrue(t, res.Body.String() == body)
+		})
+	}
+}
+
+func TestFieldFilter_LargeNumbers(t *testing.T) {
+	h := FieldFilter().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":9007199254740993,"x":1}]`))
+		return nil
+	}))
+
+	res := httptest.NewRecorder()
+	err := h.ServeHTTP(res, httptest.NewRequest("GET", "/?fields=id", nil))
+	expectTrue(t, err == nil)
+
+	var got []map[string]json.Number
+	expectTrue(t, json.Unmarshal(res.Body.Bytes(), &got) == nil)
+	expectTrue(t, len(got) == 1 && got[0]["id"] == "9007199254740993")
+}
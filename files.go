@@ -0,0 +1,47 @@
+package httprouterx
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	pathpkg "path"
+	"strings"
+)
+
+// ServeFiles serves the files of root, e.g. an embed.FS with a built single-page app, at the path, which must end
+// with "/*filepath", e.g. "/static/*filepath". A request for /static/css/app.css serves the file css/app.css of
+// root, with the http.FileServer of the standard library.
+//
+// Missing files are answered by the NotFound handler of the ServeMux, for a consistent experience with the other
+// routes, instead of the plain 404 of the file server. Only the GET method is registered, like
+// httprouter.Router.ServeFiles. It panics if the path does not end with "/*filepath".
+func (mux *ServeMux) ServeFiles(path string, root fs.FS) {
+	if !strings.HasSuffix(path, "/*filepath") {
+		panic("httprouterx: ServeFiles: path must end with /*filepath in path '" + path + "'")
+	}
+
+	fileServer := http.FileServer(http.FS(root))
+	mux.GET(path, func(w http.ResponseWriter, r *http.Request) error {
+		name := PathParams(r).ByName("filepath")
+		if _, err := fs.Stat(root, fsName(name)); errors.Is(err, fs.ErrNotExist) {
+			mux.conf.NotFound.ServeHTTP(w, r)
+			return nil
+		}
+
+		req := *r
+		u := *r.URL
+		u.Path, u.RawPath = name, ""
+		req.URL = &u
+		fileServer.ServeHTTP(w, &req)
+		return nil
+	})
+}
+
+// fsName converts the URL path to the name of the file in an fs.FS.
+func fsName(urlPath string) string {
+	name := strings.TrimPrefix(pathpkg.Clean("/"+urlPath), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,47 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestServeMux_ServeFiles(t *testing.T) {
+	root := fstest.MapFS{
+		"index.html":  {Data: []byte("<html>home</html>")},
+		"css/app.css": {Data: []byte("body{}")},
+	}
+	mux := NewServeMux(Options.NotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("custom not found"))
+	})))
+	mux.ServeFiles("/static/*filepath", root)
+
+	tests := []struct {
+		target string
+		status int
+		body   string
+	}{
+		{target: "/static/css/app.css", status: 200, body: "body{}"},
+		{target: "/static/", status: 200, body: "<html>home</html>"},
+		{target: "/static/missing.js", status: 404, body: "custom not found"},
+		{target: "/static/css/../missing.js", status: 404, body: "custom not found"},
+	}
+
+	for _, tt := range tests {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("GET", tt.target, nil))
+		expectTrue(t, res.Code == tt.status)
+		if tt.body != "" {
+			expectTrue(t, res.Body.String() == tt.body)
+		}
+	}
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/static/css/app.css", nil))
+	expectTrue(t, res.Header().Get("Content-Type") == "text/css; charset=utf-8")
+
+	defer func() { expectTrue(t, recover() != nil) }()
+	mux.ServeFiles("/assets", root)
+}
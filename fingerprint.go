@@ -0,0 +1,99 @@
+package httprouterx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// defaultFingerprintMaxBody is the default number of body bytes included in the fingerprint.
+const defaultFingerprintMaxBody = 1 << 20
+
+// FingerprintConfig selects the request components included in the fingerprint.
+// If no component is selected, Method, Path and Query are used.
+type FingerprintConfig struct {
+	Method bool
+	Path   bool
+	Query  bool
+
+	// Headers are the names of the headers to include.
+	Headers []string
+
+	// Body includes up to MaxBodyBytes of the request body, which is restored for the next handlers.
+	Body bool
+
+	// MaxBodyBytes limits the body bytes included in the fingerprint. Default 1 MiB.
+	MaxBodyBytes int64
+}
+
+// Fingerprint computes a stable hash of the selected request components and stores it in the context,
+// so it can be reused by deduplication, caching or rate limiting, via RequestFingerprint.
+//
+// The fingerprint is the hex-encoded SHA-256 of the selected components, in this order, each one followed by
+// a line feed (except the body):
+//   - Method: the request method, e.g. GET.
+//   - Path: the escaped URL path.
+//   - Query: the query with sorted keys and sorted values, e.g. a=1&a=2&b=3.
+//   - Headers: one line per header, sorted by the lower-cased name, as name:value1,value2.
+//   - Body: the raw body bytes.
+func Fingerprint(cfg FingerprintConfig) Middleware {
+	if !cfg.Method && !cfg.Path && !cfg.Query && len(cfg.Headers) == 0 && !cfg.Body {
+		cfg.Method, cfg.Path, cfg.Query = true, true, true
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaultFingerprintMaxBody
+	}
+
+	headers := make([]string, len(cfg.Headers))
+	for i, h := range cfg.Headers {
+		headers[i] = strings.ToLower(h)
+	}
+	sort.Strings(headers)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			h := sha256.New()
+			if cfg.Method {
+				_, _ = io.WriteString(h, r.Method+"\n")
+			}
+			if cfg.Path {
+				_, _ = io.WriteString(h, r.URL.EscapedPath()+"\n")
+			}
+			if cfg.Query {
+				_, _ = io.WriteString(h, CanonicalQuery(r)+"\n")
+			}
+			for _, name := range headers {
+				_, _ = io.WriteString(h, name+":"+strings.Join(r.Header.Values(name), ",")+"\n")
+			}
+			if cfg.Body && r.Body != nil && r.Body != http.NoBody {
+				prefix, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBodyBytes))
+				if err != nil {
+					return err
+				}
+				_, _ = h.Write(prefix)
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(prefix), r.Body), Closer: r.Body}
+			}
+
+			fp := hex.EncodeToString(h.Sum(nil))
+			return next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), fingerprintKey, fp)))
+		})
+	}
+}
+
+// RequestFingerprint gets the fingerprint computed by the Fingerprint middleware.
+// It returns an empty string if the middleware was not applied.
+func RequestFingerprint(r *http.Request) string {
+	fp, _ := r.Context().Value(fingerprintKey).(string)
+	return fp
+}
+
+// readCloser combines a reader with the closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
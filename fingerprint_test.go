@@ -0,0 +1,59 @@
+package httprouterx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func fingerprintOf(t *testing.T, cfg FingerprintConfig, req *http.Request) (fp, body string) {
+	t.Helper()
+	h := Fingerprint(cfg).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		fp = RequestFingerprint(r)
+		b, err := io.ReadAll(r.Body)
+		body = string(b)
+		return err
+	}))
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), req) == nil)
+	return fp, body
+}
+
+func TestFingerprint(t *testing.T) {
+	cfg := FingerprintConfig{Method: true, Path: true, Query: true, Headers: []string{"X-Tenant", "Accept"}, Body: true}
+
+	req1 := httptest.NewRequest("POST", "/data?b=2&a=2&a=1", strings.NewReader("payload"))
+	req1.Header.Set("Accept", "application/json")
+	req1.Header.Set("X-Tenant", "acme")
+	fp1, body := fingerprintOf(t, cfg, req1)
+	expectTrue(t, body == "payload")
+
+	req2 := httptest.NewRequest("POST", "/data?a=1&b=2&a=2", strings.NewReader("payload"))
+	req2.Header.Set("X-Tenant", "acme")
+	req2.Header.Set("Accept", "application/json")
+	fp2, _ := fingerprintOf(t, cfg, req2)
+	expectTrue(t, fp1 == fp2)
+
+	// the documented format can be reproduced by clients.
+	sum := sha256.Sum256([]byte("POST\n/data\na=1&a=2&b=2\naccept:application/json\nx-tenant:acme\npayload"))
+	expectTrue(t, fp1 == hex.EncodeToString(sum[:]))
+
+	req3 := httptest.NewRequest("POST", "/data?a=1&b=2&a=2", strings.NewReader("other"))
+	req3.Header.Set("X-Tenant", "acme")
+	req3.Header.Set("Accept", "application/json")
+	fp3, _ := fingerprintOf(t, cfg, req3)
+	expectTrue(t, fp1 != fp3)
+}
+
+func TestFingerprint_DefaultComponents(t *testing.T) {
+	fp1, _ := fingerprintOf(t, FingerprintConfig{}, httptest.NewRequest("GET", "/data?x=1", strings.NewReader("a")))
+	fp2, _ := fingerprintOf(t, FingerprintConfig{}, httptest.NewRequest("GET", "/data?x=1", strings.NewReader("b")))
+	fp3, _ := fingerprintOf(t, FingerprintConfig{}, httptest.NewRequest("GET", "/data?x=2", nil))
+	expectTrue(t, fp1 != "")
+	expectTrue(t, fp1 == fp2)
+	expectTrue(t, fp1 != fp3)
+	expectTrue(t, RequestFingerprint(httptest.NewRequest("GET", "/", nil)) == "")
+}
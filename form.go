@@ -0,0 +1,85 @@
+package httprouterx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Default limits of FormLimits.
+const (
+	DefaultFormMaxBytes  = 10 << 20
+	DefaultFormMaxFields = 1000
+)
+
+// FormLimits hardens the endpoints of classic form posts against memory exhaustion and parameter pollution: it
+// checks application/x-www-form-urlencoded request bodies against the limits, then parses the form, so the
+// handler finds it in r.Form and r.PostForm as after r.ParseForm. A limit that is not positive means its default:
+// DefaultFormMaxBytes and DefaultFormMaxFields.
+//
+// The body is rejected before being parsed with ErrBodyTooLarge (413) when it exceeds maxBytes, and with
+// ErrTooManyFields (400) when the body and the query, which are merged in r.Form, have more than maxFields fields
+// together. A malformed body is rejected with ErrMalformedBody (400). Other requests, including the multipart
+// ones, see MultipartLimits, are left untouched.
+func FormLimits(maxBytes int64, maxFields int) Middleware {
+	if maxBytes <= 0 {
+		maxBytes = DefaultFormMaxBytes
+	}
+	if maxFields <= 0 {
+		maxFields = DefaultFormMaxFields
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/x-www-form-urlencoded" || r.Body == nil {
+				return next.ServeHTTP(w, r)
+			}
+			if r.ContentLength > maxBytes {
+				return ErrBodyTooLarge.withCause(fmt.Sprintf("request body must not exceed %d bytes", maxBytes), nil)
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBytes))
+			if err != nil {
+				var (
+					httpErr *HTTPError
+					maxErr  *http.MaxBytesError
+				)
+				switch {
+				case errors.As(err, &httpErr):
+					return err
+				case errors.As(err, &maxErr):
+					return ErrBodyTooLarge.withCause(fmt.Sprintf("request body must not exceed %d bytes", maxBytes), err)
+				}
+				return err
+			}
+			if countFormFields(string(body))+countFormFields(r.URL.RawQuery) > maxFields {
+				return ErrTooManyFields.withCause(fmt.Sprintf("form must not have more than %d fields", maxFields), nil)
+			}
+
+			r.Body = readCloser{Reader: bytes.NewReader(body), Closer: r.Body}
+			if err := r.ParseForm(); err != nil {
+				return ErrMalformedBody.withCause("request body contains a malformed form", err)
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// countFormFields counts the fields of the urlencoded form, without decoding them. Empty fields, e.g. between
+// two '&', are ignored like url.ParseQuery does.
+func countFormFields(s string) int {
+	n := 0
+	for s != "" {
+		var field string
+		field, s, _ = strings.Cut(s, "&")
+		if field != "" {
+			n++
+		}
+	}
+	return n
+}
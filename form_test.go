@@ -0,0 +1,61 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFormLimits(t *testing.T) {
+	var form, postForm string
+	h := FormLimits(64, 5).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		form, postForm = r.Form.Encode(), r.PostForm.Encode()
+		return nil
+	}))
+
+	serve := func(target, contentType, body string) error {
+		req := httptest.NewRequest("POST", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	const urlencoded = "application/x-www-form-urlencoded"
+	expectTrue(t, serve("/?page=2", urlencoded, "name=Ada&lang=go&lang=c") == nil)
+	expectTrue(t, form == "lang=go&lang=c&name=Ada&page=2")
+	expectTrue(t, postForm == "lang=go&lang=c&name=Ada")
+
+	// oversized body.
+	err := serve("/", urlencoded, "data="+strings.Repeat("x", 64))
+	expectTrue(t, errors.Is(err, ErrBodyTooLarge))
+	expectHTTPError(t, err, http.StatusRequestEntityTooLarge)
+
+	// without Content-Length.
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", urlencoded)
+	req.Body = readCloser{Reader: strings.NewReader("data=" + strings.Repeat("x", 64)), Closer: http.NoBody}
+	req.ContentLength = -1
+	err = h.ServeHTTP(httptest.NewRecorder(), req)
+	expectTrue(t, errors.Is(err, ErrBodyTooLarge))
+
+	// field-heavy bodies, the query counts too.
+	var fields []string
+	for i := 0; i < 6; i++ {
+		fields = append(fields, "f"+strconv.Itoa(i)+"=1")
+	}
+	err = serve("/", urlencoded, strings.Join(fields, "&"))
+	expectTrue(t, errors.Is(err, ErrTooManyFields))
+	expectHTTPError(t, err, http.StatusBadRequest)
+	expectTrue(t, serve("/", urlencoded, strings.Join(fields[:5], "&")+"&&") == nil)
+	expectTrue(t, errors.Is(serve("/?a=1", urlencoded, strings.Join(fields[:5], "&")), ErrTooManyFields))
+
+	// malformed body.
+	expectTrue(t, errors.Is(serve("/", urlencoded, "a=%zz"), ErrMalformedBody))
+
+	// other content types are left untouched.
+	form = ""
+	expectTrue(t, serve("/", "text/plain", strings.Repeat("a=1&", 100)) == nil)
+	expectTrue(t, form == "")
+}
@@ -0,0 +1,159 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// GCRAStore keeps the theoretical arrival time (TAT) of each key of GCRALimit. Implement it on top of a shared
+// database, such as Redis, to enforce the limit across several instances.
+type GCRAStore interface {
+	// Get returns the TAT of the key, or the zero time if the key is unknown or expired.
+	Get(ctx context.Context, key string) (time.Time, error)
+
+	// CompareAndSet sets the TAT of the key to tat if it is still old (the zero time for an unknown key), and
+	// reports whether it did. The key may be forgotten after ttl.
+	CompareAndSet(ctx context.Context, key string, old, tat time.Time, ttl time.Duration) (bool, error)
+}
+
+// GCRAConfig is the configuration for GCRALimitWithConfig.
+type GCRAConfig struct {
+	// Rate is the emission interval: one request per Rate is allowed in the long run. Required, must be positive.
+	Rate time.Duration
+
+	// Burst is the number of requests allowed at once. Default 1.
+	Burst int
+
+	// Key is the key of the limit, e.g. the client IP or the API key. Required.
+	Key func(*http.Request) string
+
+	// Store keeps the state of the keys. Default a new in-memory store.
+	Store GCRAStore
+
+	// Clock is used to compute the arrival times. Default SystemClock.
+	Clock Clock
+}
+
+// GCRALimit limits the rate of the requests per key with the generic cell rate algorithm: one request per rate
+// is allowed in the long run, with bursts of up to burst requests. Unlike a token bucket refilled periodically,
+// the capacity leaks continuously, so the traffic is smoothed. Requests over the limit are rejected with
+// 429 Too Many Requests and a Retry-After header telling exactly when the next request will be allowed. Every
+// response carries the rate limit headers, see WriteRateLimitHeaders, with the burst as the limit.
+func GCRALimit(rate time.Duration, burst int, keyFn func(*http.Request) string) Middleware {
+	return GCRALimitWithConfig(GCRAConfig{Rate: rate, Burst: burst, Key: keyFn})
+}
+
+// GCRALimitWithConfig is like GCRALimit, but with a custom store and clock. It panics if the rate is not
+// positive or the key function is nil.
+func GCRALimitWithConfig(cfg GCRAConfig) Middleware {
+	if cfg.Rate <= 0 {
+		panic("httprouterx: GCRALimit: the rate must be positive, got " + cfg.Rate.String())
+	}
+	if cfg.Key == nil {
+		panic("httprouterx: GCRALimit: the key function is required")
+	}
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryGCRAStore()
+	}
+	clock := clockOrSystem(cfg.Clock)
+	tolerance := cfg.Rate * time.Duration(cfg.Burst-1)
+
+	// maxAttempts bounds the compare-and-set loop under heavy contention on a single key.
+	const maxAttempts = 10
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			key := cfg.Key(r)
+			for attempt := 0; attempt < maxAttempts; attempt++ {
+				old, err := cfg.Store.Get(r.Context(), key)
+				if err != nil {
+					return err
+				}
+
+				now := clock.Now()
+				tat := old
+				if tat.Before(now) {
+					tat = now
+				}
+				if allowAt := tat.Add(-tolerance); now.Before(allowAt) {
+					WriteRateLimitHeaders(w, RateLimitInfo{
+						Limit:      cfg.Burst,
+						Reset:      tat.Sub(now),
+						RetryAfter: allowAt.Sub(now),
+					})
+					return NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+				}
+
+				tat = tat.Add(cfg.Rate)
+				ok, err := cfg.Store.CompareAndSet(r.Context(), key, old, tat, tat.Sub(now))
+				if err != nil {
+					return err
+				}
+				if ok {
+					WriteRateLimitHeaders(w, RateLimitInfo{
+						Limit:     cfg.Burst,
+						Remaining: gcraRemaining(now, tat, tolerance, cfg.Rate),
+						Reset:     tat.Sub(now),
+					})
+					return next.ServeHTTP(w, r)
+				}
+			}
+			return NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+		})
+	}
+}
+
+// gcraRemaining returns the number of requests allowed at now, given the TAT.
+func gcraRemaining(now, tat time.Time, tolerance, rate time.Duration) int {
+	slack := now.Sub(tat) + tolerance
+	if slack < 0 {
+		return 0
+	}
+	return int(slack/rate) + 1
+}
+
+// MemoryGCRAStore is an in-memory GCRAStore, for a single instance.
+type MemoryGCRAStore struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+	sets int
+}
+
+// NewMemoryGCRAStore creates an empty MemoryGCRAStore.
+func NewMemoryGCRAStore() *MemoryGCRAStore {
+	return &MemoryGCRAStore{tats: make(map[string]time.Time)}
+}
+
+// Get implements GCRAStore.
+func (s *MemoryGCRAStore) Get(_ context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tats[key], nil
+}
+
+// CompareAndSet implements GCRAStore. The expired keys are pruned from time to time, since a key whose TAT has
+// passed has the same effect as an unknown key.
+func (s *MemoryGCRAStore) CompareAndSet(_ context.Context, key string, old, tat time.Time, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.tats[key].Equal(old) {
+		return false, nil
+	}
+	s.tats[key] = tat
+
+	s.sets++
+	if s.sets%1024 == 0 {
+		now := tat.Add(-ttl) // the clock of the caller.
+		for k, t := range s.tats {
+			if t.Before(now) {
+				delete(s.tats, k)
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,136 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGCRALimit(t *testing.T) {
+	clock := newFakeClock()
+	h := GCRALimitWithConfig(GCRAConfig{
+		Rate:  time.Second,
+		Burst: 3,
+		Key:   func(r *http.Request) string { return r.Header.Get("X-Client") },
+		Clock: clock,
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	serve := func(client string) (string, error) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Client", client)
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, req)
+		return res.Header().Get("Retry-After"), err
+	}
+
+	// the burst is allowed at once.
+	for i := 0; i < 3; i++ {
+		_, err := serve("a")
+		expectTrue(t, err == nil)
+	}
+	retryAfter, err := serve("a")
+	expectHTTPError(t, err, http.StatusTooManyRequests)
+	expectTrue(t, retryAfter == "1")
+
+	// other keys are independent.
+	_, err = serve("b")
+	expectTrue(t, err == nil)
+
+	// the capacity leaks at one request per second.
+	clock.Advance(500 * time.Millisecond)
+	_, err = serve("a")
+	expectHTTPError(t, err, http.StatusTooManyRequests)
+
+	clock.Advance(500 * time.Millisecond)
+	_, err = serve("a")
+	expectTrue(t, err == nil)
+	_, err = serve("a")
+	expectHTTPError(t, err, http.StatusTooManyRequests)
+
+	// a steady rate is always allowed.
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		_, err = serve("a")
+		expectTrue(t, err == nil)
+	}
+
+	// after a long idle period, the full burst is available again, but not more.
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		_, err := serve("a")
+		expectTrue(t, err == nil)
+	}
+	retryAfter, err = serve("a")
+	expectHTTPError(t, err, http.StatusTooManyRequests)
+	expectTrue(t, retryAfter == "1")
+}
+
+func TestGCRALimit_Headers(t *testing.T) {
+	clock := newFakeClock()
+	h := GCRALimitWithConfig(GCRAConfig{
+		Rate:  time.Second,
+		Burst: 3,
+		Key:   func(r *http.Request) string { return "k" },
+		Clock: clock,
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	// headers returns limit/remaining/reset/retry-after.
+	headers := func() string {
+		res := httptest.NewRecorder()
+		_ = h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		return res.Header().Get("X-RateLimit-Limit") + "/" +
+			res.Header().Get("X-RateLimit-Remaining") + "/" +
+			res.Header().Get("X-RateLimit-Reset") + "/" +
+			res.Header().Get("Retry-After")
+	}
+
+	expectTrue(t, headers() == "3/2/1/")
+	expectTrue(t, headers() == "3/1/2/")
+	expectTrue(t, headers() == "3/0/3/")
+	expectTrue(t, headers() == "3/0/3/1")
+
+	clock.Advance(500 * time.Millisecond)
+	expectTrue(t, headers() == "3/0/3/1")
+
+	clock.Advance(500 * time.Millisecond)
+	expectTrue(t, headers() == "3/0/3/")
+
+	clock.Advance(2500 * time.Millisecond)
+	expectTrue(t, headers() == "3/1/2/")
+}
+
+type failingGCRAStore struct{ err error }
+
+func (s failingGCRAStore) Get(context.Context, string) (time.Time, error) { return time.Time{}, s.err }
+func (s failingGCRAStore) CompareAndSet(context.Context, string, time.Time, time.Time, time.Duration) (bool, error) {
+	return false, s.err
+}
+
+func TestGCRALimit_StoreError(t *testing.T) {
+	anError := errors.New("store is down")
+	h := GCRALimitWithConfig(GCRAConfig{
+		Rate:  time.Second,
+		Key:   func(r *http.Request) string { return "k" },
+		Store: failingGCRAStore{err: anError},
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, errors.Is(err, anError))
+}
+
+func TestGCRALimit_InvalidConfig(t *testing.T) {
+	key := func(r *http.Request) string { return "" }
+	for _, cfg := range []GCRAConfig{
+		{Rate: 0, Key: key},
+		{Rate: -time.Second, Key: key},
+		{Rate: time.Second},
+	} {
+		func() {
+			defer func() { expectTrue(t, recover() != nil) }()
+			GCRALimitWithConfig(cfg)
+		}()
+	}
+}
@@ -0,0 +1,88 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// ResponseSummary describes a request once its response has been written, see Options.OnResponse.
+type ResponseSummary struct {
+	Method string
+	Path   string
+
+	// Route is the route that matched the request. It is the zero value for the requests answered by the
+	// NotFound, MethodNotAllowed or automatic OPTIONS handlers.
+	Route RouteInfo
+
+	// Status is the status sent to the client, and Bytes the number of body bytes.
+	Status int
+	Bytes  int64
+
+	// Duration is the time spent serving the request, including the LastResortErrorHandler.
+	Duration time.Duration
+
+	// Err is the error returned by the route handler, if any, or a *PanicError if it panicked.
+	Err error
+}
+
+// OnResponse registers a hook that is called with the summary of every request served by the ServeMux, once the
+// response has been written: matched routes, including the ones that fail or panic, and the NotFound and
+// MethodNotAllowed responses. Unlike a middleware, a hook cannot alter the response, which makes it a good place
+// for after-the-fact processing, such as usage metering or analytics. Hooks run in registration order.
+//
+// Hooks run synchronously before ServeHTTP returns, so slow work should be handed off to another goroutine.
+// With hooks registered, the handlers get a wrapped http.ResponseWriter, which supports http.Flusher,
+// http.Hijacker and http.ResponseController.
+func (nsOpts) OnResponse(hook func(ResponseSummary)) Option {
+	return func(mux *ServeMux) { mux.onResponse = append(mux.onResponse, hook) }
+}
+
+// summaryState collects the parts of the ResponseSummary known by the route handler.
+type summaryState struct {
+	mux   *ServeMux // the mux whose hooks get the summary, so nested muxes leave it alone.
+	route RouteInfo
+	err   error
+}
+
+// serveWithHooks serves the request with the underlying router, then calls the OnResponse hooks.
+func (mux *ServeMux) serveWithHooks(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	state := &summaryState{mux: mux}
+	sw := newStatusWriter(w)
+	mux.core.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), summaryKey, state)))
+
+	summary := ResponseSummary{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Route:    state.route,
+		Status:   sw.statusCode(),
+		Bytes:    sw.bytes,
+		Duration: time.Since(start),
+		Err:      state.err,
+	}
+	for _, hook := range mux.onResponse {
+		hook(summary)
+	}
+}
+
+// summaryState gets the summaryState of the request, or nil if the mux has no OnResponse hooks. The state of
+// another mux, e.g. of an outer mux serving this one, is ignored.
+func (mux *ServeMux) summaryState(r *http.Request) *summaryState {
+	state, _ := r.Context().Value(summaryKey).(*summaryState)
+	if state == nil || state.mux != mux {
+		return nil
+	}
+	return state
+}
+
+// recordPanics wraps the panic handler to record the panic for the OnResponse hooks.
+func (mux *ServeMux) recordPanics(handler func(http.ResponseWriter, *http.Request, any)) func(http.ResponseWriter, *http.Request, any) {
+	return func(w http.ResponseWriter, r *http.Request, v any) {
+		if state := mux.summaryState(r); state != nil {
+			state.err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+		handler(w, r, v)
+	}
+}
@@ -0,0 +1,100 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOptions_OnResponse(t *testing.T) {
+	var summaries []ResponseSummary
+	var order []string
+	mux := NewServeMux(
+		Options.OnResponse(func(s ResponseSummary) {
+			order = append(order, "first")
+			summaries = append(summaries, s)
+		}),
+		Options.OnResponse(func(s ResponseSummary) { order = append(order, "second") }),
+	)
+	mux.Route(Route{Method: "GET", Path: "/users/:id", Name: "users.get", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("gopher"))
+		return nil
+	}})
+	mux.Route(Route{Method: "POST", Path: "/users", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		return NewHTTPError(http.StatusConflict, "exists")
+	}})
+	mux.Route(Route{Method: "DELETE", Path: "/users/:id", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}})
+
+	serve := func(method, target string) ResponseSummary {
+		summaries = nil
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+		expectTrue(t, len(summaries) == 1)
+		return summaries[0]
+	}
+
+	s := serve("GET", "/users/1")
+	expectTrue(t, s.Method == "GET")
+	expectTrue(t, s.Path == "/users/1")
+	expectTrue(t, s.Route.Name == "users.get")
+	expectTrue(t, s.Route.Path == "/users/:id")
+	expectTrue(t, s.Status == 200)
+	expectTrue(t, s.Bytes == 6)
+	expectTrue(t, s.Duration > 0)
+	expectTrue(t, s.Err == nil)
+	expectTrue(t, len(order) == 2 && order[0] == "first" && order[1] == "second")
+
+	s = serve("POST", "/users")
+	expectTrue(t, s.Status == http.StatusConflict)
+	expectHTTPError(t, s.Err, http.StatusConflict)
+	expectTrue(t, s.Bytes > 0)
+
+	s = serve("DELETE", "/users/1")
+	expectTrue(t, s.Status == http.StatusInternalServerError)
+	expectTrue(t, s.Route.Path == "/users/:id")
+	var panicErr *PanicError
+	expectTrue(t, errors.As(s.Err, &panicErr))
+	expectTrue(t, panicErr.Value == "boom")
+
+	s = serve("GET", "/missing")
+	expectTrue(t, s.Status == http.StatusNotFound)
+	expectTrue(t, s.Route.Path == "")
+	expectTrue(t, s.Err == nil)
+
+	s = serve("PUT", "/users")
+	expectTrue(t, s.Status == http.StatusMethodNotAllowed)
+}
+
+func TestOptions_OnResponse_NestedMux(t *testing.T) {
+	inner := NewServeMux()
+	inner.GET("/a/:id", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("inner failure")
+	})
+	mounted := NewServeMux()
+	mounted.GET("/b", func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	var summaries []ResponseSummary
+	outer := NewServeMux(Options.OnResponse(func(s ResponseSummary) { summaries = append(summaries, s) }))
+	outer.POST("/batch", Batch(inner))
+	outer.GET("/mounted/*path", func(w http.ResponseWriter, r *http.Request) error {
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/b"
+		mounted.ServeHTTP(w, r2)
+		return nil
+	})
+
+	// the concurrent sub-requests do not touch the summary of the outer mux.
+	body := `[{"method":"GET","path":"/a/1"},{"method":"GET","path":"/a/2"},{"method":"GET","path":"/a/3"}]`
+	res := outer.TestRequest("POST", "/batch", strings.NewReader(body))
+	expectTrue(t, res.Code == http.StatusOK)
+	expectTrue(t, len(summaries) == 1)
+	expectTrue(t, summaries[0].Route.Path == "/batch")
+	expectTrue(t, summaries[0].Err == nil)
+
+	outer.TestRequest("GET", "/mounted/b", nil)
+	expectTrue(t, len(summaries) == 2)
+	expectTrue(t, summaries[1].Route.Path == "/mounted/*path")
+}
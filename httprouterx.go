@@ -1,8 +1,12 @@
 package httprouterx
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -45,6 +49,12 @@ func PathParams(r *http.Request) Params {
 	return httprouter.ParamsFromContext(r.Context())
 }
 
+// WithPathParams returns a copy of r that carries the path params, as if it was dispatched by the ServeMux. It is
+// meant to unit test a single handler in isolation, without registering it to a ServeMux.
+func WithPathParams(r *http.Request, params ...Param) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), httprouter.ParamsKey, Params(params)))
+}
+
 // Handler is modified version of http.Handler.
 type Handler interface {
 	// ServeHTTP is just like http.Handler.ServeHTTP, but it returns an error.
@@ -67,6 +77,45 @@ type Route struct {
 	Method  string
 	Path    string
 	Handler HandlerFunc
+
+	// Name and Tags are optional metadata, available to middlewares and handlers via CurrentRoute.
+	// The Name also identifies the route to build its URL with ServeMux.URL, so routes with different paths must
+	// not share a name.
+	Name string
+	Tags []string
+
+	// Requires is the optional list of the dependencies the route needs, see DependencyGuardWithConfig.
+	Requires []string
+
+	// MaxBodyBytes is the optional limit of the request body, enforced by the MaxBodyBytes middleware. Zero means
+	// the default limit of the middleware, and a negative value means no limit.
+	MaxBodyBytes int64
+}
+
+// RouteInfo is the metadata of the route that matched the current request.
+type RouteInfo struct {
+	Method   string
+	Path     string
+	Name     string
+	Tags     []string
+	Requires []string
+
+	MaxBodyBytes int64
+}
+
+// CurrentRoute gets the metadata of the route that matched the request.
+// It returns false if the request was not dispatched by the ServeMux.
+func CurrentRoute(r *http.Request) (RouteInfo, bool) {
+	info, ok := r.Context().Value(routeInfoKey).(RouteInfo)
+	return info, ok
+}
+
+// MatchedPath gets the registered pattern of the route that matched the request, e.g. /users/:id rather than
+// /users/123, which makes a low-cardinality label for metrics. It returns an empty string if the request was not
+// dispatched by the ServeMux, e.g. in the NotFound handler or in a NetMiddleware.
+func MatchedPath(r *http.Request) string {
+	info, _ := CurrentRoute(r)
+	return info.Path
 }
 
 // ServeMux is a wrapper of httprouter.Router with modified Handler.
@@ -83,6 +132,27 @@ type ServeMux struct {
 	conf *Config
 	midl Middleware
 
+	// routes keeps the metadata of all registered routes, in registration order.
+	routes []RouteInfo
+
+	// names maps the route names to their path, see URL.
+	names map[string]string
+
+	// chains are the handlers of all registered routes, wrapped by the global middleware.
+	chains []*routeChain
+
+	// debugRoutes enables the routes registered with DebugRoute.
+	debugRoutes bool
+
+	// onResponse are the hooks called after each response, see Options.OnResponse.
+	onResponse []func(ResponseSummary)
+
+	// server is the server used by ListenAndServe, see Options.Server.
+	server *http.Server
+
+	// stripPrefix is the prefix removed from the paths before routing, see Options.StripPrefix.
+	stripPrefix string
+
 	// lastResortErrorHandler is the error handler that is called if after all middlewares,
 	// there is still an error occurs. This handler is used to catch errors that are not handled by the middlewares.
 	//
@@ -116,14 +186,109 @@ func NewServeMux(opts ...Option) *ServeMux {
 		MethodNotAllowed:       mux.conf.MethodNotAllowed,
 		PanicHandler:           mux.conf.PanicHandler,
 	}
+	if len(mux.onResponse) > 0 {
+		mux.core.PanicHandler = mux.recordPanics(mux.core.PanicHandler)
+	}
 	return &mux
 }
 
+// Use appends the middlewares to the global middleware set by Options.Middleware, inside the existing ones: the
+// middlewares of the first call of Use run before the ones of the next calls, and all of them run before the
+// route-specific middlewares. The routes registered before calling Use get the middlewares too: their handlers
+// are wrapped again, so the middlewares are built once per route, and keep their state across requests.
+//
+// Use must be called while setting the mux up, it must not be called concurrently with ServeHTTP.
+func (mux *ServeMux) Use(mid ...Middleware) {
+	mux.midl = foldMiddlewares(append([]Middleware{mux.midl}, mid...))
+	for _, c := range mux.chains {
+		c.wrapped = mux.midl.Then(c.handler)
+	}
+}
+
+// routeChain is the handler of a route, and the same handler wrapped by the global middleware.
+type routeChain struct {
+	handler Handler
+	wrapped Handler
+}
+
 // Route is a syntactic sugar for Handle(method, path, handler) by using Route struct.
 // This route also accepts variadic Middleware, which is applied to the route handler.
 func (mux *ServeMux) Route(r Route, mid ...Middleware) {
 	chain := foldMiddlewares(mid)
-	mux.HandleFunc(r.Method, r.Path, chain.Then(r.Handler).ServeHTTP)
+	info := RouteInfo{
+		Method:   r.Method,
+		Path:     r.Path,
+		Name:     r.Name,
+		Tags:     append([]string(nil), r.Tags...),
+		Requires: append([]string(nil), r.Requires...),
+
+		MaxBodyBytes: r.MaxBodyBytes,
+	}
+	mux.handle(info, chain.Then(r.Handler))
+}
+
+// GET is a shortcut for Route with the GET method.
+func (mux *ServeMux) GET(path string, h HandlerFunc, mid ...Middleware) {
+	mux.Route(Route{Method: http.MethodGet, Path: path, Handler: h}, mid...)
+}
+
+// POST is a shortcut for Route with the POST method.
+func (mux *ServeMux) POST(path string, h HandlerFunc, mid ...Middleware) {
+	mux.Route(Route{Method: http.MethodPost, Path: path, Handler: h}, mid...)
+}
+
+// PUT is a shortcut for Route with the PUT method.
+func (mux *ServeMux) PUT(path string, h HandlerFunc, mid ...Middleware) {
+	mux.Route(Route{Method: http.MethodPut, Path: path, Handler: h}, mid...)
+}
+
+// PATCH is a shortcut for Route with the PATCH method.
+func (mux *ServeMux) PATCH(path string, h HandlerFunc, mid ...Middleware) {
+	mux.Route(Route{Method: http.MethodPatch, Path: path, Handler: h}, mid...)
+}
+
+// DELETE is a shortcut for Route with the DELETE method.
+func (mux *ServeMux) DELETE(path string, h HandlerFunc, mid ...Middleware) {
+	mux.Route(Route{Method: http.MethodDelete, Path: path, Handler: h}, mid...)
+}
+
+// HEAD is a shortcut for Route with the HEAD method.
+func (mux *ServeMux) HEAD(path string, h HandlerFunc, mid ...Middleware) {
+	mux.Route(Route{Method: http.MethodHead, Path: path, Handler: h}, mid...)
+}
+
+// OPTIONS is a shortcut for Route with the OPTIONS method.
+func (mux *ServeMux) OPTIONS(path string, h HandlerFunc, mid ...Middleware) {
+	mux.Route(Route{Method: http.MethodOptions, Path: path, Handler: h}, mid...)
+}
+
+// Match registers the handler for each of the methods on the path, e.g. for GET and HEAD, with the same
+// middlewares. It panics if methods is empty or has duplicates, so registration mistakes surface at startup.
+func (mux *ServeMux) Match(methods []string, path string, handler Handler, mid ...Middleware) {
+	if len(methods) == 0 {
+		panic("httprouterx: Match: no methods for path " + path)
+	}
+	seen := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		if seen[method] {
+			panic("httprouterx: Match: duplicate method " + method + " for path " + path)
+		}
+		seen[method] = true
+	}
+
+	for _, method := range methods {
+		mux.Route(Route{Method: method, Path: path, Handler: handler.ServeHTTP}, mid...)
+	}
+}
+
+// DebugRoute is like Route, but the route is only registered if debug routes are enabled with
+// Options.DebugRoutes, otherwise it is a no-op and the path answers with the normal 404 (or 405 if other
+// methods are registered for it). This keeps debug and admin endpoints in the codebase without the risk
+// of exposing them in production.
+func (mux *ServeMux) DebugRoute(r Route, mid ...Middleware) {
+	if mux.debugRoutes {
+		mux.Route(r, mid...)
+	}
 }
 
 // HandleFunc just like Handle, but it accepts HandlerFunc.
@@ -133,16 +298,72 @@ func (mux *ServeMux) HandleFunc(method, path string, handler HandlerFunc) {
 
 // Handle registers a new request handler with the given method and path.
 func (mux *ServeMux) Handle(method, path string, handler Handler) {
-	mux.core.HandlerFunc(method, path, func(w http.ResponseWriter, r *http.Request) {
-		err := mux.midl.Then(handler).ServeHTTP(w, r)
+	mux.handle(RouteInfo{Method: method, Path: path}, handler)
+}
+
+// ErrInvalidRoute is the error returned by TryHandle when the route cannot be registered.
+var ErrInvalidRoute = errors.New("invalid route")
+
+// TryHandle is like Handle, but it returns an error wrapping ErrInvalidRoute instead of panicking when the
+// underlying router rejects the route, e.g. because the path is invalid or conflicts with a registered one. This
+// lets routes loaded from a configuration be reported gracefully. The mux is left unchanged on error.
+func (mux *ServeMux) TryHandle(method, path string, handler Handler) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%w: %s %s: %v", ErrInvalidRoute, method, path, v)
+		}
+	}()
+	mux.Handle(method, path, handler)
+	return nil
+}
+
+// handle registers the handler to the underlying router and makes the route metadata available
+// in the request context.
+func (mux *ServeMux) handle(info RouteInfo, handler Handler) {
+	if path, ok := mux.names[info.Name]; ok && path != info.Path {
+		panic(fmt.Sprintf("httprouterx: route name %q is already used by %s", info.Name, path))
+	}
+	chain := &routeChain{handler: handler, wrapped: mux.midl.Then(handler)}
+	mux.core.HandlerFunc(info.Method, info.Path, func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), routeInfoKey, info))
+		state := mux.summaryState(r)
+		if state != nil {
+			state.route = info
+		}
+		err := chain.wrapped.ServeHTTP(w, r)
+		if state != nil {
+			state.err = err
+		}
 		if err != nil {
 			mux.lastResortErrorHandler(w, r, err)
 		}
 	})
+	// after the registration, which panics on invalid routes.
+	mux.routes = append(mux.routes, info)
+	mux.chains = append(mux.chains, chain)
+	if info.Name != "" {
+		if mux.names == nil {
+			mux.names = make(map[string]string)
+		}
+		mux.names[info.Name] = info.Path
+	}
 }
 
 // ServeHTTP satisfies http.Handler.
-func (mux *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) { mux.core.ServeHTTP(w, r) }
+func (mux *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if mux.stripPrefix != "" {
+		var ok bool
+		if w, r, ok = mux.stripRequest(w, r); !ok {
+			mux.conf.NotFound.ServeHTTP(w, r)
+			return
+		}
+	}
+	if len(mux.onResponse) > 0 {
+		mux.serveWithHooks(w, r)
+		return
+	}
+	mux.core.ServeHTTP(w, r)
+}
 
 // Config is the configuration for the underlying httprouter.Router.
 type Config struct {
@@ -315,6 +536,12 @@ func (nsOpts) Middleware(m Middleware) Option {
 	return func(mux *ServeMux) { mux.midl = m }
 }
 
+// DebugRoutes enables/disables the routes registered with ServeMux.DebugRoute. Default disabled.
+// It is meant to be set at startup from the environment, e.g. Options.DebugRoutes(os.Getenv("APP_ENV") != "production").
+func (nsOpts) DebugRoutes(enabled bool) Option {
+	return func(mux *ServeMux) { mux.debugRoutes = enabled }
+}
+
 // nsDefaultHandlers is an internal type for grouping default handlers.
 type nsDefaultHandlers int
 
@@ -322,9 +549,44 @@ type nsDefaultHandlers int
 const DefaultHandlers nsDefaultHandlers = 0
 
 // LastResortError is the default last resort error handler.
+// If the error is an *HTTPError, its Status and Message are used, otherwise it responds with 500.
+// An *HTTPError with a Code, such as the errors of the request parsing helpers, is rendered as a JSON object
+// with the code and the message, e.g. {"error":"malformed_body","message":"request body contains malformed JSON"}.
 func (nsDefaultHandlers) LastResortError(w http.ResponseWriter, r *http.Request, err error) {
-	w.WriteHeader(http.StatusInternalServerError)
-	_, _ = fmt.Fprintf(w, "default last resort error handler: method: %s, path: %s, error: %v", r.Method, r.URL.Path, err)
+	status, msg := http.StatusInternalServerError, err.Error()
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		status, msg = httpErr.Status, httpErr.Message
+		if httpErr.Code != "" {
+			_ = WriteJSON(w, status, map[string]string{"error": httpErr.Code, "message": msg})
+			return
+		}
+	}
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "default last resort error handler: method: %s, path: %s, error: %s", r.Method, r.URL.Path, msg)
+}
+
+// LastResortErrorJSON is like LastResortError, but it renders every error as a JSON error envelope, consistent
+// with NotFoundJSON, e.g. {"error":"conflict","message":"user already exists"}. The error is the Code of the
+// *HTTPError, or its status text in snake case. Errors that are not an *HTTPError are rendered as a 500 with a
+// generic message, so their text, which may hold internal details, is not leaked to the client; it is logged
+// with slog instead.
+//
+// It is opted into with Options.LastResortErrorHandler(DefaultHandlers.LastResortErrorJSON).
+func (nsDefaultHandlers) LastResortErrorJSON(w http.ResponseWriter, r *http.Request, err error) {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		slog.ErrorContext(r.Context(), "unhandled error", "method", r.Method, "path", r.URL.Path, "error", err)
+		httpErr = NewHTTPError(http.StatusInternalServerError, "")
+	}
+	code := httpErr.Code
+	if code == "" {
+		code = strings.ReplaceAll(strings.ToLower(http.StatusText(httpErr.Status)), " ", "_")
+	}
+	if code == "" {
+		code = "error" // non-standard status.
+	}
+	_ = WriteJSON(w, httpErr.Status, map[string]string{"error": code, "message": httpErr.Message})
 }
 
 // NotFound is the default not found handler.
@@ -343,6 +605,32 @@ func (nsDefaultHandlers) MethodNotAllowed() http.HandlerFunc {
 	}
 }
 
+// NotFoundJSON is like NotFound, but it writes a JSON error envelope, consistent with the JSON rendering of the
+// errors with a Code by LastResortError, e.g. {"error":"not_found","message":"Not Found","path":"/users/42"}.
+func (nsDefaultHandlers) NotFoundJSON() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = WriteJSON(w, http.StatusNotFound, map[string]string{
+			"error":   "not_found",
+			"message": http.StatusText(http.StatusNotFound),
+			"path":    r.URL.Path,
+		})
+	}
+}
+
+// MethodNotAllowedJSON is like MethodNotAllowed, but it writes a JSON error envelope, consistent with NotFoundJSON,
+// e.g. {"error":"method_not_allowed","message":"Method Not Allowed","method":"DELETE","path":"/users/42"}. The
+// Allow header is set by the router.
+func (nsDefaultHandlers) MethodNotAllowedJSON() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = WriteJSON(w, http.StatusMethodNotAllowed, map[string]string{
+			"error":   "method_not_allowed",
+			"message": http.StatusText(http.StatusMethodNotAllowed),
+			"method":  r.Method,
+			"path":    r.URL.Path,
+		})
+	}
+}
+
 // Panic is the default panic handler.
 func (nsDefaultHandlers) Panic(w http.ResponseWriter, r *http.Request, v any) {
 	w.WriteHeader(http.StatusInternalServerError)
@@ -1,8 +1,11 @@
 package httprouterx
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -50,6 +53,15 @@ func TestNsDefaultHandlers_LastResortError(t *testing.T) {
 	expectTrue(t, res.Code == 500)
 }
 
+func TestNsDefaultHandlers_LastResortErrorWithHTTPError(t *testing.T) {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	err := fmt.Errorf("wrapped: %w", NewHTTPError(409, "conflict"))
+	DefaultHandlers.LastResortError(res, req, err)
+	expectTrue(t, res.Code == 409)
+	expectTrue(t, strings.HasSuffix(res.Body.String(), "error: conflict"))
+}
+
 func TestNsDefaultHandlers_MethodNotAllowed(t *testing.T) {
 	res := httptest.NewRecorder()
 	req := httptest.NewRequest("GET", "/", nil)
@@ -64,6 +76,57 @@ func TestNsDefaultHandlers_NotFound(t *testing.T) {
 	expectTrue(t, res.Code == 404)
 }
 
+func TestNsDefaultHandlers_JSON(t *testing.T) {
+	mux := NewServeMux(
+		Options.NotFoundHandler(DefaultHandlers.NotFoundJSON()),
+		Options.MethodNotAllowedHandler(DefaultHandlers.MethodNotAllowedJSON()),
+	)
+	mux.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	res := mux.TestRequest("GET", "/missing", nil)
+	expectTrue(t, res.Code == 404)
+	expectTrue(t, res.Header().Get("Content-Type") == "application/json")
+	expectTrue(t, res.Body.String() == `{"error":"not_found","message":"Not Found","path":"/missing"}`)
+
+	res = mux.TestRequest("DELETE", "/users/42", nil)
+	expectTrue(t, res.Code == 405)
+	expectTrue(t, res.Header().Get("Content-Type") == "application/json")
+	expectTrue(t, strings.Contains(res.Header().Get("Allow"), "GET"))
+	expectTrue(t, res.Body.String() == `{"error":"method_not_allowed","message":"Method Not Allowed","method":"DELETE","path":"/users/42"}`)
+}
+
+func TestNsDefaultHandlers_LastResortErrorJSON(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	mux := NewServeMux(Options.LastResortErrorHandler(DefaultHandlers.LastResortErrorJSON))
+	mux.GET("/conflict", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("creating user: %w", NewHTTPError(http.StatusConflict, "user already exists"))
+	})
+	mux.GET("/malformed", func(w http.ResponseWriter, r *http.Request) error {
+		return ErrMalformedBody.withCause("request body contains malformed JSON", nil)
+	})
+	mux.GET("/fail", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("dial tcp 10.0.0.3:5432: connection refused")
+	})
+
+	res := mux.TestRequest("GET", "/conflict", nil)
+	expectTrue(t, res.Code == http.StatusConflict)
+	expectTrue(t, res.Header().Get("Content-Type") == "application/json")
+	expectTrue(t, res.Body.String() == `{"error":"conflict","message":"user already exists"}`)
+
+	res = mux.TestRequest("GET", "/malformed", nil)
+	expectTrue(t, res.Code == http.StatusBadRequest)
+	expectTrue(t, res.Body.String() == `{"error":"malformed_body","message":"request body contains malformed JSON"}`)
+
+	res = mux.TestRequest("GET", "/fail", nil)
+	expectTrue(t, res.Code == http.StatusInternalServerError)
+	expectTrue(t, res.Body.String() == `{"error":"internal_server_error","message":"Internal Server Error"}`)
+	expectTrue(t, strings.Contains(logs.String(), "connection refused"))
+	expectTrue(t, strings.Contains(logs.String(), "path=/fail"))
+}
+
 func TestNsDefaultHandlers_PanicHandler(t *testing.T) {
 	res := httptest.NewRecorder()
 	req := httptest.NewRequest("GET", "/", nil)
@@ -173,6 +236,51 @@ func TestServeMux_RouteWithPathParams(t *testing.T) {
 	expectTrue(t, visited)
 }
 
+func TestServeMux_RouteWithRouteInfo(t *testing.T) {
+	var info RouteInfo
+	mux := NewServeMux()
+	mux.Route(Route{
+		Method: "GET",
+		Path:   "/data/:id",
+		Name:   "data.get",
+		Tags:   []string{"data"},
+		Handler: func(w http.ResponseWriter, r *http.Request) error {
+			info, _ = CurrentRoute(r)
+			return nil
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/data/123", nil)
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+	expectTrue(t, info.Method == "GET")
+	expectTrue(t, info.Path == "/data/:id")
+	expectTrue(t, info.Name == "data.get")
+	expectTrue(t, len(info.Tags) == 1 && info.Tags[0] == "data")
+
+	_, ok := CurrentRoute(httptest.NewRequest("GET", "/", nil))
+	expectFalse(t, ok)
+}
+
+func TestMatchedPath(t *testing.T) {
+	var matched string
+	mux := NewServeMux(Options.NotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		matched = "notfound:" + MatchedPath(r)
+	})))
+	mux.GET("/users/:id/files/*path", func(w http.ResponseWriter, r *http.Request) error {
+		matched = MatchedPath(r)
+		return nil
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/123/files/a/b.txt", nil))
+	expectTrue(t, matched == "/users/:id/files/*path")
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unknown", nil))
+	expectTrue(t, matched == "notfound:")
+
+	expectTrue(t, MatchedPath(httptest.NewRequest("GET", "/users/123", nil)) == "")
+}
+
 func TestServeMux_RouteWithMiddleware(t *testing.T) {
 	mid := Middleware(func(next Handler) Handler {
 		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
@@ -301,6 +409,142 @@ func TestServeMux_RouteWithRouteSpecificMiddleware(t *testing.T) {
 	})
 }
 
+func TestServeMux_Use(t *testing.T) {
+	mux := NewServeMux(Options.Middleware(fakeMiddleware("global", "-start", "global-end")))
+	mux.Route(Route{Method: "GET", Path: "/early", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}}, fakeMiddleware("route", "-start", "route-end"))
+
+	mux.Use(fakeMiddleware("m1", "-start", "m1-end"), fakeMiddleware("m2", "-start", "m2-end"))
+	mux.Use(fakeMiddleware("m3", "-start", "m3-end"))
+	mux.GET("/late", func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/early", nil))
+	expectTrue(t, strings.Join(res.Header().Values("X-Middleware"), ",") ==
+		"global-start,m1-start,m2-start,m3-start,route-start,route-end,m3-end,m2-end,m1-end,global-end")
+
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/late", nil))
+	expectTrue(t, strings.Join(res.Header().Values("X-Middleware"), ",") ==
+		"global-start,m1-start,m2-start,m3-start,m3-end,m2-end,m1-end,global-end")
+
+	// without a global middleware.
+	mux = NewServeMux()
+	mux.Use(fakeMiddleware("m1", "-start", "m1-end"))
+	mux.GET("/", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, strings.Join(res.Header().Values("X-Middleware"), ",") == "m1-start,m1-end")
+}
+
+func TestServeMux_MethodShortcuts(t *testing.T) {
+	mux := NewServeMux(Options.HandleOption(false))
+	echo := func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Add("X-Trace", "handler")
+		return nil
+	}
+	trace := func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("X-Trace", "mid")
+			return next.ServeHTTP(w, r)
+		})
+	}
+
+	mux.GET("/r", echo, trace)
+	mux.POST("/r", echo)
+	mux.PUT("/r", echo)
+	mux.PATCH("/r", echo)
+	mux.DELETE("/r", echo)
+	mux.HEAD("/r", echo)
+	mux.OPTIONS("/r", echo)
+
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"} {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest(method, "/r", nil))
+		expectTrue(t, res.Code == 200)
+		expectTrue(t, res.Header().Get("X-Method") == method)
+	}
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/r", nil))
+	expectTrue(t, strings.Join(res.Header().Values("X-Trace"), ",") == "mid,handler")
+}
+
+func TestServeMux_Match(t *testing.T) {
+	mux := NewServeMux()
+	var calls int
+	mux.Match([]string{"PUT", "PATCH"}, "/users/:id", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		calls++
+		w.Header().Set("X-Method", r.Method)
+		return nil
+	}))
+
+	for _, method := range []string{"PUT", "PATCH"} {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest(method, "/users/1", nil))
+		expectTrue(t, res.Code == 200)
+		expectTrue(t, res.Header().Get("X-Method") == method)
+	}
+	expectTrue(t, calls == 2)
+	expectTrue(t, len(mux.Routes()) == 2)
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/users/1", nil))
+	expectTrue(t, res.Code == 405)
+
+	for _, methods := range [][]string{nil, {"GET", "HEAD", "GET"}} {
+		func() {
+			defer func() { expectTrue(t, recover() != nil) }()
+			mux.Match(methods, "/other", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+		}()
+	}
+	expectTrue(t, len(mux.Routes()) == 2)
+}
+
+func TestServeMux_TryHandle(t *testing.T) {
+	mux := NewServeMux()
+	ok := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	expectTrue(t, mux.TryHandle("GET", "/users/:id", ok) == nil)
+
+	for _, path := range []string{"/users/:name", "users", "/users/:id"} {
+		err := mux.TryHandle("GET", path, ok)
+		expectTrue(t, errors.Is(err, ErrInvalidRoute))
+		expectTrue(t, strings.Contains(err.Error(), path))
+	}
+	expectTrue(t, len(mux.Routes()) == 1)
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/users/1", nil))
+	expectTrue(t, res.Code == 200)
+}
+
+func TestServeMux_DebugRoute(t *testing.T) {
+	route := Route{Method: "GET", Path: "/debug/vars", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(200)
+		return nil
+	}}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		mux := NewServeMux()
+		mux.DebugRoute(route)
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/debug/vars", nil))
+		expectTrue(t, res.Code == 404)
+		expectTrue(t, len(mux.Routes()) == 0)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		mux := NewServeMux(Options.DebugRoutes(true))
+		mux.DebugRoute(route)
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/debug/vars", nil))
+		expectTrue(t, res.Code == 200)
+	})
+}
+
 func expectTrue(t *testing.T, condition bool) {
 	t.Helper()
 	if !condition {
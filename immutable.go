@@ -0,0 +1,86 @@
+package httprouterx
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ImmutableCacheControl is the Cache-Control value set by ImmutableCache.
+const ImmutableCacheControl = "public, max-age=31536000, immutable"
+
+// ImmutableCache lets browsers cache content-addressed files, such as fingerprinted assets (app.abc123.js),
+// forever: the successful (2xx) responses to GET requests whose path matches pattern get the
+// ImmutableCacheControl Cache-Control header, unless the handler has set one. Other requests pass through.
+//
+// The pattern is a path.Match glob. If it contains a '/', it is matched against the whole path, e.g.
+// "/assets/*.js", otherwise against the last element of the path only, e.g. "*.[0-9a-f][0-9a-f][0-9a-f]*.js".
+// Use ImmutableCacheRegexp for patterns that globs cannot express. It panics if the pattern is malformed.
+func ImmutableCache(pattern string) Middleware {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic("httprouterx: ImmutableCache: " + err.Error())
+	}
+	return immutableCache(func(p string) bool {
+		if !strings.Contains(pattern, "/") {
+			p = path.Base(p)
+		}
+		ok, _ := path.Match(pattern, p)
+		return ok
+	})
+}
+
+// ImmutableCacheRegexp is like ImmutableCache, but the whole path is matched against a regular expression,
+// e.g. `\.[0-9a-f]{8,}\.(js|css)$`.
+func ImmutableCacheRegexp(re *regexp.Regexp) Middleware {
+	return immutableCache(re.MatchString)
+}
+
+func immutableCache(match func(path string) bool) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Method != http.MethodGet || !match(r.URL.Path) {
+				return next.ServeHTTP(w, r)
+			}
+			return next.ServeHTTP(&immutableWriter{ResponseWriter: w}, r)
+		})
+	}
+}
+
+// immutableWriter sets the Cache-Control header when a successful status is written.
+type immutableWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *immutableWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status >= 200 {
+		w.wroteHeader = true
+		if status <= 299 && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", ImmutableCacheControl)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *immutableWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher. It is a no-op if the underlying writer is not an http.Flusher.
+func (w *immutableWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *immutableWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,62 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestImmutableCache(t *testing.T) {
+	serveFile := func(w http.ResponseWriter, r *http.Request) error {
+		if PathParams(r).ByName("name") == "/missing.abc123.js" {
+			return NewHTTPError(http.StatusNotFound, "")
+		}
+		_, _ = w.Write([]byte("console.log(1)"))
+		return nil
+	}
+
+	tests := []struct {
+		name   string
+		mid    Middleware
+		method string
+		path   string
+		want   string
+	}{
+		{name: "base glob match", mid: ImmutableCache("*.[0-9a-f][0-9a-f][0-9a-f]*.js"), method: "GET", path: "/assets/js/app.abc123.js", want: ImmutableCacheControl},
+		{name: "base glob no match", mid: ImmutableCache("*.[0-9a-f][0-9a-f][0-9a-f]*.js"), method: "GET", path: "/assets/app.js"},
+		{name: "path glob match", mid: ImmutableCache("/assets/*.js"), method: "GET", path: "/assets/app.js", want: ImmutableCacheControl},
+		{name: "path glob no match", mid: ImmutableCache("/assets/*.js"), method: "GET", path: "/assets/js/app.js"},
+		{name: "regexp match", mid: ImmutableCacheRegexp(regexp.MustCompile(`\.[0-9a-f]{6,}\.js$`)), method: "GET", path: "/assets/app.abc123.js", want: ImmutableCacheControl},
+		{name: "not GET", mid: ImmutableCache("*.js"), method: "POST", path: "/assets/app.js"},
+		{name: "not successful", mid: ImmutableCache("*.js"), method: "GET", path: "/assets/missing.abc123.js"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := NewServeMux()
+			mux.Route(Route{Method: tt.method, Path: "/assets/*name", Handler: serveFile}, tt.mid)
+
+			res := httptest.NewRecorder()
+			mux.ServeHTTP(res, httptest.NewRequest(tt.method, tt.path, nil))
+			expectTrue(t, res.Header().Get("Cache-Control") == tt.want)
+		})
+	}
+}
+
+func TestImmutableCache_KeepsHandlerValue(t *testing.T) {
+	h := ImmutableCache("*.js").Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(200)
+		return nil
+	}))
+
+	res := httptest.NewRecorder()
+	_ = h.ServeHTTP(res, httptest.NewRequest("GET", "/app.js", nil))
+	expectTrue(t, res.Header().Get("Cache-Control") == "no-store")
+}
+
+func TestImmutableCache_BadPattern(t *testing.T) {
+	defer func() { expectTrue(t, recover() != nil) }()
+	ImmutableCache("[")
+}
@@ -0,0 +1,42 @@
+package httprouterx
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// InFlightByRoute tracks the number of in-flight requests per matched route path (e.g. /users/:id), which
+// reveals the saturated endpoints. The returned function takes a snapshot of the counters, including the routes
+// with no request in flight, e.g. to expose them on a debug endpoint.
+//
+// Counters are decremented when the handler returns or panics. Requests that are not dispatched by the ServeMux
+// (see CurrentRoute) are not tracked.
+func InFlightByRoute() (Middleware, func() map[string]int) {
+	var counters sync.Map // route path -> *atomic.Int64
+
+	mid := func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			route, ok := CurrentRoute(r)
+			if !ok {
+				return next.ServeHTTP(w, r)
+			}
+
+			c, _ := counters.LoadOrStore(route.Path, new(atomic.Int64))
+			counter := c.(*atomic.Int64)
+			counter.Add(1)
+			defer counter.Add(-1)
+			return next.ServeHTTP(w, r)
+		})
+	}
+
+	snapshot := func() map[string]int {
+		m := make(map[string]int)
+		counters.Range(func(k, v any) bool {
+			m[k.(string)] = int(v.(*atomic.Int64).Load())
+			return true
+		})
+		return m
+	}
+	return mid, snapshot
+}
@@ -0,0 +1,48 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestInFlightByRoute(t *testing.T) {
+	mid, snapshot := InFlightByRoute()
+	mux := NewServeMux(Options.Middleware(mid))
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	mux.Route(Route{Method: "GET", Path: "/slow/:id", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		entered <- struct{}{}
+		<-release
+		return nil
+	}})
+	mux.Route(Route{Method: "GET", Path: "/panic", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}})
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"1", "2", "3"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow/"+id, nil))
+		}(id)
+	}
+	for i := 0; i < 3; i++ {
+		<-entered
+	}
+	expectTrue(t, snapshot()["/slow/:id"] == 3)
+
+	close(release)
+	wg.Wait()
+	expectTrue(t, snapshot()["/slow/:id"] == 0)
+
+	// the counter is released even if the handler panics.
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/panic", nil))
+	expectTrue(t, res.Code == 500)
+	expectTrue(t, snapshot()["/panic"] == 0)
+	expectTrue(t, len(snapshot()) == 2)
+}
@@ -0,0 +1,257 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxJSONBytes is the body limit used by the JSON decoding helpers when the given limit is not positive.
+const DefaultMaxJSONBytes = 1 << 20
+
+// DecodeJSON decodes the JSON body of the request into dst, reading at most maxBytes (DefaultMaxJSONBytes if
+// not positive). The body must contain a single JSON value.
+//
+// It returns an *HTTPError, so the error can be returned from the handler as is: ErrBodyTooLarge (413) if the
+// body is too large, ErrEmptyBody (400) if it is empty, or ErrMalformedBody (400) if it is malformed or does not
+// fit dst.
+//
+// When dst points to a struct, the fields tagged with `default:"..."` that are absent from the body are set to
+// their default, e.g. `json:"limit" default:"20"`, including in nested structs. Fields sent by the client are
+// kept, even when zero or null. Defaults use the same syntax as BindQuery.
+func DecodeJSON(r *http.Request, dst any, maxBytes int64) error {
+	return decodeJSON(r, dst, maxBytes, jsonDecodeOptions{})
+}
+
+// DecodeJSONNumbers is like DecodeJSON, but it decodes numbers with json.Decoder.UseNumber, so numbers stored in
+// interface values (e.g. map[string]any) are kept as json.Number instead of float64, which silently loses
+// precision for integers above 2^53, such as IDs and money amounts.
+//
+// It also rejects, with 400, any number in the body that cannot be represented without precision loss:
+// integers must fit in an int64, and other numbers must fit in a float64. See DecodeJSONNumbersWithConfig to
+// bound the magnitude of the numbers further.
+func DecodeJSONNumbers(r *http.Request, dst any, maxBytes int64) error {
+	return decodeJSON(r, dst, maxBytes, jsonDecodeOptions{numbers: true})
+}
+
+// JSONNumbersConfig is the configuration for DecodeJSONNumbersWithConfig.
+type JSONNumbersConfig struct {
+	// MaxBytes limits the body. Default DefaultMaxJSONBytes.
+	MaxBytes int64
+
+	// MaxAbs is the largest absolute value of the numbers of the body, e.g. the largest amount accepted by the
+	// application. Zero means no bound, besides the precision check of DecodeJSONNumbers.
+	MaxAbs float64
+}
+
+// DecodeJSONNumbersWithConfig is like DecodeJSONNumbers, but it also rejects, with 400, any number in the body
+// whose absolute value exceeds cfg.MaxAbs, wherever it is in the body. A number equal to MaxAbs is accepted.
+func DecodeJSONNumbersWithConfig(r *http.Request, dst any, cfg JSONNumbersConfig) error {
+	return decodeJSON(r, dst, cfg.MaxBytes, jsonDecodeOptions{numbers: true, maxAbs: cfg.MaxAbs})
+}
+
+// BindJSON is like DecodeJSON, but it first checks that the request declares a JSON body, with a Content-Type of
+// application/json or application/*+json, and rejects it with an ErrUnsupportedMediaType *HTTPError (415)
+// otherwise. Unknown fields are ignored, see BindJSONStrict to reject them.
+func BindJSON(r *http.Request, dst any, maxBytes int64) error {
+	return bindJSON(r, dst, maxBytes, jsonDecodeOptions{})
+}
+
+// BindJSONStrict is like BindJSON, but the body must not contain fields that do not exist in dst, which are
+// rejected with ErrMalformedBody (400), naming the field. This catches typos in client payloads that would
+// otherwise be silently dropped.
+func BindJSONStrict(r *http.Request, dst any, maxBytes int64) error {
+	return bindJSON(r, dst, maxBytes, jsonDecodeOptions{strict: true})
+}
+
+func bindJSON(r *http.Request, dst any, maxBytes int64, opts jsonDecodeOptions) error {
+	if !isJSONContentType(r.Header.Get("Content-Type")) {
+		return ErrUnsupportedMediaType.withCause("request body must be JSON", nil)
+	}
+	return decodeJSON(r, dst, maxBytes, opts)
+}
+
+// jsonDecodeOptions are the options of decodeJSON.
+type jsonDecodeOptions struct {
+	numbers bool    // decode the numbers as json.Number, and reject the ones that lose precision.
+	maxAbs  float64 // with numbers, reject the numbers of a larger absolute value, if positive.
+	strict  bool    // reject unknown fields.
+}
+
+func decodeJSON(r *http.Request, dst any, maxBytes int64, opts jsonDecodeOptions) error {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxJSONBytes
+	}
+	if r.Body == nil {
+		return ErrEmptyBody.withCause(ErrEmptyBody.Message, nil)
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBytes))
+	if err != nil {
+		var (
+			httpErr *HTTPError
+			maxErr  *http.MaxBytesError
+		)
+		switch {
+		case errors.As(err, &httpErr):
+			return err // e.g. a smaller limit enforced by MaxBodyBytes.
+		case errors.As(err, &maxErr):
+			return ErrBodyTooLarge.withCause(fmt.Sprintf("request body must not exceed %d bytes", maxBytes), err)
+		}
+		return err
+	}
+
+	if opts.numbers {
+		if err := checkJSONNumbers(body, opts.maxAbs); err != nil {
+			return err
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if opts.numbers {
+		dec.UseNumber()
+	}
+	if opts.strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(dst); err != nil {
+		return jsonDecodeError(err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return ErrMalformedBody.withCause("request body must contain a single JSON value", nil)
+	}
+	return applyJSONDefaults(dst, body)
+}
+
+// jsonDecodeError maps the decoding error to an ErrEmptyBody or ErrMalformedBody *HTTPError, with a message safe
+// for clients.
+func jsonDecodeError(err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		invalid   *json.InvalidUnmarshalError
+		msg       string
+	)
+	switch {
+	case errors.As(err, &invalid):
+		return err // programming error, not the client's fault.
+	case errors.Is(err, io.EOF):
+		return ErrEmptyBody.withCause(ErrEmptyBody.Message, err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		msg = "request body contains malformed JSON"
+	case errors.As(err, &syntaxErr):
+		msg = fmt.Sprintf("request body contains malformed JSON at position %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			msg = fmt.Sprintf("request body contains an invalid value for the field %q", typeErr.Field)
+		} else {
+			msg = fmt.Sprintf("request body contains an invalid value at position %d", typeErr.Offset)
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		msg = "request body contains unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	default:
+		msg = "request body contains invalid JSON"
+	}
+	return ErrMalformedBody.withCause(msg, err)
+}
+
+// checkJSONNumbers rejects the numbers of the document that cannot be represented without precision loss, or
+// whose absolute value exceeds maxAbs, if positive.
+func checkJSONNumbers(body []byte, maxAbs float64) error {
+	var bound *big.Float
+	if maxAbs > 0 && !math.IsInf(maxAbs, 1) {
+		bound = big.NewFloat(maxAbs)
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// syntax errors are reported by the decoding step.
+			return nil
+		}
+		n, ok := tok.(json.Number)
+		if !ok {
+			continue
+		}
+		abs := new(big.Float)
+		if strings.ContainsAny(n.String(), ".eE") {
+			var f float64
+			f, err = strconv.ParseFloat(n.String(), 64)
+			abs.SetFloat64(math.Abs(f))
+		} else {
+			var i int64
+			i, err = strconv.ParseInt(n.String(), 10, 64)
+			abs.SetInt64(i).Abs(abs)
+		}
+		if err != nil {
+			return ErrMalformedBody.withCause(fmt.Sprintf("request body contains the number %s, which exceeds the supported magnitude", n), err)
+		}
+		if bound != nil && abs.Cmp(bound) > 0 {
+			return ErrMalformedBody.withCause(fmt.Sprintf("request body contains the number %s, whose absolute value exceeds %v", n, maxAbs), nil)
+		}
+	}
+}
+
+// WriteJSON writes v as the JSON response with the given status, and the application/json content type.
+//
+// The value is encoded before anything is written, so an encoding error is returned with the response untouched,
+// and the handler can return it to let the LastResortErrorHandler write the error response instead. A nil v
+// writes the status only, without body nor content type.
+func WriteJSON(w http.ResponseWriter, status int, v any) error {
+	if v == nil {
+		w.WriteHeader(status)
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
+
+// rewriteJSONResponse buffers the response of next and, if it is a successful (2xx) response whose content
+// type is accepted by isJSON, decodes it, rewrites it with rewrite, and sends it with an adjusted
+// Content-Length. Numbers are decoded as json.Number to keep their precision. Other responses, and bodies that
+// are not valid JSON, are sent untouched.
+func rewriteJSONResponse(w http.ResponseWriter, r *http.Request, next Handler, isJSON func(contentType string) bool, rewrite func(doc any) any) error {
+	buf, r := bufferResponse(w, r)
+	if err := next.ServeHTTP(buf, r); err != nil || buf.streaming {
+		if buf.written() {
+			_ = buf.flushTo(w)
+		}
+		return err
+	}
+
+	status := buf.statusCode()
+	if status < 200 || status > 299 || !isJSON(buf.Header().Get("Content-Type")) {
+		return buf.flushTo(w)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(buf.body.Bytes()))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		// not our business to fix a broken response.
+		return buf.flushTo(w)
+	}
+
+	b, err := json.Marshal(rewrite(doc))
+	if err != nil {
+		return err
+	}
+	buf.body.Reset()
+	buf.body.Write(b)
+	buf.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	return buf.flushTo(w)
+}
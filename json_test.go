@@ -0,0 +1,154 @@
+package httprouterx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func expectHTTPError(t *testing.T, err error, status int) *HTTPError {
+	t.Helper()
+	var httpErr *HTTPError
+	expectTrue(t, errors.As(err, &httpErr))
+	expectTrue(t, httpErr.Status == status)
+	return httpErr
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	var p payload
+	err := DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"a","count":2}`)), &p, 0)
+	expectTrue(t, err == nil)
+	expectTrue(t, p.Name == "a" && p.Count == 2)
+
+	tests := []struct {
+		name     string
+		body     string
+		maxBytes int64
+		status   int
+	}{
+		{name: "empty", body: "", status: 400},
+		{name: "malformed", body: `{"name":`, status: 400},
+		{name: "syntax error", body: `{"name" "a"}`, status: 400},
+		{name: "wrong type", body: `{"count":"a"}`, status: 400},
+		{name: "multiple values", body: `{} {}`, status: 400},
+		{name: "too large", body: `{"name":"abcdefghij"}`, maxBytes: 10, status: 413},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p payload
+			err := DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader(tt.body)), &p, tt.maxBytes)
+			expectHTTPError(t, err, tt.status)
+		})
+	}
+}
+
+func TestDecodeJSONNumbers(t *testing.T) {
+	const body = `{"id": 9007199254740993, "amount": 12.5, "nested": [1, {"big": 9223372036854775807}]}`
+
+	// without UseNumber, the id silently loses precision.
+	var lossy map[string]any
+	err := DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader(body)), &lossy, 0)
+	expectTrue(t, err == nil)
+	expectTrue(t, lossy["id"].(float64) == 9007199254740992)
+
+	var exact map[string]any
+	err = DecodeJSONNumbers(httptest.NewRequest("POST", "/", strings.NewReader(body)), &exact, 0)
+	expectTrue(t, err == nil)
+	expectTrue(t, exact["id"].(json.Number).String() == "9007199254740993")
+	id, err := exact["id"].(json.Number).Int64()
+	expectTrue(t, err == nil && id == 9007199254740993)
+
+	for _, body := range []string{`{"id": 9223372036854775808}`, `[1, -9223372036854775809]`, `{"x": 1e400}`} {
+		var v any
+		err := DecodeJSONNumbers(httptest.NewRequest("POST", "/", strings.NewReader(body)), &v, 0)
+		httpErr := expectHTTPError(t, err, 400)
+		expectTrue(t, strings.Contains(httpErr.Message, "exceeds the supported magnitude"))
+	}
+}
+
+func TestDecodeJSONNumbersWithConfig(t *testing.T) {
+	decode := func(body string, maxAbs float64) error {
+		var v any
+		return DecodeJSONNumbersWithConfig(httptest.NewRequest("POST", "/", strings.NewReader(body)), &v, JSONNumbersConfig{MaxAbs: maxAbs})
+	}
+
+	// at the bound.
+	expectTrue(t, decode(`{"amount": 1000, "items": [-1000, 999.5, 1e3]}`, 1000) == nil)
+	expectTrue(t, decode(`9007199254740992`, 9007199254740992) == nil)
+
+	// just over the bound, at any depth.
+	for _, body := range []string{`{"amount": 1001}`, `{"items": [1, {"x": -1000.001}]}`, `1.0001e3`} {
+		httpErr := expectHTTPError(t, decode(body, 1000), 400)
+		expectTrue(t, errors.Is(httpErr, ErrMalformedBody))
+		expectTrue(t, strings.Contains(httpErr.Message, "exceeds 1000"))
+	}
+	// an integer just over a bound that float64 cannot tell apart.
+	expectHTTPError(t, decode(`9007199254740993`, 9007199254740992), 400)
+
+	// without bound, only the precision is checked.
+	expectTrue(t, decode(`1e300`, 0) == nil)
+	expectHTTPError(t, decode(`1e400`, 0), 400)
+}
+
+func TestWriteJSON(t *testing.T) {
+	res := httptest.NewRecorder()
+	expectTrue(t, WriteJSON(res, http.StatusCreated, map[string]int{"id": 1}) == nil)
+	expectTrue(t, res.Code == http.StatusCreated)
+	expectTrue(t, res.Header().Get("Content-Type") == "application/json")
+	expectTrue(t, res.Body.String() == `{"id":1}`)
+
+	res = httptest.NewRecorder()
+	expectTrue(t, WriteJSON(res, http.StatusAccepted, nil) == nil)
+	expectTrue(t, res.Code == http.StatusAccepted)
+	expectTrue(t, res.Header().Get("Content-Type") == "")
+	expectTrue(t, res.Body.Len() == 0)
+
+	// nothing is written when the encoding fails, so the error can still be rendered.
+	res = httptest.NewRecorder()
+	err := WriteJSON(res, http.StatusOK, map[string]any{"f": func() {}})
+	expectTrue(t, err != nil)
+	expectFalse(t, res.Flushed || res.Body.Len() > 0 || res.Header().Get("Content-Type") != "")
+	DefaultHandlers.LastResortError(res, httptest.NewRequest("GET", "/", nil), err)
+	expectTrue(t, res.Code == http.StatusInternalServerError)
+}
+
+func TestBindJSON(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+	request := func(contentType, body string) *http.Request {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	var p payload
+	expectTrue(t, BindJSON(request("application/json; charset=utf-8", `{"name":"gopher","extra":1}`), &p, 0) == nil)
+	expectTrue(t, p.Name == "gopher")
+	expectTrue(t, BindJSON(request("application/merge-patch+json", `{"name":"x"}`), &p, 0) == nil)
+
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		err := BindJSON(request(contentType, `{"name":"gopher"}`), &p, 0)
+		expectHTTPError(t, err, http.StatusUnsupportedMediaType)
+		expectTrue(t, errors.Is(err, ErrUnsupportedMediaType))
+	}
+
+	err := BindJSON(request("application/json", `{"name":`), &p, 0)
+	expectTrue(t, errors.Is(err, ErrMalformedBody))
+	err = BindJSON(request("application/json", `{"name":"`+strings.Repeat("x", 64)+`"}`), &p, 16)
+	expectTrue(t, errors.Is(err, ErrBodyTooLarge))
+
+	err = BindJSONStrict(request("application/json", `{"name":"gopher","nmae":"typo"}`), &p, 0)
+	httpErr := expectHTTPError(t, err, http.StatusBadRequest)
+	expectTrue(t, strings.Contains(httpErr.Message, `"nmae"`))
+	expectTrue(t, BindJSONStrict(request("application/json", `{"name":"gopher"}`), &p, 0) == nil)
+}
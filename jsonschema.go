@@ -0,0 +1,303 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is a compiled JSON Schema. Only the commonly used subset of the validation keywords is
+// supported: type, enum, const, properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, pattern, minimum, maximum, allOf, anyOf and oneOf. References ($ref) are not supported.
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Const                *any                   `json:"const"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+	OneOf                []*jsonSchema          `json:"oneOf"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes is the type keyword, which is either a single type or a list of types.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = many
+	return nil
+}
+
+// additionalProperties is either a boolean or a schema.
+type additionalProperties struct {
+	allowed bool
+	schema  *jsonSchema
+}
+
+func (a *additionalProperties) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &a.allowed); err == nil {
+		return nil
+	}
+	a.allowed = true
+	return json.Unmarshal(b, &a.schema)
+}
+
+// compileSchema parses the schema and compiles its patterns.
+func compileSchema(b []byte) (*jsonSchema, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var s jsonSchema
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	return &s, nil
+}
+
+func (s *jsonSchema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = re
+	}
+	children := append(append(append([]*jsonSchema{s.Items}, s.AllOf...), s.AnyOf...), s.OneOf...)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	if s.AdditionalProperties != nil {
+		children = append(children, s.AdditionalProperties.schema)
+	}
+	for _, c := range children {
+		if err := c.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateJSON decodes the document and validates it against the schema.
+// It returns the list of violations, which is empty if the document is valid.
+func (s *jsonSchema) validateJSON(doc []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return []string{"invalid json: " + err.Error()}
+	}
+	return s.validate(v, "$")
+}
+
+func (s *jsonSchema) validate(v any, path string) []string {
+	if s == nil {
+		return nil
+	}
+
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(s.Type) > 0 && !s.matchesType(v) {
+		fail("expected type %s, got %s", strings.Join(s.Type, " or "), jsonTypeOf(v))
+		return errs
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if jsonEqual(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value is not one of the enum values")
+		}
+	}
+	if s.Const != nil && !jsonEqual(v, *s.Const) {
+		fail("value does not match the const value")
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			childPath := path + "." + name
+			if prop, ok := s.Properties[name]; ok {
+				errs = append(errs, prop.validate(val[name], childPath)...)
+				continue
+			}
+			if ap := s.AdditionalProperties; ap != nil {
+				if !ap.allowed {
+					fail("additional property %q is not allowed", name)
+				} else {
+					errs = append(errs, ap.schema.validate(val[name], childPath)...)
+				}
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(val) < *s.MinItems {
+			fail("expected at least %d items, got %d", *s.MinItems, len(val))
+		}
+		if s.MaxItems != nil && len(val) > *s.MaxItems {
+			fail("expected at most %d items, got %d", *s.MaxItems, len(val))
+		}
+		for i, item := range val {
+			errs = append(errs, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case string:
+		n := utf8.RuneCountInString(val)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("expected at least %d characters, got %d", *s.MinLength, n)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("expected at most %d characters, got %d", *s.MaxLength, n)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			fail("value does not match pattern %q", s.Pattern)
+		}
+	case json.Number:
+		f, _ := val.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("expected minimum %v, got %v", *s.Minimum, val)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("expected maximum %v, got %v", *s.Maximum, val)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		errs = append(errs, sub.validate(v, path)...)
+	}
+	if len(s.AnyOf) > 0 && countValid(s.AnyOf, v, path) == 0 {
+		fail("value does not match any of the anyOf schemas")
+	}
+	if len(s.OneOf) > 0 {
+		if n := countValid(s.OneOf, v, path); n != 1 {
+			fail("value must match exactly one of the oneOf schemas, matched %d", n)
+		}
+	}
+	return errs
+}
+
+func countValid(schemas []*jsonSchema, v any, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.validate(v, path)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *jsonSchema) matchesType(v any) bool {
+	actual := jsonTypeOf(v)
+	for _, t := range s.Type {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON Schema type name of a value decoded with json.Decoder.UseNumber.
+func jsonTypeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if f, err := val.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// jsonEqual compares two decoded JSON values, comparing numbers by value.
+func jsonEqual(a, b any) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, _ := av.Float64()
+		bf, _ := bv.Float64()
+		return af == bf
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k := range av {
+			if !jsonEqual(av[k], bv[k]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,56 @@
+package httprouterx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema, err := compileSchema([]byte(`{
+		"type": "object",
+		"required": ["id", "name"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"name": {"type": "string", "minLength": 1, "maxLength": 5, "pattern": "^[a-z]+$"},
+			"status": {"enum": ["active", "inactive"]},
+			"score": {"type": ["number", "null"], "maximum": 10},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+			"ref": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+			"any": {"anyOf": [{"type": "boolean"}, {"const": 1}]}
+		}
+	}`))
+	expectTrue(t, err == nil)
+
+	tests := []struct {
+		doc        string
+		violations []string
+	}{
+		{doc: `{"id": 1, "name": "abc", "status": "active", "score": null, "tags": ["a"], "ref": 2, "any": true}`},
+		{doc: `{"id": 1.5, "name": "abc"}`, violations: []string{"$.id: expected type integer, got number"}},
+		{doc: `{"id": 0, "name": "abc"}`, violations: []string{"$.id: expected minimum 1"}},
+		{doc: `{"name": "ABCDEF"}`, violations: []string{`$: missing required property "id"`, "$.name: expected at most 5", "$.name: value does not match pattern"}},
+		{doc: `{"id": 1, "name": "a", "extra": 1}`, violations: []string{`$: additional property "extra" is not allowed`}},
+		{doc: `{"id": 1, "name": "a", "status": "deleted"}`, violations: []string{"$.status: value is not one of the enum values"}},
+		{doc: `{"id": 1, "name": "a", "tags": ["a", 1, "c"]}`, violations: []string{"$.tags: expected at most 2 items", "$.tags[1]: expected type string"}},
+		{doc: `{"id": 1, "name": "a", "ref": true, "any": 2}`, violations: []string{"$.any: value does not match any", "$.ref: value must match exactly one"}},
+		{doc: `[]`, violations: []string{"$: expected type object, got array"}},
+		{doc: `{`, violations: []string{"invalid json"}},
+	}
+
+	for _, tt := range tests {
+		got := schema.validateJSON([]byte(tt.doc))
+		expectTrue(t, len(got) == len(tt.violations))
+		for i, v := range tt.violations {
+			expectTrue(t, strings.HasPrefix(got[i], v))
+		}
+	}
+}
+
+func TestCompileSchema_Invalid(t *testing.T) {
+	_, err := compileSchema([]byte(`{"type": 1}`))
+	expectTrue(t, err != nil)
+
+	_, err = compileSchema([]byte(`{"properties": {"a": {"pattern": "("}}}`))
+	expectTrue(t, err != nil)
+}
@@ -0,0 +1,130 @@
+package httprouterx
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyCase is the casing style of JSON object keys.
+type KeyCase int
+
+const (
+	// CamelCase is the style of "userId".
+	CamelCase KeyCase = iota
+	// SnakeCase is the style of "user_id".
+	SnakeCase
+	// PascalCase is the style of "UserId".
+	PascalCase
+)
+
+// JSONKeyCase rewrites the object keys of successful (2xx) application/json responses, recursively, to the given
+// style. This enforces consistent naming across handlers that encode heterogeneous structs, without retagging
+// them all.
+//
+// Keys are split into words at '_', '-', and case changes, so any of the supported styles can be converted to
+// any other. Acronyms are not preserved: "userID" becomes "user_id" in snake case and "userId" in camel case.
+// When two keys of the same object convert to the same key, the last one in alphabetical order wins.
+//
+// The whole response is buffered, decoded and encoded again, which costs memory and CPU proportional to its
+// size; prefer fixing the struct tags for large or hot endpoints. The object keys of the rewritten response are
+// sorted, and its Content-Length is adjusted.
+func JSONKeyCase(style KeyCase) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return rewriteJSONResponse(w, r, next, isPlainJSON, func(doc any) any { return convertKeys(doc, style) })
+		})
+	}
+}
+
+// isPlainJSON reports whether the content type is exactly application/json.
+func isPlainJSON(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json"
+}
+
+// convertKeys converts the object keys of the JSON value, at any depth.
+func convertKeys(v any, style KeyCase) any {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		out := make(map[string]any, len(v))
+		for _, k := range keys {
+			out[style.convert(k)] = convertKeys(v[k], style)
+		}
+		return out
+	case []any:
+		for i, elem := range v {
+			v[i] = convertKeys(elem, style)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// convert converts the key to the style.
+func (c KeyCase) convert(key string) string {
+	words := splitWords(key)
+	if len(words) == 0 {
+		return key
+	}
+
+	var b strings.Builder
+	for i, word := range words {
+		word = strings.ToLower(word)
+		switch {
+		case c == SnakeCase:
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteString(word)
+		case c == CamelCase && i == 0:
+			b.WriteString(word)
+		default:
+			r := []rune(word)
+			r[0] = unicode.ToUpper(r[0])
+			b.WriteString(string(r))
+		}
+	}
+	return b.String()
+}
+
+// splitWords splits the key at '_', '-', and case changes. An uppercase run followed by a lowercase letter
+// starts a new word at its last letter, so "HTTPServer" is split into "HTTP" and "Server".
+func splitWords(key string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+	runes := []rune(key)
+	for i, r := range runes {
+		if r == '_' || r == '-' {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
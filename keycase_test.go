@@ -0,0 +1,75 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestKeyCase_Convert(t *testing.T) {
+	tests := []struct {
+		key                  string
+		camel, snake, pascal string
+	}{
+		{key: "user_id", camel: "userId", snake: "user_id", pascal: "UserId"},
+		{key: "userId", camel: "userId", snake: "user_id", pascal: "UserId"},
+		{key: "UserID", camel: "userId", snake: "user_id", pascal: "UserId"},
+		{key: "HTTPServer", camel: "httpServer", snake: "http_server", pascal: "HttpServer"},
+		{key: "created-at", camel: "createdAt", snake: "created_at", pascal: "CreatedAt"},
+		{key: "name", camel: "name", snake: "name", pascal: "Name"},
+		{key: "_", camel: "_", snake: "_", pascal: "_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			expectTrue(t, CamelCase.convert(tt.key) == tt.camel)
+			expectTrue(t, SnakeCase.convert(tt.key) == tt.snake)
+			expectTrue(t, PascalCase.convert(tt.key) == tt.pascal)
+		})
+	}
+}
+
+func TestJSONKeyCase(t *testing.T) {
+	body := `{"user_id":1,"DisplayName":"gopher","address":{"zip_code":"10110"},"orders":[{"order_id":9007199254740993}]}`
+	handler := func(status int, contentType string) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+			return nil
+		})
+	}
+
+	t.Run("camel case", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		err := JSONKeyCase(CamelCase).Then(handler(200, "application/json; charset=utf-8")).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		want := `{"address":{"zipCode":"10110"},"displayName":"gopher","orders":[{"orderId":9007199254740993}],"userId":1}`
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Body.String() == want)
+		expectTrue(t, res.Header().Get("Content-Length") == strconv.Itoa(len(want)))
+	})
+
+	t.Run("snake case", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		err := JSONKeyCase(SnakeCase).Then(handler(200, "application/json")).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Body.String() == `{"address":{"zip_code":"10110"},"display_name":"gopher","orders":[{"order_id":9007199254740993}],"user_id":1}`)
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		err := JSONKeyCase(CamelCase).Then(handler(200, "application/problem+json")).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Body.String() == body)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		err := JSONKeyCase(CamelCase).Then(handler(400, "application/json")).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == 400)
+		expectTrue(t, res.Body.String() == body)
+	})
+}
@@ -0,0 +1,84 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language negotiates the language of the response from the Accept-Language header of the request (RFC 4647
+// lookup) among the supported language tags, e.g. Language("en-US", "fr", "de"), and stores it in the context,
+// to be read with RequestLanguage. The first supported tag is the default, used when the client accepts none of
+// them or sends no Accept-Language. It also adds Accept-Language to the Vary response header.
+//
+// The tags are plain BCP 47 strings, compared case-insensitively, so this package does not depend on
+// golang.org/x/text. A range matches a supported tag that is equal, or more specific ("en" matches "en-US"), and
+// otherwise is shortened until it matches ("fr-CA" matches "fr"). It panics if no tag is supported.
+func Language(supported ...string) Middleware {
+	if len(supported) == 0 {
+		panic("httprouterx: Language: at least one language must be supported")
+	}
+	supported = append([]string(nil), supported...)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("Vary", "Accept-Language")
+			lang := negotiateLanguage(strings.Join(r.Header.Values("Accept-Language"), ","), supported)
+			return next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), languageKey, lang)))
+		})
+	}
+}
+
+// RequestLanguage gets the language negotiated by the Language middleware, as written in its supported tags.
+// It returns an empty string if the middleware was not applied.
+func RequestLanguage(r *http.Request) string {
+	lang, _ := r.Context().Value(languageKey).(string)
+	return lang
+}
+
+// languageRange is a language range of the Accept-Language header.
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// negotiateLanguage returns the supported tag best matching the Accept-Language header, or the first one.
+func negotiateLanguage(header string, supported []string) string {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		lr := languageRange{tag: strings.TrimSpace(tag), quality: 1}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			v, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			lr.quality = v
+		}
+		if lr.tag != "" && lr.quality > 0 {
+			ranges = append(ranges, lr)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	for _, lr := range ranges {
+		if lr.tag == "*" {
+			return supported[0]
+		}
+		for prefix := lr.tag; prefix != ""; {
+			for _, tag := range supported {
+				if strings.EqualFold(tag, prefix) || len(tag) > len(prefix) && tag[len(prefix)] == '-' && strings.EqualFold(tag[:len(prefix)], prefix) {
+					return tag
+				}
+			}
+			i := strings.LastIndexByte(prefix, '-')
+			if i < 0 {
+				break
+			}
+			prefix = prefix[:i]
+		}
+	}
+	return supported[0]
+}
@@ -0,0 +1,43 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLanguage(t *testing.T) {
+	var lang string
+	h := Language("en-US", "fr", "pt-BR").Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		lang = RequestLanguage(r)
+		return nil
+	}))
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "en-US"},
+		{"fr", "fr"},
+		{"FR-ca", "fr"},
+		{"en", "en-US"},
+		{"pt", "pt-BR"},
+		{"de, fr;q=0.5, en;q=0.8", "en-US"},
+		{"de, *;q=0.1", "en-US"},
+		{"fr;q=0, pt-BR;q=0.3", "pt-BR"},
+		{"de, ja", "en-US"},
+		{"fr;q=invalid, pt", "pt-BR"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+		res := httptest.NewRecorder()
+		_ = h.ServeHTTP(res, req)
+		expectTrue(t, lang == tt.want)
+		expectTrue(t, res.Header().Get("Vary") == "Accept-Language")
+	}
+
+	expectTrue(t, RequestLanguage(httptest.NewRequest("GET", "/", nil)) == "")
+}
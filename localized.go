@@ -0,0 +1,78 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocalizedErrors returns a LastResortErrorHandler that translates the error messages into the language of the
+// request. The catalog maps the language tags to the translated messages, keyed by the Code of the *HTTPError,
+// e.g. "malformed_body", or by the status, e.g. "404", for the errors without Code, including the plain errors,
+// which are 500.
+//
+// The language of the request is the one negotiated by the Language middleware when it is visible to the
+// handler, i.e. when Language runs outside the ServeMux. Otherwise, since the LastResortErrorHandler gets the
+// request as dispatched, before the middlewares, the language is negotiated the same way among the languages of
+// the catalog. The message is looked up in the catalog of the request language, then of its base language ("fr"
+// for "fr-CA"), then of the fallback language. The response carries the Content-Language of the translation.
+// Errors without translation are rendered by DefaultHandlers.LastResortError as is.
+//
+// The tags are plain BCP 47 strings, compared case-insensitively, like in Language, so this package does not
+// depend on golang.org/x/text.
+func LocalizedErrors(catalog map[string]map[string]string, fallback string) LastResortErrorHandler {
+	// the catalogs are indexed by lower-cased tag.
+	messages := make(map[string]map[string]string, len(catalog))
+	supported := []string{fallback}
+	for tag, msgs := range catalog {
+		messages[strings.ToLower(tag)] = msgs
+		if !strings.EqualFold(tag, fallback) {
+			supported = append(supported, tag)
+		}
+	}
+	sort.Strings(supported[1:])
+
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		httpErr := &HTTPError{Status: http.StatusInternalServerError, Err: err}
+		if !errors.As(err, &httpErr) {
+			httpErr.Message = http.StatusText(http.StatusInternalServerError)
+		}
+		key := httpErr.Code
+		if key == "" {
+			key = strconv.Itoa(httpErr.Status)
+		}
+
+		lang := RequestLanguage(r)
+		if lang == "" {
+			lang = negotiateLanguage(strings.Join(r.Header.Values("Accept-Language"), ","), supported)
+		}
+		lang, msg, ok := lookupTranslation(messages, lang, key)
+		if !ok {
+			lang, msg, ok = lookupTranslation(messages, fallback, key)
+		}
+		if !ok {
+			DefaultHandlers.LastResortError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Language", lang)
+		DefaultHandlers.LastResortError(w, r, &HTTPError{Status: httpErr.Status, Code: httpErr.Code, Message: msg, Err: err})
+	}
+}
+
+// lookupTranslation gets the message of the key in the catalog of the language, or of its shorter forms.
+func lookupTranslation(messages map[string]map[string]string, lang, key string) (string, string, bool) {
+	for tag := lang; tag != ""; {
+		if msg, ok := messages[strings.ToLower(tag)][key]; ok {
+			return tag, msg, true
+		}
+		i := strings.LastIndexByte(tag, '-')
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	return "", "", false
+}
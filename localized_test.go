@@ -0,0 +1,98 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocalizedErrors(t *testing.T) {
+	mux := NewServeMux(
+		Options.LastResortErrorHandler(LocalizedErrors(map[string]map[string]string{
+			"en": {
+				"malformed_body": "the request body is malformed",
+				"404":            "not found",
+				"500":            "something went wrong",
+			},
+			"fr": {
+				"malformed_body": "le corps de la requête est mal formé",
+				"404":            "introuvable",
+			},
+			"fr-CA": {
+				"404": "pas trouvé",
+			},
+		}, "en")),
+	)
+	mux.GET("/malformed", func(w http.ResponseWriter, r *http.Request) error {
+		return ErrMalformedBody.withCause("request body contains malformed JSON", nil)
+	})
+	mux.GET("/missing", func(w http.ResponseWriter, r *http.Request) error {
+		return NewHTTPError(http.StatusNotFound, "")
+	})
+	mux.GET("/fail", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database password is hunter2")
+	})
+	mux.GET("/conflict", func(w http.ResponseWriter, r *http.Request) error {
+		return NewHTTPError(http.StatusConflict, "already exists")
+	})
+
+	serve := func(target, acceptLanguage string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, req)
+		return res
+	}
+
+	res := serve("/malformed", "fr")
+	expectTrue(t, res.Code == http.StatusBadRequest)
+	expectTrue(t, res.Header().Get("Content-Language") == "fr")
+	expectTrue(t, strings.Contains(res.Body.String(), `"error":"malformed_body"`))
+	expectTrue(t, strings.Contains(res.Body.String(), "le corps de la requête est mal formé"))
+
+	// the regional catalog, then the base language, then the fallback.
+	res = serve("/missing", "fr-CA")
+	expectTrue(t, res.Code == http.StatusNotFound)
+	expectTrue(t, res.Header().Get("Content-Language") == "fr-CA")
+	expectTrue(t, strings.Contains(res.Body.String(), "pas trouvé"))
+
+	res = serve("/malformed", "fr-CA")
+	expectTrue(t, res.Header().Get("Content-Language") == "fr")
+	expectTrue(t, strings.Contains(res.Body.String(), "mal formé"))
+
+	res = serve("/missing", "de")
+	expectTrue(t, res.Header().Get("Content-Language") == "en")
+	expectTrue(t, strings.Contains(res.Body.String(), "not found"))
+
+	// plain errors are translated by status, which hides their text.
+	res = serve("/fail", "fr")
+	expectTrue(t, res.Code == http.StatusInternalServerError)
+	expectTrue(t, strings.Contains(res.Body.String(), "something went wrong"))
+	expectFalse(t, strings.Contains(res.Body.String(), "hunter2"))
+
+	// without translation, the error is rendered as is.
+	res = serve("/conflict", "fr")
+	expectTrue(t, res.Code == http.StatusConflict)
+	expectTrue(t, res.Header().Get("Content-Language") == "")
+	expectTrue(t, strings.Contains(res.Body.String(), "already exists"))
+}
+
+func TestLocalizedErrors_RequestLanguage(t *testing.T) {
+	handler := LocalizedErrors(map[string]map[string]string{
+		"en": {"404": "not found"},
+		"de": {"404": "nicht gefunden"},
+	}, "en")
+
+	// the language negotiated by the Language middleware wins over the catalog languages.
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "en")
+	_ = Language("de-AT").Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		handler(w, r, NewHTTPError(http.StatusNotFound, ""))
+		return nil
+	})).ServeHTTP(res, req)
+	expectTrue(t, res.Header().Get("Content-Language") == "de")
+	expectTrue(t, strings.Contains(res.Body.String(), "nicht gefunden"))
+}
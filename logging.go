@@ -0,0 +1,46 @@
+package httprouterx
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// LoggingMiddleware logs one line per request with the method, the path, the status, the duration and the
+// number of body bytes written. When the handler returns an error, it is logged too, at the error level, and the
+// status is the one the LastResortErrorHandler is expected to send if nothing was written yet: the Status of an
+// *HTTPError, or 500. A nil logger means slog.Default().
+//
+// The response writer passed to the handler still supports http.Flusher and http.Hijacker when the underlying
+// writer does.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+			sw := newStatusWriter(w)
+			err := next.ServeHTTP(sw, r)
+
+			status := sw.statusCode()
+			if err != nil && !sw.written() {
+				status = errorStatus(err)
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int64("bytes", sw.bytes),
+			}
+			level := slog.LevelInfo
+			if err != nil {
+				level = slog.LevelError
+				attrs = append(attrs, slog.Any("error", err))
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+			return err
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "GET", Path: "/ok", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+		return nil
+	}}, LoggingMiddleware(logger))
+	mux.Route(Route{Method: "GET", Path: "/missing", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		return NewHTTPError(http.StatusNotFound, "no such thing")
+	}}, LoggingMiddleware(logger))
+
+	record := func(target string) map[string]any {
+		logs.Reset()
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+		var rec map[string]any
+		expectTrue(t, json.Unmarshal(logs.Bytes(), &rec) == nil)
+		return rec
+	}
+
+	rec := record("/ok")
+	expectTrue(t, rec["level"] == "INFO")
+	expectTrue(t, rec["msg"] == "request")
+	expectTrue(t, rec["method"] == "GET")
+	expectTrue(t, rec["path"] == "/ok")
+	expectTrue(t, rec["status"] == float64(201))
+	expectTrue(t, rec["bytes"] == float64(5))
+	_, ok := rec["duration"]
+	expectTrue(t, ok)
+	_, ok = rec["error"]
+	expectFalse(t, ok)
+
+	rec = record("/missing")
+	expectTrue(t, rec["level"] == "ERROR")
+	expectTrue(t, rec["status"] == float64(404))
+	expectTrue(t, rec["bytes"] == float64(0))
+	expectTrue(t, rec["error"] == NewHTTPError(http.StatusNotFound, "no such thing").Error())
+}
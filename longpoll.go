@@ -0,0 +1,59 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DefaultLongPollInterval is the interval between two checks of LongPoll.
+const DefaultLongPollInterval = 500 * time.Millisecond
+
+// LongPoll creates a long-polling handler. It calls check every DefaultLongPollInterval until it reports that the
+// data is available, then writes the data as JSON with status 200. If the data is still not available after
+// wait, it responds with 204 No Content, so the client can poll again.
+//
+// The context given to check is cancelled on timeout or when the client disconnects, in which case the loop is
+// aborted and nothing is written. Errors returned by check are returned by the handler, except the ones caused
+// by the timeout. Use LongPollWithInterval to change the interval between checks.
+func LongPoll(wait time.Duration, check func(ctx context.Context) (any, bool, error)) HandlerFunc {
+	return LongPollWithInterval(wait, DefaultLongPollInterval, check)
+}
+
+// LongPollWithInterval is like LongPoll, but with a custom interval between two checks.
+func LongPollWithInterval(wait, interval time.Duration, check func(ctx context.Context) (any, bool, error)) HandlerFunc {
+	if interval <= 0 {
+		interval = DefaultLongPollInterval
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), wait)
+		defer cancel()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			v, ok, err := check(ctx)
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+				return err
+			}
+			if err == nil && ok {
+				return WriteJSON(w, http.StatusOK, v)
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-ticker.C:
+				continue
+			}
+
+			if r.Context().Err() != nil {
+				// the client is gone, there is no one to respond to.
+				return nil
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLongPoll(t *testing.T) {
+	t.Run("data becomes available", func(t *testing.T) {
+		calls := 0
+		h := LongPollWithInterval(time.Second, time.Millisecond, func(ctx context.Context) (any, bool, error) {
+			calls++
+			if calls < 3 {
+				return nil, false, nil
+			}
+			return map[string]int{"calls": calls}, true, nil
+		})
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/events", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == 200)
+		expectTrue(t, res.Header().Get("Content-Type") == "application/json")
+		expectTrue(t, res.Body.String() == `{"calls":3}`)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		h := LongPollWithInterval(20*time.Millisecond, time.Millisecond, func(ctx context.Context) (any, bool, error) {
+			return nil, false, nil
+		})
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/events", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == 204)
+	})
+
+	t.Run("check respects the deadline", func(t *testing.T) {
+		h := LongPoll(20*time.Millisecond, func(ctx context.Context) (any, bool, error) {
+			<-ctx.Done()
+			return nil, false, ctx.Err()
+		})
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/events", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == 204)
+	})
+
+	t.Run("client disconnects", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		h := LongPollWithInterval(time.Second, time.Millisecond, func(context.Context) (any, bool, error) {
+			cancel()
+			return nil, false, nil
+		})
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("GET", "/events", nil).WithContext(ctx))
+		expectTrue(t, err == nil)
+		expectFalse(t, res.Flushed)
+		expectTrue(t, res.Body.Len() == 0)
+	})
+
+	t.Run("check fails", func(t *testing.T) {
+		anError := errors.New("check failed")
+		h := LongPoll(time.Second, func(context.Context) (any, bool, error) { return nil, false, anError })
+		err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+		expectTrue(t, errors.Is(err, anError))
+	})
+}
@@ -0,0 +1,37 @@
+package httprouterx
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives the measures of MetricsMiddleware. It is implemented by adapters of the metrics
+// backends, e.g. a Prometheus histogram labeled by method, path and status, so this package does not depend on
+// any metrics library. It must be safe for concurrent use.
+type MetricsRecorder interface {
+	// ObserveRequest records a served request. The path is the route pattern, e.g. /users/:id.
+	ObserveRequest(method, path string, status int, dur time.Duration)
+}
+
+// MetricsMiddleware times each request and reports it to the recorder with its method, route pattern and
+// status. The path is the one returned by MatchedPath rather than the concrete path, which keeps the cardinality
+// of the labels bounded; it is empty when the middleware is not used inside a route of the ServeMux.
+//
+// When the handler returns an error without writing the response, the status is the one the
+// LastResortErrorHandler is expected to send: the Status of an *HTTPError, or 500.
+func MetricsMiddleware(reg MetricsRecorder) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+			sw := newStatusWriter(w)
+			err := next.ServeHTTP(sw, r)
+
+			status := sw.statusCode()
+			if err != nil && !sw.written() {
+				status = errorStatus(err)
+			}
+			reg.ObserveRequest(r.Method, MatchedPath(r), status, time.Since(start))
+			return err
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package httprouterx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// metricsRecorderFunc adapts a function to MetricsRecorder.
+type metricsRecorderFunc func(method, path string, status int, dur time.Duration)
+
+func (f metricsRecorderFunc) ObserveRequest(method, path string, status int, dur time.Duration) {
+	f(method, path, status, dur)
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		observed []string
+	)
+	rec := metricsRecorderFunc(func(method, path string, status int, dur time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		expectTrue(t, dur > 0)
+		observed = append(observed, fmt.Sprintf("%s %s %d", method, path, status))
+	})
+
+	mux := NewServeMux(Options.Middleware(MetricsMiddleware(rec)))
+	mux.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		time.Sleep(time.Millisecond)
+		if r.URL.Path == "/users/0" {
+			return NewHTTPError(http.StatusNotFound, "no such user")
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return nil
+	})
+	mux.POST("/users", func(w http.ResponseWriter, r *http.Request) error {
+		time.Sleep(time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+		return nil
+	})
+
+	for _, target := range []string{"/users/1", "/users/2", "/users/0"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	}
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/users", nil))
+
+	expectTrue(t, len(observed) == 4)
+	expectTrue(t, observed[0] == "GET /users/:id 202")
+	expectTrue(t, observed[1] == "GET /users/:id 202")
+	expectTrue(t, observed[2] == "GET /users/:id 404")
+	expectTrue(t, observed[3] == "POST /users 200")
+}
@@ -0,0 +1,253 @@
+package httprouterx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Default limits of MultipartLimits.
+const (
+	DefaultMultipartMaxBytes      = 32 << 20
+	DefaultMultipartMaxParts      = 1000
+	DefaultMultipartMaxNameBytes  = 256
+	DefaultMultipartMaxValueBytes = 1 << 20
+
+	DefaultMultipartMaxMemoryBytes = 1 << 20
+)
+
+// ErrMultipartLimit is the underlying error of the *HTTPError returned by MultipartLimits when a limit is
+// exceeded.
+var ErrMultipartLimit = errors.New("multipart limit exceeded")
+
+// MultipartConfig is the configuration for MultipartLimits. Zero values mean the defaults.
+type MultipartConfig struct {
+	// MaxBytes limits the whole request body. Default DefaultMultipartMaxBytes.
+	MaxBytes int64
+
+	// MaxParts limits the number of parts, files and form fields alike. Default DefaultMultipartMaxParts.
+	MaxParts int
+
+	// MaxFileBytes limits the size of each file part. Default MaxBytes.
+	MaxFileBytes int64
+
+	// MaxNameBytes limits the length of the form field names. Default DefaultMultipartMaxNameBytes.
+	MaxNameBytes int
+
+	// MaxValueBytes limits the size of each form field that is not a file. Default DefaultMultipartMaxValueBytes.
+	MaxValueBytes int64
+
+	// MaxMemoryBytes limits the part of the checked body kept in memory for the handler, the rest is stored in a
+	// temporary file. Default DefaultMultipartMaxMemoryBytes.
+	MaxMemoryBytes int64
+}
+
+// MultipartLimits hardens upload endpoints against resource exhaustion: it checks multipart request bodies
+// against the limits of cfg before the handler parses them. The body is parsed as a stream, part by part, and the
+// request is rejected as soon as a limit is exceeded, without reading the rest of the body:
+//   - 413 Request Entity Too Large when the body, a file, a form field value or the number of parts exceeds its
+//     limit.
+//   - 400 Bad Request when a form field name is too long, or the body is malformed.
+//
+// The errors are ErrBodyTooLarge (413) or ErrMalformedBody (400), and wrap ErrMultipartLimit, except for
+// malformed bodies. The checked body is handed to the handler untouched, e.g. for r.ParseMultipartForm: its first
+// MaxMemoryBytes are kept in memory, and the rest in a temporary file, removed once the handler returns. Requests
+// that are not multipart are left untouched.
+func MultipartLimits(cfg MultipartConfig) Middleware {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMultipartMaxBytes
+	}
+	if cfg.MaxParts <= 0 {
+		cfg.MaxParts = DefaultMultipartMaxParts
+	}
+	if cfg.MaxFileBytes <= 0 {
+		cfg.MaxFileBytes = cfg.MaxBytes
+	}
+	if cfg.MaxNameBytes <= 0 {
+		cfg.MaxNameBytes = DefaultMultipartMaxNameBytes
+	}
+	if cfg.MaxValueBytes <= 0 {
+		cfg.MaxValueBytes = DefaultMultipartMaxValueBytes
+	}
+	if cfg.MaxMemoryBytes <= 0 {
+		cfg.MaxMemoryBytes = DefaultMultipartMaxMemoryBytes
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || !strings.HasPrefix(mediaType, "multipart/") || r.Body == nil {
+				return next.ServeHTTP(w, r)
+			}
+			if params["boundary"] == "" {
+				return ErrMalformedBody.withCause("multipart body has no boundary", nil)
+			}
+			if r.ContentLength > cfg.MaxBytes {
+				return multipartLimitError(ErrBodyTooLarge, "request body must not exceed %d bytes", cfg.MaxBytes)
+			}
+
+			raw := &spillBuffer{max: cfg.MaxMemoryBytes}
+			defer raw.close()
+			body := io.TeeReader(http.MaxBytesReader(nil, r.Body, cfg.MaxBytes), raw)
+			err = checkMultipart(multipart.NewReader(body, params["boundary"]), cfg)
+			if err == nil {
+				// keeps the epilogue, if any.
+				if _, err = io.Copy(io.Discard, body); err != nil {
+					err = multipartReadError(err, cfg)
+				}
+			}
+			if raw.err != nil {
+				return raw.err // not the client's fault.
+			}
+			if err != nil {
+				return err
+			}
+
+			checked, err := raw.reader()
+			if err != nil {
+				return err
+			}
+			r.Body = readCloser{Reader: checked, Closer: r.Body}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// spillBuffer keeps the first max bytes written in memory, and the rest in a temporary file.
+type spillBuffer struct {
+	max  int64
+	mem  bytes.Buffer
+	file *os.File
+	err  error // the error of the temporary file.
+}
+
+// Write implements io.Writer.
+func (b *spillBuffer) Write(p []byte) (int, error) {
+	if b.file == nil && int64(b.mem.Len()+len(p)) <= b.max {
+		return b.mem.Write(p)
+	}
+	if b.file == nil {
+		if b.file, b.err = os.CreateTemp("", "httprouterx-multipart-*"); b.err != nil {
+			return 0, b.err
+		}
+	}
+	n, err := b.file.Write(p)
+	if err != nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// reader returns a reader of the whole content.
+func (b *spillBuffer) reader() (io.Reader, error) {
+	if b.file == nil {
+		return bytes.NewReader(b.mem.Bytes()), nil
+	}
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.MultiReader(bytes.NewReader(b.mem.Bytes()), b.file), nil
+}
+
+// close removes the temporary file, if any.
+func (b *spillBuffer) close() {
+	if b.file != nil {
+		_ = b.file.Close()
+		_ = os.Remove(b.file.Name())
+	}
+}
+
+// checkMultipart reads all the parts, checking them against the limits.
+func checkMultipart(mr *multipart.Reader, cfg MultipartConfig) error {
+	for parts := 1; ; parts++ {
+		part, err := mr.NextRawPart()
+		if err == io.EOF { // a wrapped io.EOF means a truncated body.
+			return nil
+		}
+		if err != nil {
+			return multipartReadError(err, cfg)
+		}
+		if parts > cfg.MaxParts {
+			return multipartLimitError(ErrBodyTooLarge, "multipart body must not have more than %d parts", cfg.MaxParts)
+		}
+
+		name := part.FormName()
+		if len(name) > cfg.MaxNameBytes {
+			return multipartLimitError(ErrMalformedBody, "multipart field names must not exceed %d bytes", cfg.MaxNameBytes)
+		}
+
+		limit, what := cfg.MaxValueBytes, "field"
+		if part.FileName() != "" {
+			limit, what = cfg.MaxFileBytes, "file"
+		}
+		n, err := io.Copy(io.Discard, io.LimitReader(part, limit+1))
+		if err != nil {
+			return multipartReadError(err, cfg)
+		}
+		if n > limit {
+			return multipartLimitError(ErrBodyTooLarge, "multipart %s %q must not exceed %d bytes", what, name, limit)
+		}
+	}
+}
+
+// multipartLimitError creates the *HTTPError of the kind of sentinel for an exceeded limit.
+func multipartLimitError(sentinel *HTTPError, format string, args ...any) error {
+	return sentinel.withCause(fmt.Sprintf(format, args...), ErrMultipartLimit)
+}
+
+// multipartReadError maps an error reading the body: 413 if the body is too large, ErrMalformedBody otherwise.
+func multipartReadError(err error, cfg MultipartConfig) error {
+	var (
+		httpErr *HTTPError
+		maxErr  *http.MaxBytesError
+	)
+	if errors.As(err, &httpErr) {
+		return err
+	}
+	if errors.As(err, &maxErr) {
+		return multipartLimitError(ErrBodyTooLarge, "request body must not exceed %d bytes", cfg.MaxBytes)
+	}
+	return ErrMalformedBody.withCause("request body contains malformed multipart data", err)
+}
+
+// DefaultMultipartMaxMemory is the memory used by MultipartForm when the given one is not positive.
+const DefaultMultipartMaxMemory = 32 << 20
+
+// MultipartForm parses the multipart/form-data body of the request, like r.ParseMultipartForm, keeping at most
+// maxMemory bytes of the files in memory (DefaultMultipartMaxMemory if not positive) and storing the rest in
+// temporary files. It returns the parsed form, which is also available in r.MultipartForm.
+//
+// It returns an *HTTPError, so the error can be returned from the handler as is: ErrUnsupportedMediaType (415)
+// if the body is not multipart/form-data, ErrBodyTooLarge (413) if the body exceeds a limit, e.g. set by
+// MaxBodyBytes or MultipartLimits, or ErrMalformedBody (400) if it is malformed.
+func MultipartForm(r *http.Request, maxMemory int64) (*multipart.Form, error) {
+	if maxMemory <= 0 {
+		maxMemory = DefaultMultipartMaxMemory
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return nil, ErrUnsupportedMediaType.withCause("request body must be multipart/form-data", nil)
+	}
+
+	err := r.ParseMultipartForm(maxMemory)
+	var (
+		httpErr *HTTPError
+		maxErr  *http.MaxBytesError
+	)
+	switch {
+	case err == nil:
+		return r.MultipartForm, nil
+	case errors.As(err, &httpErr):
+		return nil, err
+	case errors.Is(err, multipart.ErrMessageTooLarge), errors.As(err, &maxErr):
+		return nil, ErrBodyTooLarge.withCause(ErrBodyTooLarge.Message, err)
+	default:
+		return nil, ErrMalformedBody.withCause("request body contains malformed multipart data", err)
+	}
+}
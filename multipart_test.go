@@ -0,0 +1,144 @@
+package httprouterx
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// multipartBody builds a multipart body with the given fields, and files whose names start with "file".
+func multipartBody(t *testing.T, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		var err error
+		if strings.HasPrefix(name, "file") {
+			fw, ferr := mw.CreateFormFile(name, name+".bin")
+			expectTrue(t, ferr == nil)
+			_, err = fw.Write([]byte(value))
+		} else {
+			err = mw.WriteField(name, value)
+		}
+		expectTrue(t, err == nil)
+	}
+	expectTrue(t, mw.Close() == nil)
+	return &body, mw.FormDataContentType()
+}
+
+func TestMultipartLimits(t *testing.T) {
+	var parsed map[string][]string
+	h := MultipartLimits(MultipartConfig{
+		MaxBytes:      4 << 10,
+		MaxParts:      3,
+		MaxFileBytes:  1 << 10,
+		MaxNameBytes:  16,
+		MaxValueBytes: 64,
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			return err
+		}
+		parsed = r.MultipartForm.Value
+		return nil
+	}))
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		status int
+	}{
+		{name: "within limits", fields: map[string]string{"title": "hello", "file": strings.Repeat("x", 1<<10)}},
+		{name: "total size", fields: map[string]string{"a": "1", "file1": strings.Repeat("x", 1<<10), "file2": strings.Repeat("x", 1<<10), "file3": strings.Repeat("x", 1<<10), "file4": strings.Repeat("x", 1<<10)}, status: 413},
+		{name: "file size", fields: map[string]string{"file": strings.Repeat("x", 1<<10+1)}, status: 413},
+		{name: "value size", fields: map[string]string{"title": strings.Repeat("x", 65)}, status: 413},
+		{name: "parts", fields: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"}, status: 413},
+		{name: "name length", fields: map[string]string{strings.Repeat("n", 17): "1"}, status: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed = nil
+			body, contentType := multipartBody(t, tt.fields)
+			req := httptest.NewRequest("POST", "/", body)
+			req.Header.Set("Content-Type", contentType)
+			req.ContentLength = -1 // as a chunked request, so the stream is checked.
+
+			err := h.ServeHTTP(httptest.NewRecorder(), req)
+			if tt.status == 0 {
+				expectTrue(t, err == nil)
+				expectTrue(t, parsed["title"][0] == "hello")
+				return
+			}
+			httpErr := expectHTTPError(t, err, tt.status)
+			expectTrue(t, errors.Is(httpErr, ErrMultipartLimit))
+			expectTrue(t, errors.Is(httpErr, ErrBodyTooLarge) || errors.Is(httpErr, ErrMalformedBody))
+			expectTrue(t, parsed == nil)
+		})
+	}
+}
+
+func TestMultipartLimits_Stream(t *testing.T) {
+	// many tiny parts: the check stops at the first part over the limit, without reading the rest of the body.
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for i := 0; i < 10000; i++ {
+		_ = mw.WriteField("f", "v")
+	}
+	_ = mw.Close()
+	src := bytes.NewReader(body.Bytes())
+
+	h := MultipartLimits(MultipartConfig{MaxParts: 10}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+	req := httptest.NewRequest("POST", "/", src)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	expectHTTPError(t, h.ServeHTTP(httptest.NewRecorder(), req), http.StatusRequestEntityTooLarge)
+	expectTrue(t, src.Len() > body.Len()/2)
+
+	// a declared length over the limit is rejected upfront.
+	req = httptest.NewRequest("POST", "/", strings.NewReader("x"))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.ContentLength = DefaultMultipartMaxBytes + 1
+	expectHTTPError(t, h.ServeHTTP(httptest.NewRecorder(), req), http.StatusRequestEntityTooLarge)
+
+	// malformed bodies are rejected with 400.
+	req = httptest.NewRequest("POST", "/", strings.NewReader("garbage"))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	expectHTTPError(t, h.ServeHTTP(httptest.NewRecorder(), req), http.StatusBadRequest)
+
+	// other requests are left untouched.
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), req) == nil)
+}
+
+func TestMultipartLimits_Spill(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	var spilled []os.DirEntry
+	h := MultipartLimits(MultipartConfig{MaxMemoryBytes: 64}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		spilled, _ = os.ReadDir(tmp)
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			return err
+		}
+		expectTrue(t, r.MultipartForm.Value["title"][0] == "hello")
+		expectTrue(t, r.MultipartForm.File["file"][0].Size == 1<<10)
+		return nil
+	}))
+
+	body, contentType := multipartBody(t, map[string]string{"title": "hello", "file": strings.Repeat("x", 1<<10)})
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", contentType)
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), req) == nil)
+
+	// the body beyond MaxMemoryBytes was in a temporary file, removed after the handler.
+	expectTrue(t, len(spilled) == 1)
+	left, _ := os.ReadDir(tmp)
+	expectTrue(t, len(left) == 0)
+}
@@ -0,0 +1,64 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Normalizer converts a string to a Unicode normalization form.
+// The forms of golang.org/x/text/unicode/norm (norm.NFC, norm.NFKC, ...) satisfy this interface, so they can be
+// used directly without making this package depend on x/text.
+type Normalizer interface {
+	String(s string) string
+}
+
+// NormalizeUnicode normalizes the path parameters and the query parameters (both names and values) of the
+// request with the given form, before passing it to the next handler. This way identifiers that only differ by
+// their normalization, for example "é" written as a single code point or as "e" followed by a combining acute
+// accent, are seen as the same value by the handlers, which prevents duplicated or spoofed identifiers.
+//
+// The normalized path parameters are available via PathParams, and the normalized query via r.URL.Query.
+// The raw r.URL.Path is left untouched.
+func NormalizeUnicode(form Normalizer) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if params := PathParams(r); len(params) > 0 {
+				normalized := make(Params, len(params))
+				for i, p := range params {
+					normalized[i] = Param{Key: p.Key, Value: form.String(p.Value)}
+				}
+				r = r.WithContext(context.WithValue(r.Context(), httprouter.ParamsKey, normalized))
+			}
+
+			if r.URL.RawQuery != "" {
+				query, changed := normalizeQuery(form, r.URL.Query())
+				if changed {
+					u := *r.URL
+					u.RawQuery = query.Encode()
+					r = r.Clone(r.Context())
+					r.URL = &u
+				}
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// normalizeQuery normalizes the names and values of the query. It reports whether anything has changed.
+func normalizeQuery(form Normalizer, query url.Values) (url.Values, bool) {
+	var changed bool
+	normalized := make(url.Values, len(query))
+	for name, values := range query {
+		key := form.String(name)
+		changed = changed || key != name
+		for _, v := range values {
+			nv := form.String(v)
+			changed = changed || nv != v
+			normalized[key] = append(normalized[key], nv)
+		}
+	}
+	return normalized, changed
+}
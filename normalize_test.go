@@ -0,0 +1,49 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// composer is a tiny NFC stand-in that only composes "e" + U+0301 (combining acute accent) into U+00E9.
+type composer struct{}
+
+func (composer) String(s string) string { return strings.ReplaceAll(s, "e\u0301", "\u00e9") }
+
+func TestNormalizeUnicode(t *testing.T) {
+	var (
+		gotParam string
+		gotQuery url.Values
+	)
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "GET", Path: "/users/:name", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		gotParam = PathParams(r).ByName("name")
+		gotQuery = r.URL.Query()
+		return nil
+	}}, NormalizeUnicode(composer{}))
+
+	decomposed := "jose\u0301"
+	target := "/users/" + url.PathEscape(decomposed) + "?q=" + url.QueryEscape(decomposed) + "&caf" + url.QueryEscape("e\u0301") + "=1"
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", target, nil))
+
+	expectTrue(t, res.Code == 200)
+	expectTrue(t, gotParam == "jos\u00e9")
+	expectTrue(t, gotQuery.Get("q") == "jos\u00e9")
+	expectTrue(t, gotQuery.Get("caf\u00e9") == "1")
+	expectFalse(t, gotQuery.Has("cafe\u0301"))
+}
+
+func TestNormalizeUnicode_Unchanged(t *testing.T) {
+	h := NormalizeUnicode(composer{}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		expectTrue(t, r.URL.RawQuery == "b=2&a=1")
+		return nil
+	}))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?b=2&a=1", nil))
+	expectTrue(t, err == nil)
+}
@@ -0,0 +1,100 @@
+package httprouterx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// VersionFunc returns the current version of the resource targeted by the request, or an empty string if the
+// resource does not exist.
+type VersionFunc func(*http.Request) (string, error)
+
+// OCCConfig is the configuration for OptimisticConcurrency.
+type OCCConfig struct {
+	// Version provides the current version of the resource. Required.
+	Version VersionFunc
+
+	// Weak makes the ETags weak (W/"..."), for versions that identify the semantic content of the resource
+	// rather than its exact bytes. Weak ETags are compared with the weak comparison function.
+	Weak bool
+}
+
+// OptimisticConcurrency implements optimistic concurrency control with ETags, in one middleware:
+//
+//   - GET and HEAD responses carry the current version of the resource in the ETag header.
+//   - PUT, PATCH and DELETE requests must send the ETag back in the If-Match header. The request is rejected
+//     with 428 Precondition Required when the header is absent, and with 412 Precondition Failed when the
+//     resource has been modified since, so concurrent updates cannot silently overwrite each other.
+//
+// With strong ETags, the comparison follows RFC 7232 (a weak tag never matches). With Weak, the tags match if
+// their opaque parts are equal, whether they are weak or not. "*" matches any existing resource.
+//
+// It panics if cfg.Version is nil.
+func OptimisticConcurrency(cfg OCCConfig) Middleware {
+	if cfg.Version == nil {
+		panic("httprouterx: OptimisticConcurrency: nil VersionFunc")
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead:
+				version, err := cfg.Version(r)
+				if err != nil {
+					return err
+				}
+				if version != "" {
+					w.Header().Set("ETag", versionETag(version, cfg.Weak))
+				}
+				return next.ServeHTTP(w, r)
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				return next.ServeHTTP(w, r)
+			}
+
+			ifMatch := strings.Join(r.Header.Values("If-Match"), ",")
+			if strings.TrimSpace(ifMatch) == "" {
+				return NewHTTPError(http.StatusPreconditionRequired, "missing If-Match header")
+			}
+
+			version, err := cfg.Version(r)
+			if err != nil {
+				return err
+			}
+
+			var ok bool
+			if cfg.Weak {
+				ok = ifMatchWeakSatisfied(ifMatch, version)
+			} else {
+				ok = ifMatchSatisfied(ifMatch, version)
+			}
+			if !ok {
+				return NewHTTPError(http.StatusPreconditionFailed, "resource has been modified")
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// versionETag formats the version as an entity tag.
+func versionETag(version string, weak bool) string {
+	tag := quoteETag(strings.TrimPrefix(version, "W/"))
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// ifMatchWeakSatisfied is like ifMatchSatisfied, but it uses the weak comparison function.
+func ifMatchWeakSatisfied(ifMatch, current string) bool {
+	if current == "" {
+		return false
+	}
+	current = versionETag(current, false)
+	for _, tag := range parseETags(ifMatch) {
+		if tag == "*" || versionETag(tag, false) == current {
+			return true
+		}
+	}
+	return false
+}
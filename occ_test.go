@@ -0,0 +1,92 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestOptimisticConcurrency(t *testing.T) {
+	for _, weak := range []bool{false, true} {
+		t.Run("weak="+strconv.FormatBool(weak), func(t *testing.T) {
+			revision := 1
+			occ := OptimisticConcurrency(OCCConfig{
+				Version: func(r *http.Request) (string, error) { return "rev-" + strconv.Itoa(revision), nil },
+				Weak:    weak,
+			})
+
+			mux := NewServeMux()
+			mux.Route(Route{Method: "GET", Path: "/doc", Handler: func(w http.ResponseWriter, r *http.Request) error {
+				return nil
+			}}, occ)
+			mux.Route(Route{Method: "PUT", Path: "/doc", Handler: func(w http.ResponseWriter, r *http.Request) error {
+				revision++
+				w.WriteHeader(204)
+				return nil
+			}}, occ)
+
+			serve := func(method, ifMatch string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, "/doc", nil)
+				if ifMatch != "" {
+					req.Header.Set("If-Match", ifMatch)
+				}
+				res := httptest.NewRecorder()
+				mux.ServeHTTP(res, req)
+				return res
+			}
+
+			// read, then update with the ETag.
+			res := serve("GET", "")
+			etag := res.Header().Get("ETag")
+			if weak {
+				expectTrue(t, etag == `W/"rev-1"`)
+			} else {
+				expectTrue(t, etag == `"rev-1"`)
+			}
+			expectTrue(t, serve("PUT", etag).Code == 204)
+
+			// the same ETag is now stale.
+			expectTrue(t, serve("PUT", etag).Code == http.StatusPreconditionFailed)
+			expectTrue(t, serve("PUT", "").Code == http.StatusPreconditionRequired)
+
+			// read again to get the fresh ETag.
+			etag = serve("GET", "").Header().Get("ETag")
+			expectTrue(t, serve("PUT", etag).Code == 204)
+			expectTrue(t, serve("PUT", "*").Code == 204)
+		})
+	}
+}
+
+func TestOptimisticConcurrency_Comparison(t *testing.T) {
+	tests := []struct {
+		ifMatch string
+		weak    bool
+		ok      bool
+	}{
+		{ifMatch: `"v1"`, weak: false, ok: true},
+		{ifMatch: `W/"v1"`, weak: false, ok: false},
+		{ifMatch: `W/"v1"`, weak: true, ok: true},
+		{ifMatch: `"v1"`, weak: true, ok: true},
+		{ifMatch: `W/"v2", "v1"`, weak: true, ok: true},
+		{ifMatch: `W/"v2"`, weak: true, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ifMatch, func(t *testing.T) {
+			h := OptimisticConcurrency(OCCConfig{
+				Version: func(r *http.Request) (string, error) { return "v1", nil },
+				Weak:    tt.weak,
+			}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+			req := httptest.NewRequest("PATCH", "/", nil)
+			req.Header.Set("If-Match", tt.ifMatch)
+			err := h.ServeHTTP(httptest.NewRecorder(), req)
+			if tt.ok {
+				expectTrue(t, err == nil)
+			} else {
+				expectHTTPError(t, err, http.StatusPreconditionFailed)
+			}
+		})
+	}
+}
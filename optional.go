@@ -0,0 +1,70 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Optional is a JSON field that tells apart a field absent from the payload, a field explicitly set to null, and
+// a field set to a value. It is meant for PATCH payloads, where only the fields sent by the client must be applied:
+//
+//	type PatchUser struct {
+//		Name     Optional[string] `json:"name"`
+//		Nickname Optional[string] `json:"nickname"`
+//	}
+//
+//	var p PatchUser
+//	if err := DecodeJSON(r, &p, 0); err != nil {
+//		return err
+//	}
+//	if p.Name.Set && !p.Name.Null {
+//		user.Name = p.Name.Value
+//	}
+//	if p.Nickname.Set {
+//		user.Nickname = p.Nickname.Ptr() // nil clears it.
+//	}
+//
+// For an absent field, Set is false. For a null field, Set and Null are true. Otherwise, Set is true and Value
+// holds the decoded value. The default value of an absent field, given with the `default:"..."` tag, is stored
+// in Value, see DecodeJSON.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It is only called by encoding/json when the field is present.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		o.Value, o.Null = zero, true
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON implements json.Marshaler. An absent or null Optional is encoded as null.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// Ptr returns a pointer to a copy of the value, or nil if the Optional is absent or null.
+func (o Optional[T]) Ptr() *T {
+	if !o.Set || o.Null {
+		return nil
+	}
+	v := o.Value
+	return &v
+}
+
+// setDefault implements defaultSetter: the default of an absent Optional is stored in Value, while Set stays
+// false, so the handler can still tell that the client did not send the field.
+func (o *Optional[T]) setDefault(raw, layout string) error {
+	return setFieldValue(reflect.ValueOf(&o.Value).Elem(), raw, layout)
+}
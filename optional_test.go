@@ -0,0 +1,106 @@
+package httprouterx
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOptional(t *testing.T) {
+	type Address struct {
+		City Optional[string] `json:"city"`
+		Zip  Optional[string] `json:"zip"`
+	}
+	type Patch struct {
+		Name    Optional[string]  `json:"name"`
+		Age     Optional[int]     `json:"age"`
+		Address Optional[Address] `json:"address"`
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		check func(t *testing.T, p Patch)
+	}{
+		{
+			name: "absent",
+			body: `{}`,
+			check: func(t *testing.T, p Patch) {
+				expectFalse(t, p.Name.Set)
+				expectFalse(t, p.Age.Set)
+				expectFalse(t, p.Address.Set)
+				expectTrue(t, p.Name.Ptr() == nil)
+			},
+		},
+		{
+			name: "null",
+			body: `{"name": null, "age": null, "address": null}`,
+			check: func(t *testing.T, p Patch) {
+				expectTrue(t, p.Name.Set && p.Name.Null)
+				expectTrue(t, p.Age.Set && p.Age.Null)
+				expectTrue(t, p.Address.Set && p.Address.Null)
+				expectTrue(t, p.Name.Ptr() == nil)
+			},
+		},
+		{
+			name: "value",
+			body: `{"name": "gopher", "age": 0}`,
+			check: func(t *testing.T, p Patch) {
+				expectTrue(t, p.Name.Set && !p.Name.Null && p.Name.Value == "gopher")
+				expectTrue(t, p.Age.Set && !p.Age.Null && p.Age.Value == 0)
+				expectTrue(t, *p.Name.Ptr() == "gopher")
+				expectFalse(t, p.Address.Set)
+			},
+		},
+		{
+			name: "nested",
+			body: `{"address": {"city": "Jakarta", "zip": null}}`,
+			check: func(t *testing.T, p Patch) {
+				expectTrue(t, p.Address.Set && !p.Address.Null)
+				expectTrue(t, p.Address.Value.City.Set && p.Address.Value.City.Value == "Jakarta")
+				expectTrue(t, p.Address.Value.Zip.Set && p.Address.Value.Zip.Null)
+			},
+		},
+		{
+			name: "nested absent",
+			body: `{"address": {}}`,
+			check: func(t *testing.T, p Patch) {
+				expectTrue(t, p.Address.Set && !p.Address.Null)
+				expectFalse(t, p.Address.Value.City.Set)
+				expectFalse(t, p.Address.Value.Zip.Set)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Patch
+			err := DecodeJSON(httptest.NewRequest("PATCH", "/", strings.NewReader(tt.body)), &p, 0)
+			expectTrue(t, err == nil)
+			tt.check(t, p)
+		})
+	}
+}
+
+func TestOptional_InvalidValue(t *testing.T) {
+	var p struct {
+		Age Optional[int] `json:"age"`
+	}
+	err := DecodeJSON(httptest.NewRequest("PATCH", "/", strings.NewReader(`{"age": "old"}`)), &p, 0)
+	expectHTTPError(t, err, 400)
+}
+
+func TestOptional_MarshalJSON(t *testing.T) {
+	v := struct {
+		A Optional[string] `json:"a"`
+		B Optional[string] `json:"b"`
+		C Optional[string] `json:"c"`
+	}{
+		B: Optional[string]{Set: true, Null: true},
+		C: Optional[string]{Set: true, Value: "c"},
+	}
+	b, err := json.Marshal(v)
+	expectTrue(t, err == nil)
+	expectTrue(t, string(b) == `{"a":null,"b":null,"c":"c"}`)
+}
@@ -0,0 +1,151 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+)
+
+// maxPanicBodySnapshot is the maximum number of request body bytes kept by PanicLogger.
+const maxPanicBodySnapshot = 4 << 10
+
+// PanicLogger recovers panics from the next handlers and logs them as a single structured error record with
+// the stack trace, the method, the path, the matched route, the request ID (see RequestID), the client IP,
+// and a redacted snapshot of the request body. The panic is then converted to a 500 *HTTPError, so it flows
+// through the pipeline like any other error, wrapping a *PanicError.
+//
+// The body snapshot is made of the first 4 KiB read by the handler, the body is not read in advance. For JSON and
+// form bodies, the values of the fields listed in DefaultAuditRedactedParams are replaced by "REDACTED". Other
+// bodies, and JSON bodies that cannot be parsed, are only described by their size.
+//
+// A panic with http.ErrAbortHandler is re-panicked, since it is used to abort the response on purpose.
+func PanicLogger(logger *slog.Logger) Middleware {
+	redacted := make(map[string]bool, len(DefaultAuditRedactedParams))
+	for _, p := range DefaultAuditRedactedParams {
+		redacted[p] = true
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
+			var snapshot *snapshotReader
+			if r.Body != nil && r.Body != http.NoBody {
+				snapshot = &snapshotReader{ReadCloser: r.Body, limit: maxPanicBodySnapshot}
+				r.Body = snapshot
+			}
+
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				stack := debug.Stack()
+				route, _ := CurrentRoute(r)
+				var body string
+				if snapshot != nil {
+					body = redactBody(r.Header.Get("Content-Type"), snapshot, redacted)
+				}
+				logger.LogAttrs(r.Context(), slog.LevelError, "panic recovered",
+					slog.Any("panic", v),
+					slog.String("stack", string(stack)),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("route", route.Path),
+					slog.String("route_name", route.Name),
+					slog.String("request_id", requestIDOf(r)),
+					slog.String("client_ip", clientIP(r)),
+					slog.String("body", body),
+				)
+				err = &HTTPError{
+					Status:  http.StatusInternalServerError,
+					Message: http.StatusText(http.StatusInternalServerError),
+					Err:     &PanicError{Value: v, Stack: stack},
+				}
+			}()
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// snapshotReader keeps a copy of the first limit bytes read from the underlying body.
+type snapshotReader struct {
+	io.ReadCloser
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+	size      int64
+}
+
+func (s *snapshotReader) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.size += int64(n)
+	if room := s.limit - s.buf.Len(); room > 0 {
+		s.buf.Write(p[:min(n, room)])
+	}
+	if s.size > int64(s.limit) {
+		s.truncated = true
+	}
+	return n, err
+}
+
+// redactBody renders the body snapshot with the values of the redacted fields replaced.
+func redactBody(contentType string, s *snapshotReader, redacted map[string]bool) string {
+	if s.size == 0 {
+		return ""
+	}
+	omitted := fmt.Sprintf("[%d bytes omitted]", s.size)
+	if s.truncated {
+		return omitted
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case isJSONContentType(contentType):
+		var doc any
+		if err := json.Unmarshal(s.buf.Bytes(), &doc); err != nil {
+			return omitted
+		}
+		b, err := json.Marshal(redactJSON(doc, redacted))
+		if err != nil {
+			return omitted
+		}
+		return string(b)
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(s.buf.String())
+		if err != nil {
+			return omitted
+		}
+		return redactQuery(form, redacted)
+	default:
+		return omitted
+	}
+}
+
+// redactJSON replaces the values of the redacted object keys, at any depth.
+func redactJSON(v any, redacted map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if redacted[strings.ToLower(k)] {
+				v[k] = "REDACTED"
+			} else {
+				v[k] = redactJSON(child, redacted)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redactJSON(child, redacted)
+		}
+	}
+	return v
+}
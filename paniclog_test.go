@@ -0,0 +1,102 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanicLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "POST", Path: "/users/:id", Name: "users.update", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = io.ReadAll(r.Body)
+		panic("boom")
+	}}, PanicLogger(logger))
+
+	req := httptest.NewRequest("POST", "/users/42", strings.NewReader(`{"name":"gopher","password":"hunter2","nested":{"Token":"abc"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "req-1")
+	req.RemoteAddr = "10.0.0.1:1234"
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	expectTrue(t, res.Code == http.StatusInternalServerError)
+
+	var record map[string]any
+	expectTrue(t, json.Unmarshal(logs.Bytes(), &record) == nil)
+	expectTrue(t, record["level"] == "ERROR")
+	expectTrue(t, record["msg"] == "panic recovered")
+	expectTrue(t, record["panic"] == "boom")
+	expectTrue(t, strings.Contains(record["stack"].(string), "TestPanicLogger"))
+	expectTrue(t, record["method"] == "POST")
+	expectTrue(t, record["path"] == "/users/42")
+	expectTrue(t, record["route"] == "/users/:id")
+	expectTrue(t, record["route_name"] == "users.update")
+	expectTrue(t, record["request_id"] == "req-1")
+	expectTrue(t, record["client_ip"] == "10.0.0.1")
+	expectTrue(t, record["body"] == `{"name":"gopher","nested":{"Token":"REDACTED"},"password":"REDACTED"}`)
+}
+
+func TestPanicLogger_Body(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: "user=gopher&secret=s3", want: "secret=REDACTED&user=gopher"},
+		{name: "binary", contentType: "application/octet-stream", body: "abc", want: "[3 bytes omitted]"},
+		{name: "invalid json", contentType: "application/json", body: `{"password":`, want: "[12 bytes omitted]"},
+		{name: "too large", contentType: "application/json", body: strings.Repeat("x", maxPanicBodySnapshot+1), want: "[4097 bytes omitted]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			h := PanicLogger(slog.New(slog.NewJSONHandler(&logs, nil))).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				_, _ = io.ReadAll(r.Body)
+				panic("boom")
+			}))
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			err := h.ServeHTTP(httptest.NewRecorder(), req)
+			expectHTTPError(t, err, http.StatusInternalServerError)
+
+			var record map[string]any
+			expectTrue(t, json.Unmarshal(logs.Bytes(), &record) == nil)
+			expectTrue(t, record["body"] == tt.want)
+		})
+	}
+}
+
+func TestPanicLogger_NoPanic(t *testing.T) {
+	var logs bytes.Buffer
+	anError := errors.New("an error")
+	h := PanicLogger(slog.New(slog.NewJSONHandler(&logs, nil))).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return anError
+	}))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, errors.Is(err, anError))
+	expectTrue(t, logs.Len() == 0)
+}
+
+func TestPanicLogger_AbortHandler(t *testing.T) {
+	h := PanicLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() { expectTrue(t, recover() == http.ErrAbortHandler) }()
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	t.Fatal("expected panic")
+}
@@ -0,0 +1,150 @@
+package httprouterx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingParam is the error of the path param helpers, such as RequirePathParam and PathParamInt, when the
+// matched route has no param of the given name, which usually means a typo in the name or in the route path.
+var ErrMissingParam = errors.New("missing path parameter")
+
+// RequirePathParam gets the named path param. Unlike PathParams(r).ByName, it returns an error wrapping
+// ErrMissingParam when the matched route has no such param, instead of an empty string, so a misconfigured route
+// fails loudly. The LastResortErrorHandler renders the error as 500. An empty value of an existing param, such as
+// the catch-all param of /files/*path requested as /files/, is returned as is.
+func RequirePathParam(r *http.Request, name string) (string, error) {
+	value, ok := lookupPathParam(r, name)
+	if !ok {
+		return "", missingPathParam(name)
+	}
+	return value, nil
+}
+
+// PathParamInt gets the named path param as an int.
+//
+// If the route has no such param, it returns an error wrapping ErrMissingParam, that the LastResortErrorHandler
+// renders as 500, since it is a misconfigured route rather than a bad request. If the value is not a valid
+// base-10 integer, or it is out of range, it returns an *HTTPError with status 400 whose message names the param
+// and quotes the value, so the error can be returned from the handler as is.
+func PathParamInt(r *http.Request, name string) (int, error) {
+	return parsePathParam(r, name, "an integer", func(s string) (int, error) { return strconv.Atoi(s) })
+}
+
+// PathParamInt64 is like PathParamInt, but for int64 values.
+func PathParamInt64(r *http.Request, name string) (int64, error) {
+	return parsePathParam(r, name, "an integer", func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+}
+
+// PathParamUint is like PathParamInt, but for uint values. Negative values are rejected with 400.
+func PathParamUint(r *http.Request, name string) (uint, error) {
+	return parsePathParam(r, name, "a non-negative integer", func(s string) (uint, error) {
+		n, err := strconv.ParseUint(s, 10, 0)
+		return uint(n), err
+	})
+}
+
+// parsePathParam gets the named path param and parses it with parse. want describes the expected value.
+func parsePathParam[T any](r *http.Request, name, want string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	value, ok := lookupPathParam(r, name)
+	if !ok {
+		return zero, missingPathParam(name)
+	}
+
+	v, err := parse(value)
+	if err != nil {
+		msg := fmt.Sprintf("path parameter %q must be %s, got %q", name, want, value)
+		if errors.Is(err, strconv.ErrRange) {
+			msg = fmt.Sprintf("path parameter %q is out of range, got %q", name, value)
+		}
+		return zero, &HTTPError{Status: http.StatusBadRequest, Message: msg, Err: err}
+	}
+	return v, nil
+}
+
+// missingPathParam is the error for a path param that the matched route does not have.
+func missingPathParam(name string) error {
+	return fmt.Errorf("httprouterx: %w %q", ErrMissingParam, name)
+}
+
+// lookupPathParam gets the named path param, and whether the matched route has it.
+func lookupPathParam(r *http.Request, name string) (string, bool) {
+	for _, p := range PathParams(r) {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// EnumParam rejects, with 400 Bad Request, the requests whose named path param is not one of the allowed values,
+// so the handler can assume a valid value. The error message lists the allowed values. Like PathParamInt, it
+// fails with 500 if the route has no such param.
+func EnumParam(name string, allowed ...string) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			value, ok := lookupPathParam(r, name)
+			if !ok {
+				return missingPathParam(name)
+			}
+			if _, err := checkEnum("path parameter", name, value, allowed); err != nil {
+				return err
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// EnumQuery is like EnumParam, but for a query param. The param is optional: requests without it are accepted,
+// but every value of a repeated param must be allowed.
+func EnumQuery(name string, allowed ...string) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			for _, value := range r.URL.Query()[name] {
+				if _, err := checkEnum("query parameter", name, value, allowed); err != nil {
+					return err
+				}
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ParseEnum gets the named param as one of the allowed values: the path param if the route has it, otherwise the
+// first value of the query param. It returns an *HTTPError with status 400, listing the allowed values, if the
+// param is absent or not allowed.
+func ParseEnum[T ~string](r *http.Request, name string, allowed ...T) (T, error) {
+	kind := "path parameter"
+	value, ok := lookupPathParam(r, name)
+	if !ok {
+		kind = "query parameter"
+		value, ok = r.URL.Query().Get(name), r.URL.Query().Has(name)
+	}
+
+	values := make([]string, len(allowed))
+	for i, v := range allowed {
+		values[i] = string(v)
+	}
+	if !ok {
+		return "", NewHTTPError(http.StatusBadRequest, fmt.Sprintf("parameter %q is required, must be one of: %s", name, strings.Join(values, ", ")))
+	}
+	i, err := checkEnum(kind, name, value, values)
+	if err != nil {
+		return "", err
+	}
+	return allowed[i], nil
+}
+
+// checkEnum returns the index of the value in allowed, or an *HTTPError with status 400 listing them.
+func checkEnum(kind, name, value string, allowed []string) (int, error) {
+	for i, v := range allowed {
+		if v == value {
+			return i, nil
+		}
+	}
+	return -1, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s %q must be one of: %s, got %q", kind, name, strings.Join(allowed, ", "), value))
+}
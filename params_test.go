@@ -0,0 +1,164 @@
+package httprouterx
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPathParamInt(t *testing.T) {
+	r := WithPathParams(httptest.NewRequest("GET", "/", nil),
+		Param{Key: "id", Value: "42"},
+		Param{Key: "neg", Value: "-7"},
+		Param{Key: "bad", Value: "abc"},
+		Param{Key: "huge", Value: "99999999999999999999"},
+	)
+
+	n, err := PathParamInt(r, "id")
+	expectTrue(t, err == nil)
+	expectTrue(t, n == 42)
+
+	n, err = PathParamInt(r, "neg")
+	expectTrue(t, err == nil)
+	expectTrue(t, n == -7)
+
+	httpErr := expectHTTPError(t, func() error { _, err := PathParamInt(r, "bad"); return err }(), http.StatusBadRequest)
+	expectTrue(t, strings.Contains(httpErr.Message, `"bad"`))
+	expectTrue(t, strings.Contains(httpErr.Message, `"abc"`))
+
+	httpErr = expectHTTPError(t, func() error { _, err := PathParamInt64(r, "huge"); return err }(), http.StatusBadRequest)
+	expectTrue(t, errors.Is(httpErr, strconv.ErrRange))
+	expectTrue(t, strings.Contains(httpErr.Message, "out of range"))
+
+	_, err = PathParamInt(r, "missing")
+	var target *HTTPError
+	expectTrue(t, err != nil)
+	expectFalse(t, errors.As(err, &target))
+	expectTrue(t, strings.Contains(err.Error(), `"missing"`))
+	expectTrue(t, errors.Is(err, ErrMissingParam))
+}
+
+func TestRequirePathParam(t *testing.T) {
+	r := WithPathParams(httptest.NewRequest("GET", "/", nil), Param{Key: "id", Value: "42"}, Param{Key: "path", Value: ""})
+
+	v, err := RequirePathParam(r, "id")
+	expectTrue(t, err == nil && v == "42")
+
+	v, err = RequirePathParam(r, "path")
+	expectTrue(t, err == nil && v == "")
+
+	_, err = RequirePathParam(r, "ID")
+	expectTrue(t, errors.Is(err, ErrMissingParam))
+	expectTrue(t, strings.Contains(err.Error(), `"ID"`))
+
+	// through the mux, a misconfigured route fails with 500.
+	mux := NewServeMux()
+	mux.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		_, err := RequirePathParam(r, "user_id")
+		return err
+	})
+	expectTrue(t, mux.TestRequest("GET", "/users/1", nil).Code == http.StatusInternalServerError)
+}
+
+func TestPathParamInt64(t *testing.T) {
+	r := WithPathParams(httptest.NewRequest("GET", "/", nil), Param{Key: "id", Value: strconv.FormatInt(math.MaxInt64, 10)})
+
+	n, err := PathParamInt64(r, "id")
+	expectTrue(t, err == nil)
+	expectTrue(t, n == math.MaxInt64)
+}
+
+func TestPathParamUint(t *testing.T) {
+	r := WithPathParams(httptest.NewRequest("GET", "/", nil), Param{Key: "id", Value: "7"}, Param{Key: "neg", Value: "-1"})
+
+	n, err := PathParamUint(r, "id")
+	expectTrue(t, err == nil)
+	expectTrue(t, n == 7)
+
+	httpErr := expectHTTPError(t, func() error { _, err := PathParamUint(r, "neg"); return err }(), http.StatusBadRequest)
+	expectTrue(t, strings.Contains(httpErr.Message, "non-negative"))
+}
+
+func TestPathParamInt_Route(t *testing.T) {
+	mux := NewServeMux()
+	mux.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		id, err := PathParamInt(r, "id")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(strconv.Itoa(id * 2)))
+		return err
+	})
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/users/21", nil))
+	expectTrue(t, res.Code == 200)
+	expectTrue(t, res.Body.String() == "42")
+
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/users/x", nil))
+	expectTrue(t, res.Code == 400)
+}
+
+func TestEnumParam(t *testing.T) {
+	mux := NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	mux.Route(Route{Method: "GET", Path: "/orders/:status", Handler: ok}, EnumParam("status", "open", "closed"))
+	mux.Route(Route{Method: "GET", Path: "/orders", Handler: ok}, EnumQuery("sort", "asc", "desc"))
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{target: "/orders/open", status: 200},
+		{target: "/orders/closed", status: 200},
+		{target: "/orders/OPEN", status: 400},
+		{target: "/orders/pending", status: 400},
+		{target: "/orders", status: 200},
+		{target: "/orders?sort=asc", status: 200},
+		{target: "/orders?sort=asc&sort=desc", status: 200},
+		{target: "/orders?sort=asc&sort=random", status: 400},
+		{target: "/orders?sort=", status: 400},
+	}
+
+	for _, tt := range tests {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("GET", tt.target, nil))
+		expectTrue(t, res.Code == tt.status)
+	}
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/orders/pending", nil))
+	expectTrue(t, strings.Contains(res.Body.String(), `path parameter "status" must be one of: open, closed, got "pending"`))
+}
+
+func TestParseEnum(t *testing.T) {
+	type status string
+	const (
+		open   status = "open"
+		closed status = "closed"
+	)
+
+	r := WithPathParams(httptest.NewRequest("GET", "/?status=closed&kind=x", nil), Param{Key: "status", Value: "open"})
+	got, err := ParseEnum(r, "status", open, closed)
+	expectTrue(t, err == nil)
+	expectTrue(t, got == open)
+
+	r = httptest.NewRequest("GET", "/?status=closed", nil)
+	got, err = ParseEnum(r, "status", open, closed)
+	expectTrue(t, err == nil)
+	expectTrue(t, got == closed)
+
+	r = httptest.NewRequest("GET", "/?status=pending", nil)
+	_, err = ParseEnum(r, "status", open, closed)
+	httpErr := expectHTTPError(t, err, http.StatusBadRequest)
+	expectTrue(t, strings.Contains(httpErr.Message, "open, closed"))
+
+	_, err = ParseEnum(httptest.NewRequest("GET", "/", nil), "status", open, closed)
+	httpErr = expectHTTPError(t, err, http.StatusBadRequest)
+	expectTrue(t, strings.Contains(httpErr.Message, "required"))
+}
@@ -0,0 +1,104 @@
+package httprouterx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RequireIfMatch enforces optimistic concurrency for PUT, PATCH and DELETE requests.
+// The request must carry an If-Match header, which is compared against the current ETag of the
+// resource returned by currentETag. It responds with 428 Precondition Required when the header is
+// absent, and 412 Precondition Failed when none of the given entity tags matches.
+//
+// The comparison follows RFC 7232: weak entity tags never match, and "*" matches any existing
+// resource (a non-empty current ETag). The current ETag may be returned quoted or unquoted.
+func RequireIfMatch(currentETag func(*http.Request) (string, error)) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				return next.ServeHTTP(w, r)
+			}
+
+			ifMatch := strings.Join(r.Header.Values("If-Match"), ",")
+			if strings.TrimSpace(ifMatch) == "" {
+				return NewHTTPError(http.StatusPreconditionRequired, "missing If-Match header")
+			}
+
+			current, err := currentETag(r)
+			if err != nil {
+				return err
+			}
+
+			if !ifMatchSatisfied(ifMatch, current) {
+				return NewHTTPError(http.StatusPreconditionFailed, "resource has been modified")
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ifMatchSatisfied reports whether the If-Match header value matches the current ETag. A "*" matches any
+// current ETag, even a weak one; the listed tags use the strong comparison function.
+func ifMatchSatisfied(ifMatch, current string) bool {
+	if current == "" {
+		return false
+	}
+	tags := parseETags(ifMatch)
+	for _, tag := range tags {
+		if tag == "*" {
+			return true
+		}
+	}
+	current = quoteETag(current)
+	if strings.HasPrefix(current, "W/") {
+		return false
+	}
+	for _, tag := range tags {
+		if tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteETag returns the entity tag in its quoted form, keeping the weak prefix if present.
+func quoteETag(tag string) string {
+	weak := strings.HasPrefix(tag, "W/")
+	opaque := strings.TrimPrefix(tag, "W/")
+	if !strings.HasPrefix(opaque, `"`) || !strings.HasSuffix(opaque, `"`) || len(opaque) < 2 {
+		opaque = `"` + opaque + `"`
+	}
+	if weak {
+		return "W/" + opaque
+	}
+	return opaque
+}
+
+// parseETags splits a comma-separated list of entity tags. Commas inside quotes are preserved,
+// since they are valid entity tag characters.
+func parseETags(s string) []string {
+	var (
+		tags   []string
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				if tag := strings.TrimSpace(s[start:i]); tag != "" {
+					tags = append(tags, tag)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if tag := strings.TrimSpace(s[start:]); tag != "" {
+		tags = append(tags, tag)
+	}
+	return tags
+}
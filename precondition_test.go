@@ -0,0 +1,84 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireIfMatch(t *testing.T) {
+	current := func(r *http.Request) (string, error) { return `"v2"`, nil }
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "PUT", Path: "/data", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(204)
+		return nil
+	}}, RequireIfMatch(current))
+
+	mux.Route(Route{Method: "GET", Path: "/data", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(200)
+		return nil
+	}}, RequireIfMatch(current))
+
+	tests := []struct {
+		name    string
+		method  string
+		ifMatch string
+		status  int
+	}{
+		{name: "match", method: "PUT", ifMatch: `"v2"`, status: 204},
+		{name: "match in list", method: "PUT", ifMatch: `"v1", "v2"`, status: 204},
+		{name: "wildcard", method: "PUT", ifMatch: `*`, status: 204},
+		{name: "mismatch", method: "PUT", ifMatch: `"v1"`, status: http.StatusPreconditionFailed},
+		{name: "weak never matches", method: "PUT", ifMatch: `W/"v2"`, status: http.StatusPreconditionFailed},
+		{name: "missing header", method: "PUT", status: http.StatusPreconditionRequired},
+		{name: "safe method is not checked", method: "GET", status: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/data", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			mux.ServeHTTP(res, req)
+			expectTrue(t, res.Code == tt.status)
+		})
+	}
+}
+
+func TestRequireIfMatch_WeakCurrent(t *testing.T) {
+	h := RequireIfMatch(func(r *http.Request) (string, error) { return `W/"v2"`, nil }).
+		Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	serve := func(ifMatch string) error {
+		req := httptest.NewRequest("PUT", "/data", nil)
+		req.Header.Set("If-Match", ifMatch)
+		return h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expectTrue(t, serve(`*`) == nil)
+	expectHTTPError(t, serve(`W/"v2"`), http.StatusPreconditionFailed)
+	expectHTTPError(t, serve(`"v2"`), http.StatusPreconditionFailed)
+}
+
+func TestRequireIfMatch_CurrentETagError(t *testing.T) {
+	anError := errors.New("lookup failed")
+	h := RequireIfMatch(func(r *http.Request) (string, error) { return "", anError }).
+		Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	req := httptest.NewRequest("DELETE", "/data", nil)
+	req.Header.Set("If-Match", `"v1"`)
+	err := h.ServeHTTP(httptest.NewRecorder(), req)
+	expectTrue(t, errors.Is(err, anError))
+}
+
+func TestParseETags(t *testing.T) {
+	tags := parseETags(`"a,b", W/"c" ,, "d"`)
+	expectTrue(t, len(tags) == 3)
+	expectTrue(t, tags[0] == `"a,b"`)
+	expectTrue(t, tags[1] == `W/"c"`)
+	expectTrue(t, tags[2] == `"d"`)
+}
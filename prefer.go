@@ -0,0 +1,108 @@
+package httprouterx
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preferences are the preferences of the Prefer header, as defined by RFC 7240.
+//
+// The registered preferences are parsed into their own fields:
+//   - return=representation|minimal: Return.
+//   - respond-async: RespondAsync.
+//   - wait=<seconds>: Wait.
+//   - handling=strict|lenient: Handling.
+//
+// All preferences, including the unregistered ones, are also available in Values, keyed by the lower-cased
+// token. Preference parameters are ignored.
+type Preferences struct {
+	Return       string
+	RespondAsync bool
+	Wait         time.Duration
+	Handling     string
+	Values       map[string]string
+}
+
+// Has reports whether the preference token was sent.
+func (p Preferences) Has(token string) bool {
+	_, ok := p.Values[strings.ToLower(token)]
+	return ok
+}
+
+// ParsePrefer parses the Prefer headers of the request. When a preference is given more than once,
+// the first one wins, as required by RFC 7240.
+func ParsePrefer(r *http.Request) Preferences {
+	var prefs Preferences
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range splitQuoted(header, ',') {
+			params := splitQuoted(pref, ';')
+			if len(params) == 0 {
+				continue
+			}
+			token, value, _ := strings.Cut(params[0], "=")
+			token = strings.ToLower(strings.TrimSpace(token))
+			value = unquote(strings.TrimSpace(value))
+			if token == "" {
+				continue
+			}
+			if _, seen := prefs.Values[token]; seen {
+				continue
+			}
+			if prefs.Values == nil {
+				prefs.Values = make(map[string]string)
+			}
+			prefs.Values[token] = value
+
+			switch token {
+			case "return":
+				prefs.Return = strings.ToLower(value)
+			case "respond-async":
+				prefs.RespondAsync = true
+			case "wait":
+				if secs, err := strconv.ParseUint(value, 10, 32); err == nil {
+					prefs.Wait = time.Duration(secs) * time.Second
+				}
+			case "handling":
+				prefs.Handling = strings.ToLower(value)
+			}
+		}
+	}
+	return prefs
+}
+
+// ApplyPrefer parses the Prefer header once, and makes it available to handlers via RequestPreferences.
+// Since the response may vary on the preferences, it also adds Prefer to the Vary header.
+//
+// Handlers decide which preferences they honor, and report them with PreferenceApplied, e.g.:
+//
+//	if RequestPreferences(r).Return == "minimal" {
+//		PreferenceApplied(w, "return=minimal")
+//		w.WriteHeader(http.StatusNoContent)
+//		return nil
+//	}
+func ApplyPrefer() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("Vary", "Prefer")
+			prefs := ParsePrefer(r)
+			return next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), preferencesKey, prefs)))
+		})
+	}
+}
+
+// RequestPreferences gets the preferences parsed by ApplyPrefer, or parses them if ApplyPrefer is not used.
+func RequestPreferences(r *http.Request) Preferences {
+	if prefs, ok := r.Context().Value(preferencesKey).(Preferences); ok {
+		return prefs
+	}
+	return ParsePrefer(r)
+}
+
+// PreferenceApplied echoes the honored preference in the Preference-Applied header.
+// It must be called before the response header is written.
+func PreferenceApplied(w http.ResponseWriter, pref string) {
+	w.Header().Add("Preference-Applied", pref)
+}
@@ -0,0 +1,60 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParsePrefer(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Prefer", `return=minimal; foo="bar", respond-async, wait=10`)
+	req.Header.Add("Prefer", `handling=Lenient, return=representation, x-custom="a, b"`)
+
+	prefs := ParsePrefer(req)
+	expectTrue(t, prefs.Return == "minimal")
+	expectTrue(t, prefs.RespondAsync)
+	expectTrue(t, prefs.Wait == 10*time.Second)
+	expectTrue(t, prefs.Handling == "lenient")
+	expectTrue(t, prefs.Has("x-custom"))
+	expectTrue(t, prefs.Values["x-custom"] == "a, b")
+	expectFalse(t, prefs.Has("foo"))
+	expectTrue(t, len(prefs.Values) == 5)
+
+	empty := ParsePrefer(httptest.NewRequest("GET", "/", nil))
+	expectFalse(t, empty.RespondAsync)
+	expectTrue(t, empty.Return == "")
+}
+
+func TestApplyPrefer(t *testing.T) {
+	mux := NewServeMux(Options.Middleware(ApplyPrefer()))
+	mux.Route(Route{Method: "POST", Path: "/data", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		if RequestPreferences(r).Return == "minimal" {
+			PreferenceApplied(w, "return=minimal")
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, err := io.WriteString(w, `{"id":1}`)
+		return err
+	}})
+
+	t.Run("minimal", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/data", nil)
+		req.Header.Set("Prefer", "return=minimal")
+		mux.ServeHTTP(res, req)
+		expectTrue(t, res.Code == http.StatusNoContent)
+		expectTrue(t, res.Header().Get("Preference-Applied") == "return=minimal")
+		expectTrue(t, res.Header().Get("Vary") == "Prefer")
+	})
+
+	t.Run("representation", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("POST", "/data", nil))
+		expectTrue(t, res.Code == http.StatusCreated)
+		expectTrue(t, res.Header().Get("Preference-Applied") == "")
+	})
+}
@@ -0,0 +1,52 @@
+package httprouterx
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Priority implements basic quality of service by shedding low-priority traffic first when the server is busy.
+// classify assigns a priority class to each request, and maxConcurrentByClass gives, for each class, the total
+// number of in-flight requests (of any class) above which the requests of that class are rejected with
+// 503 Service Unavailable.
+//
+// Giving higher limits to higher classes reserves the slots in between for them. For example, with
+// {0: 50, 1: 80, 2: 100}, class 0 (e.g. batch jobs) is shed once 50 requests are in flight, class 1 once 80 are,
+// and the last 20 slots are reserved for class 2 (e.g. paying customers or health checks).
+//
+// Requests of a class that is not in the map get the lowest limit. The accounting is safe for concurrent use.
+func Priority(classify func(*http.Request) int, maxConcurrentByClass map[int]int) Middleware {
+	limits := make(map[int]int64, len(maxConcurrentByClass))
+	lowest := int64(-1)
+	for class, limit := range maxConcurrentByClass {
+		limits[class] = int64(limit)
+		if lowest < 0 || int64(limit) < lowest {
+			lowest = int64(limit)
+		}
+	}
+	if lowest < 0 {
+		lowest = 0
+	}
+
+	var inFlight atomic.Int64
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			limit, ok := limits[classify(r)]
+			if !ok {
+				limit = lowest
+			}
+
+			for {
+				n := inFlight.Load()
+				if n >= limit {
+					return NewHTTPError(http.StatusServiceUnavailable, "server is busy, try again later")
+				}
+				if inFlight.CompareAndSwap(n, n+1) {
+					break
+				}
+			}
+			defer inFlight.Add(-1)
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
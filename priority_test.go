@@ -0,0 +1,65 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestPriority(t *testing.T) {
+	classify := func(r *http.Request) int {
+		class, _ := strconv.Atoi(r.Header.Get("X-Class"))
+		return class
+	}
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	h := Priority(classify, map[int]int{0: 1, 1: 2, 2: 3}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Header.Get("X-Block") != "" {
+			entered <- struct{}{}
+			<-release
+		}
+		return nil
+	}))
+
+	serve := func(class string, block bool) error {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Class", class)
+		if block {
+			req.Header.Set("X-Block", "1")
+		}
+		return h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var wg sync.WaitGroup
+	block := func(class string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = serve(class, true)
+		}()
+		<-entered
+	}
+
+	// 1 in flight: low priority is shed, others pass.
+	block("2")
+	expectHTTPError(t, serve("0", false), 503)
+	expectHTTPError(t, serve("9", false), 503)
+	expectTrue(t, serve("1", false) == nil)
+	expectTrue(t, serve("2", false) == nil)
+
+	// 2 in flight: only the highest class gets the reserved slot.
+	block("1")
+	expectHTTPError(t, serve("1", false), 503)
+	expectTrue(t, serve("2", false) == nil)
+
+	// 3 in flight: everything is shed.
+	block("2")
+	expectHTTPError(t, serve("2", false), 503)
+
+	close(release)
+	wg.Wait()
+	expectTrue(t, serve("0", false) == nil)
+}
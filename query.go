@@ -0,0 +1,76 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxQueryParams is the limit used by MaxQueryParams when the given limit is not positive.
+const DefaultMaxQueryParams = 1000
+
+// MaxQueryParams rejects requests with more than max query parameters with 400 Bad Request. Repeated keys are
+// counted once per occurrence, so ?a=1&a=2 counts as 2. The raw query is counted without being parsed, which
+// protects the handlers from query strings that would produce huge maps when calling r.URL.Query().
+//
+// If max is not positive, DefaultMaxQueryParams is used.
+func MaxQueryParams(max int) Middleware {
+	if max <= 0 {
+		max = DefaultMaxQueryParams
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if countQueryParams(r.URL.RawQuery, max) > max {
+				return NewHTTPError(http.StatusBadRequest, "too many query parameters, maximum is "+strconv.Itoa(max))
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// countQueryParams counts the non-empty parameters of the raw query, stopping as soon as the limit is exceeded.
+func countQueryParams(rawQuery string, limit int) int {
+	n := 0
+	for rawQuery != "" && n <= limit {
+		var param string
+		param, rawQuery, _ = strings.Cut(rawQuery, "&")
+		if param != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// CanonicalQuery returns the query of the request in a canonical form: keys sorted, repeated values sorted, and
+// everything re-encoded the same way (e.g. "%7E" and "~", or "+" and "%20", are equivalent). Logically identical
+// requests get the same canonical query whatever the order of their parameters, which makes it suitable for
+// cache keys, deduplication keys and signatures.
+func CanonicalQuery(r *http.Request) string {
+	return canonicalQuery(r.URL.Query())
+}
+
+// canonicalQuery encodes the query with sorted keys and sorted values.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(v))
+		}
+	}
+	return b.String()
+}
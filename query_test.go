@@ -0,0 +1,68 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxQueryParams(t *testing.T) {
+	h := MaxQueryParams(3).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	tests := []struct {
+		target string
+		ok     bool
+	}{
+		{target: "/", ok: true},
+		{target: "/?a=1&b=2&c=3", ok: true},
+		{target: "/?a=1&&b=2&c=3&", ok: true},
+		{target: "/?a=1&a=2&a=3&a=4", ok: false},
+		{target: "/?" + strings.Repeat("a=1&", 100000), ok: false},
+	}
+
+	for _, tt := range tests {
+		err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.target, nil))
+		if tt.ok {
+			expectTrue(t, err == nil)
+			continue
+		}
+		var httpErr *HTTPError
+		expectTrue(t, errors.As(err, &httpErr))
+		expectTrue(t, httpErr.Status == 400)
+	}
+}
+
+func TestMaxQueryParams_Default(t *testing.T) {
+	h := MaxQueryParams(0).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?"+strings.Repeat("a=1&", DefaultMaxQueryParams), nil))
+	expectTrue(t, err == nil)
+
+	err = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?"+strings.Repeat("a=1&", DefaultMaxQueryParams+1), nil))
+	expectTrue(t, err != nil)
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{a: "/?b=2&a=1", b: "/?a=1&b=2", want: "a=1&b=2"},
+		{a: "/?id=3&id=1&id=2", b: "/?id=2&id=3&id=1", want: "id=1&id=2&id=3"},
+		{a: "/?q=hello+world", b: "/?q=hello%20world", want: "q=hello+world"},
+		{a: "/?k=%7E", b: "/?k=~", want: "k=~"},
+		{a: "/?x=%2F&y", b: "/?y=&x=/", want: "x=%2F&y="},
+		{a: "/", b: "/?", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a, func(t *testing.T) {
+			a := CanonicalQuery(httptest.NewRequest("GET", tt.a, nil))
+			b := CanonicalQuery(httptest.NewRequest("GET", tt.b, nil))
+			expectTrue(t, a == b)
+			expectTrue(t, a == tt.want)
+		})
+	}
+}
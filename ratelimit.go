@@ -0,0 +1,43 @@
+package httprouterx
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitInfo is the state of a rate limit for the current request, as reported by WriteRateLimitHeaders.
+type RateLimitInfo struct {
+	// Limit is the maximum number of requests allowed at once.
+	Limit int
+
+	// Remaining is the number of requests still allowed right now, after the current one.
+	Remaining int
+
+	// Reset is how long until the full limit is available again.
+	Reset time.Duration
+
+	// RetryAfter is how long until the next request will be allowed, when the current one is rejected.
+	// Zero means the current request is allowed.
+	RetryAfter time.Duration
+}
+
+// WriteRateLimitHeaders sets the rate limit headers of the response, so all the limiters report their state in
+// the same format: X-RateLimit-Limit, X-RateLimit-Remaining, and X-RateLimit-Reset, in seconds from now. When the
+// request is rejected, Retry-After is also set, in seconds. The durations are rounded up, so a client that
+// waits for them is never rejected again too early. It must be called before the header is written.
+func WriteRateLimitHeaders(w http.ResponseWriter, info RateLimitInfo) {
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(info.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(max(info.Remaining, 0)))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(info.Reset)))
+	if info.RetryAfter > 0 {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(info.RetryAfter)))
+	}
+}
+
+// ceilSeconds returns the duration in seconds, rounded up, and at least 0.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(max(d, 0).Seconds()))
+}
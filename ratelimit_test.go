@@ -0,0 +1,22 @@
+package httprouterx
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteRateLimitHeaders(t *testing.T) {
+	res := httptest.NewRecorder()
+	WriteRateLimitHeaders(res, RateLimitInfo{Limit: 10, Remaining: 4, Reset: 1500 * time.Millisecond})
+	expectTrue(t, res.Header().Get("X-RateLimit-Limit") == "10")
+	expectTrue(t, res.Header().Get("X-RateLimit-Remaining") == "4")
+	expectTrue(t, res.Header().Get("X-RateLimit-Reset") == "2")
+	expectTrue(t, res.Header().Get("Retry-After") == "")
+
+	res = httptest.NewRecorder()
+	WriteRateLimitHeaders(res, RateLimitInfo{Limit: 10, Remaining: -1, Reset: time.Minute, RetryAfter: time.Millisecond})
+	expectTrue(t, res.Header().Get("X-RateLimit-Remaining") == "0")
+	expectTrue(t, res.Header().Get("X-RateLimit-Reset") == "60")
+	expectTrue(t, res.Header().Get("Retry-After") == "1")
+}
@@ -0,0 +1,136 @@
+package httprouterx
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// ReplayHeader marks the requests re-issued by RecorderStore.Replay, so middlewares can tell them apart from
+	// real traffic, e.g. to skip side effects. Its value is "1".
+	ReplayHeader = "X-Replay"
+
+	// DefaultMaxRecordedBody is the maximum number of body bytes captured by RequestRecorder per request.
+	DefaultMaxRecordedBody = 64 << 10
+)
+
+// RecordedRequest is a request captured by RequestRecorder.
+type RecordedRequest struct {
+	Time   time.Time
+	Method string
+	// URI is the request URI, i.e. the path and the query.
+	URI    string
+	Header http.Header
+	Body   []byte
+
+	// Truncated reports whether the body was larger than DefaultMaxRecordedBody and was cut.
+	Truncated bool
+}
+
+// ReplayResult is the outcome of a replayed request.
+type ReplayResult struct {
+	Method   string
+	URI      string
+	Status   int
+	Duration time.Duration
+}
+
+// RecorderStore keeps the last captured requests in a ring buffer. It is safe for concurrent use.
+type RecorderStore struct {
+	mu       sync.Mutex
+	requests []RecordedRequest
+	next     int
+	full     bool
+}
+
+// NewRecorderStore creates a RecorderStore that keeps the last capacity requests.
+func NewRecorderStore(capacity int) *RecorderStore {
+	return &RecorderStore{requests: make([]RecordedRequest, max(capacity, 1))}
+}
+
+func (s *RecorderStore) add(rec RecordedRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[s.next] = rec
+	s.next = (s.next + 1) % len(s.requests)
+	s.full = s.full || s.next == 0
+}
+
+// Requests returns the captured requests, from the oldest to the newest.
+func (s *RecorderStore) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		return append([]RecordedRequest(nil), s.requests[:s.next]...)
+	}
+	return append(append([]RecordedRequest(nil), s.requests[s.next:]...), s.requests[:s.next]...)
+}
+
+// Replay re-issues the last count captured requests, oldest first, against the mux and reports the status and
+// the duration of each. The responses are discarded. The requests are rebuilt with their method, URI, headers and
+// body, plus the ReplayHeader marker. A count of 0 or less replays all the captured requests.
+func (s *RecorderStore) Replay(mux *ServeMux, count int) []ReplayResult {
+	requests := s.Requests()
+	if count > 0 && count < len(requests) {
+		requests = requests[len(requests)-count:]
+	}
+
+	results := make([]ReplayResult, 0, len(requests))
+	for _, rec := range requests {
+		req, err := http.NewRequest(rec.Method, rec.URI, bytes.NewReader(rec.Body))
+		if err != nil {
+			results = append(results, ReplayResult{Method: rec.Method, URI: rec.URI, Status: http.StatusBadRequest})
+			continue
+		}
+		req.Header = rec.Header.Clone()
+		req.Header.Set(ReplayHeader, "1")
+		req.RequestURI = rec.URI
+
+		w := newStatusWriter(discardResponseWriter{header: make(http.Header)})
+		start := time.Now()
+		mux.ServeHTTP(w, req)
+		results = append(results, ReplayResult{
+			Method:   rec.Method,
+			URI:      rec.URI,
+			Status:   w.statusCode(),
+			Duration: time.Since(start),
+		})
+	}
+	return results
+}
+
+// RequestRecorder captures the requests into the store, to replay them later with RecorderStore.Replay. The
+// body is captured up to DefaultMaxRecordedBody bytes, as it is read by the handler, so the capture adds no
+// buffering. The requests carrying the ReplayHeader are not captured again.
+//
+// The captures contain the raw headers and body, including credentials, so keep the store out of reach.
+func RequestRecorder(store *RecorderStore) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Header.Get(ReplayHeader) != "" {
+				return next.ServeHTTP(w, r)
+			}
+
+			rec := RecordedRequest{
+				Time:   time.Now(),
+				Method: r.Method,
+				URI:    r.URL.RequestURI(),
+				Header: r.Header.Clone(),
+			}
+			var body *snapshotReader
+			if r.Body != nil && r.Body != http.NoBody {
+				body = &snapshotReader{ReadCloser: r.Body, limit: DefaultMaxRecordedBody}
+				r.Body = body
+			}
+
+			err := next.ServeHTTP(w, r)
+			if body != nil {
+				rec.Body, rec.Truncated = body.buf.Bytes(), body.truncated
+			}
+			store.add(rec)
+			return err
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorderStore_Replay(t *testing.T) {
+	type seen struct {
+		method, uri, auth, body string
+		replay                  bool
+	}
+	var requests []seen
+
+	store := NewRecorderStore(2)
+	mux := NewServeMux(Options.Middleware(RequestRecorder(store)))
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		b, _ := io.ReadAll(r.Body)
+		requests = append(requests, seen{
+			method: r.Method,
+			uri:    r.URL.RequestURI(),
+			auth:   r.Header.Get("Authorization"),
+			body:   string(b),
+			replay: r.Header.Get(ReplayHeader) == "1",
+		})
+		if r.Method == "POST" {
+			w.WriteHeader(201)
+		}
+		return nil
+	}
+	mux.HandleFunc("GET", "/users", handler)
+	mux.HandleFunc("POST", "/users", handler)
+
+	send := func(method, target, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer t")
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send("GET", "/users?page=1", "")
+	send("GET", "/users?page=2", "")
+	send("POST", "/users", `{"name":"gopher"}`)
+
+	// the store keeps the last 2 requests only.
+	captured := store.Requests()
+	expectTrue(t, len(captured) == 2)
+	expectTrue(t, captured[0].URI == "/users?page=2")
+	expectTrue(t, captured[1].URI == "/users")
+	expectTrue(t, string(captured[1].Body) == `{"name":"gopher"}`)
+
+	results := store.Replay(mux, 0)
+	expectTrue(t, len(results) == 2)
+	expectTrue(t, results[0].Method == "GET" && results[0].URI == "/users?page=2" && results[0].Status == 200)
+	expectTrue(t, results[1].Method == "POST" && results[1].URI == "/users" && results[1].Status == 201)
+
+	expectTrue(t, len(requests) == 5)
+	expectTrue(t, requests[3] == seen{method: "GET", uri: "/users?page=2", auth: "Bearer t", replay: true})
+	expectTrue(t, requests[4] == seen{method: "POST", uri: "/users", auth: "Bearer t", body: `{"name":"gopher"}`, replay: true})
+
+	// replayed requests are not captured again.
+	expectTrue(t, len(store.Requests()) == 2)
+	expectTrue(t, store.Requests()[1].Header.Get(ReplayHeader) == "")
+
+	results = store.Replay(mux, 1)
+	expectTrue(t, len(results) == 1)
+	expectTrue(t, results[0].Method == "POST")
+}
+
+func TestRequestRecorder_TruncatedBody(t *testing.T) {
+	store := NewRecorderStore(1)
+	h := RequestRecorder(store).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = io.ReadAll(r.Body)
+		return nil
+	}))
+
+	body := strings.Repeat("x", DefaultMaxRecordedBody+1)
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	captured := store.Requests()
+	expectTrue(t, len(captured) == 1)
+	expectTrue(t, captured[0].Truncated)
+	expectTrue(t, len(captured[0].Body) == DefaultMaxRecordedBody)
+}
@@ -0,0 +1,49 @@
+package httprouterx
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is the error a recovered panic is converted to by RecoverMiddleware.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the goroutine that panicked, as formatted by runtime/debug.Stack.
+	Stack []byte
+}
+
+// Error implements error.
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// Unwrap returns the panic value if it is an error, so errors.Is and errors.As see through the panic.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RecoverMiddleware recovers the panics of the next handlers and returns them as a *PanicError, so they flow
+// through the middlewares and the LastResortErrorHandler like any other error, instead of reaching the
+// PanicHandler of the ServeMux, which bypasses both. Register it as the outermost route middleware, e.g. with
+// Options.Middleware, to cover the other middlewares too.
+//
+// A panic with http.ErrAbortHandler is re-panicked, since it is used to abort the response on purpose.
+func RecoverMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}()
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
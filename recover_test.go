@@ -0,0 +1,57 @@
+package httprouterx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	var handled error
+	mux := NewServeMux(
+		Options.Middleware(RecoverMiddleware()),
+		Options.LastResortErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusInternalServerError)
+		}),
+		Options.PanicHandler(func(w http.ResponseWriter, r *http.Request, v any) {
+			t.Fatal("the panic handler must not be called")
+		}),
+	)
+	mux.Route(Route{Method: "GET", Path: "/boom", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}})
+	mux.Route(Route{Method: "GET", Path: "/eof", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		panic(io.EOF)
+	}})
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest("GET", "/boom", nil))
+	expectTrue(t, res.Code == http.StatusInternalServerError)
+
+	var panicErr *PanicError
+	expectTrue(t, errors.As(handled, &panicErr))
+	expectTrue(t, panicErr.Value == "boom")
+	expectTrue(t, panicErr.Error() == "panic: boom")
+	expectTrue(t, bytes.Contains(panicErr.Stack, []byte("TestRecoverMiddleware")))
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/eof", nil))
+	expectTrue(t, errors.Is(handled, io.EOF))
+}
+
+func TestRecoverMiddleware_Abort(t *testing.T) {
+	h := RecoverMiddleware().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		v := recover()
+		expectTrue(t, v == http.ErrAbortHandler)
+	}()
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", strings.NewReader("")))
+	t.Fatal("the abort panic must be propagated")
+}
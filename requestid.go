@@ -0,0 +1,102 @@
+package httprouterx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// maxRequestIDLength is the maximum length of an incoming request ID; longer IDs are replaced.
+const maxRequestIDLength = 128
+
+// RequestIDConfig is the configuration for RequestIDMiddlewareWithConfig.
+type RequestIDConfig struct {
+	// Header is the name of the request and response header carrying the ID. Default X-Request-ID.
+	Header string
+
+	// Generate generates the ID of the requests without a valid one. Default NewRequestID.
+	Generate func() string
+}
+
+// RequestIDMiddleware gives each request a unique ID, to correlate the logs of a request across the services:
+// the ID of the X-Request-ID request header, or a new random UUID if the header is absent or invalid. The ID
+// is stored in the context, to be read with RequestID, and echoed back in the X-Request-ID response header.
+//
+// An incoming ID is valid if it is 1 to 128 printable ASCII characters long, which keeps the logs safe from
+// injected line breaks.
+func RequestIDMiddleware() Middleware {
+	return RequestIDMiddlewareWithConfig(RequestIDConfig{})
+}
+
+// RequestIDMiddlewareWithConfig is like RequestIDMiddleware, but with a custom header and generator.
+func RequestIDMiddlewareWithConfig(cfg RequestIDConfig) Middleware {
+	if cfg.Header == "" {
+		cfg.Header = "X-Request-ID"
+	}
+	if cfg.Generate == nil {
+		cfg.Generate = NewRequestID
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			id := r.Header.Get(cfg.Header)
+			if !isValidRequestID(id) {
+				id = cfg.Generate()
+			}
+			w.Header().Set(cfg.Header, id)
+			return next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+		})
+	}
+}
+
+// RequestID gets the ID given to the request by RequestIDMiddleware.
+// It returns an empty string if the middleware was not applied.
+func RequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// NewRequestID generates a random (version 4) UUID, e.g. 0b5d7a43-6c1e-4f2a-9d8e-3b7f1c2a4e5d.
+func NewRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("httprouterx: NewRequestID: " + err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4.
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant.
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// isValidRequestID reports whether the incoming ID can be trusted as is.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDOf gets the ID of RequestIDMiddleware, or the X-Request-ID header when the middleware was not
+// applied, e.g. when the ID is set by a proxy.
+func requestIDOf(r *http.Request) string {
+	if id := RequestID(r); id != "" {
+		return id
+	}
+	return r.Header.Get("X-Request-ID")
+}
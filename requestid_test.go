@@ -0,0 +1,67 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var got string
+	h := RequestIDMiddleware().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		got = RequestID(r)
+		return nil
+	}))
+
+	serve := func(incoming string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		if incoming != "" {
+			req.Header.Set("X-Request-ID", incoming)
+		}
+		res := httptest.NewRecorder()
+		expectTrue(t, h.ServeHTTP(res, req) == nil)
+		return res
+	}
+
+	res := serve("abc-123")
+	expectTrue(t, got == "abc-123")
+	expectTrue(t, res.Header().Get("X-Request-ID") == "abc-123")
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, incoming := range []string{"", "with space", strings.Repeat("x", 129), "café"} {
+		res = serve(incoming)
+		expectTrue(t, uuid.MatchString(got))
+		expectTrue(t, res.Header().Get("X-Request-ID") == got)
+	}
+
+	first := got
+	serve("")
+	expectTrue(t, got != first)
+
+	expectTrue(t, RequestID(httptest.NewRequest("GET", "/", nil)) == "")
+}
+
+func TestRequestIDMiddlewareWithConfig(t *testing.T) {
+	var got string
+	h := RequestIDMiddlewareWithConfig(RequestIDConfig{
+		Header:   "X-Correlation-ID",
+		Generate: func() string { return "generated" },
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		got = RequestID(r)
+		return nil
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "ignored")
+	res := httptest.NewRecorder()
+	_ = h.ServeHTTP(res, req)
+	expectTrue(t, got == "generated")
+	expectTrue(t, res.Header().Get("X-Correlation-ID") == "generated")
+	expectTrue(t, res.Header().Get("X-Request-ID") == "")
+
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	_ = h.ServeHTTP(httptest.NewRecorder(), req)
+	expectTrue(t, got == "corr-1")
+}
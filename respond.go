@@ -0,0 +1,68 @@
+package httprouterx
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// respondMediaTypes are the media types of Respond, by order of preference.
+var respondMediaTypes = []string{"application/json", "application/xml", "text/xml"}
+
+// Respond writes v as the response with the given status, encoded in JSON or XML according to the Accept header
+// of the request, so a handler can serve both without branching. JSON is preferred, and is used when the
+// request has no Accept header, or accepts both formats with the same quality, e.g. with */*. XML is written as
+// application/xml, or text/xml if that is the only one accepted, and encoded with encoding/xml, so v must be
+// encodable by it, which excludes maps.
+//
+// It returns a 406 Not Acceptable *HTTPError when the client accepts neither format, e.g. Accept: text/html. Like
+// WriteJSON, the value is encoded before anything is written, so an encoding error is returned with the response
+// untouched, and a nil v writes the status only. It adds Accept to the Vary header.
+func Respond(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	w.Header().Add("Vary", "Accept")
+
+	mediaType := respondMediaTypes[0]
+	if ranges := ParseAccept(r); ranges != nil {
+		mediaType = ""
+		best := 0.0
+		for _, mt := range respondMediaTypes {
+			if q := acceptQuality(ranges, mt); q > best {
+				mediaType, best = mt, q
+			}
+		}
+	}
+
+	switch mediaType {
+	case "":
+		return NewHTTPError(http.StatusNotAcceptable, "response is only available as application/json or application/xml")
+	case "application/json":
+		return WriteJSON(w, status, v)
+	}
+
+	if v == nil {
+		w.WriteHeader(status)
+		return nil
+	}
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// acceptQuality returns the quality of the most specific media range matching the media type, or 0 if none
+// matches.
+func acceptQuality(ranges []MediaRange, mediaType string) float64 {
+	quality, specificity := 0.0, -1
+	for _, mr := range ranges {
+		if s := mr.specificity(); s > specificity && mr.Matches(mediaType) {
+			quality, specificity = mr.Quality, s
+		}
+	}
+	return quality
+}
@@ -0,0 +1,63 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRespond(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id" xml:"id"`
+		Name string `json:"name" xml:"name"`
+	}
+
+	serve := func(accept string, v any) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest("GET", "/", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		res := httptest.NewRecorder()
+		return res, Respond(res, req, http.StatusCreated, v)
+	}
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/*", "application/json"},
+		{"application/json", "application/json"},
+		{"application/xml", "application/xml; charset=utf-8"},
+		{"text/xml", "text/xml; charset=utf-8"},
+		{"application/json;q=0.5, application/xml", "application/xml; charset=utf-8"},
+		{"application/xml;q=0.9, */*;q=0.8", "application/xml; charset=utf-8"},
+		{"*/*, application/json;q=0", "application/xml; charset=utf-8"},
+		{"text/html, application/xml;q=0.1", "application/xml; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		res, err := serve(tt.accept, user{ID: 1, Name: "Ada"})
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == http.StatusCreated)
+		expectTrue(t, res.Header().Get("Content-Type") == tt.contentType)
+		expectTrue(t, res.Header().Get("Vary") == "Accept")
+		if strings.HasPrefix(tt.contentType, "application/json") {
+			expectTrue(t, res.Body.String() == `{"id":1,"name":"Ada"}`)
+		} else {
+			expectTrue(t, res.Body.String() == `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<user><id>1</id><name>Ada</name></user>`)
+		}
+	}
+
+	for _, accept := range []string{"text/html", "application/json;q=0, application/xml;q=0, text/xml;q=0"} {
+		res, err := serve(accept, user{})
+		expectHTTPError(t, err, http.StatusNotAcceptable)
+		expectTrue(t, res.Body.Len() == 0)
+	}
+
+	// encoding errors are returned before anything is written.
+	res, err := serve("application/xml", map[string]string{"a": "b"})
+	expectTrue(t, err != nil)
+	expectTrue(t, res.Body.Len() == 0 && res.Header().Get("Content-Type") == "")
+}
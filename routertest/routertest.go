@@ -0,0 +1,141 @@
+// Package routertest provides helpers to test the routes of an httprouterx.ServeMux in a table-driven form.
+package routertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/josestg/httprouterx"
+)
+
+// RouteTestCase describes a request to send to the mux and the response expected from it. The zero values of the
+// expectations are not checked.
+type RouteTestCase struct {
+	// Name identifies the test case in the failure messages. Default "METHOD PATH".
+	Name string
+
+	// Method and Path are the method and the target of the request, e.g. GET and /users/1?expand=true.
+	Method string
+	Path   string
+
+	// Header and Body are the headers and the body of the request.
+	Header http.Header
+	Body   string
+
+	// WantStatus is the expected status code.
+	WantStatus int
+
+	// WantHeader are the expected response headers, all their values must match.
+	WantHeader http.Header
+
+	// WantHeaderPresent are the names of the response headers that must be present, whatever their values.
+	WantHeaderPresent []string
+
+	// WantBody is the expected response body. When both the expected and the actual bodies are valid JSON, they
+	// are compared as JSON values, so the key order and the spacing do not matter.
+	WantBody string
+
+	// WantBodySubset relaxes the comparison of WantBody: a JSON body may have more object keys than expected, at
+	// any depth, and the elements of the arrays are compared the same way. A body that is not JSON must contain
+	// WantBody.
+	WantBodySubset bool
+}
+
+// AssertRoute sends the request of tc to mux and reports every expectation of tc that the response does not
+// meet as an error of t. The test goes on after the failures, so the cases of a table are all checked.
+func AssertRoute(t *testing.T, mux *httprouterx.ServeMux, tc RouteTestCase) {
+	t.Helper()
+	for _, failure := range checkRoute(mux, tc) {
+		t.Error(failure)
+	}
+}
+
+// checkRoute returns the failed expectations of tc.
+func checkRoute(mux *httprouterx.ServeMux, tc RouteTestCase) []string {
+	name := tc.Name
+	if name == "" {
+		name = tc.Method + " " + tc.Path
+	}
+
+	req := httptest.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.Body))
+	for k, v := range tc.Header {
+		req.Header[k] = v
+	}
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	var failures []string
+	failf := func(format string, args ...any) {
+		failures = append(failures, name+": "+fmt.Sprintf(format, args...))
+	}
+
+	if tc.WantStatus != 0 && res.Code != tc.WantStatus {
+		failf("status: got %d, want %d", res.Code, tc.WantStatus)
+	}
+	for k, want := range tc.WantHeader {
+		if got := res.Header().Values(k); !reflect.DeepEqual(got, want) {
+			failf("header %s: got %q, want %q", k, got, want)
+		}
+	}
+	for _, k := range tc.WantHeaderPresent {
+		if len(res.Header().Values(k)) == 0 {
+			failf("header %s: missing", k)
+		}
+	}
+	if tc.WantBody != "" && !bodyMatches(res.Body.Bytes(), tc.WantBody, tc.WantBodySubset) {
+		failf("body: got %q, want %q", res.Body.String(), tc.WantBody)
+	}
+	return failures
+}
+
+// bodyMatches reports whether the body matches the expected one.
+func bodyMatches(body []byte, want string, subset bool) bool {
+	var gotDoc, wantDoc any
+	if json.Unmarshal(body, &gotDoc) != nil || json.Unmarshal([]byte(want), &wantDoc) != nil {
+		if subset {
+			return bytes.Contains(body, []byte(want))
+		}
+		return string(body) == want
+	}
+	if subset {
+		return jsonSubset(gotDoc, wantDoc)
+	}
+	return reflect.DeepEqual(gotDoc, wantDoc)
+}
+
+// jsonSubset reports whether the JSON value got contains want: the objects may have more keys, and the arrays
+// must have the same length, with each element containing the expected one.
+func jsonSubset(got, want any) bool {
+	switch want := want.(type) {
+	case map[string]any:
+		obj, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range want {
+			if _, ok := obj[k]; !ok || !jsonSubset(obj[k], v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		arr, ok := got.([]any)
+		if !ok || len(arr) != len(want) {
+			return false
+		}
+		for i := range want {
+			if !jsonSubset(arr[i], want[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(got, want)
+	}
+}
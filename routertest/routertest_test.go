@@ -0,0 +1,131 @@
+package routertest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/josestg/httprouterx"
+)
+
+func newMux() *httprouterx.ServeMux {
+	mux := httprouterx.NewServeMux()
+	mux.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-ID", "abc")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Encoding")
+		_, _ = io.WriteString(w, `{"id": 1, "name": "Ada", "roles": [{"name": "admin", "since": 2020}], "active": true}`)
+		return nil
+	})
+	mux.POST("/echo", func(w http.ResponseWriter, r *http.Request) error {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, r.Header.Get("X-Prefix")+string(body))
+		return nil
+	})
+	return mux
+}
+
+func TestAssertRoute(t *testing.T) {
+	mux := newMux()
+	for _, tc := range []RouteTestCase{
+		{
+			Method:            "GET",
+			Path:              "/users/1",
+			WantStatus:        http.StatusOK,
+			WantHeader:        http.Header{"Content-Type": {"application/json"}, "Vary": {"Accept", "Accept-Encoding"}},
+			WantHeaderPresent: []string{"X-Request-ID"},
+			WantBody:          `{"active": true, "roles": [{"since": 2020, "name": "admin"}], "name": "Ada", "id": 1}`,
+		},
+		{
+			Method:         "GET",
+			Path:           "/users/1",
+			WantBody:       `{"name": "Ada", "roles": [{"name": "admin"}]}`,
+			WantBodySubset: true,
+		},
+		{
+			Method:     "POST",
+			Path:       "/echo",
+			Header:     http.Header{"X-Prefix": {"echo: "}},
+			Body:       "hello",
+			WantStatus: http.StatusCreated,
+			WantBody:   "echo: hello",
+		},
+		{
+			Method:         "POST",
+			Path:           "/echo",
+			Body:           "hello world",
+			WantBody:       "lo wo",
+			WantBodySubset: true,
+		},
+		{
+			Method:     "GET",
+			Path:       "/missing",
+			WantStatus: http.StatusNotFound,
+		},
+	} {
+		AssertRoute(t, mux, tc)
+	}
+}
+
+func TestAssertRoute_Failures(t *testing.T) {
+	mux := newMux()
+	tests := []struct {
+		tc   RouteTestCase
+		want []string
+	}{
+		{
+			tc:   RouteTestCase{Method: "GET", Path: "/users/1", WantStatus: http.StatusNotFound},
+			want: []string{"GET /users/1: status: got 200, want 404"},
+		},
+		{
+			tc: RouteTestCase{
+				Name:              "headers",
+				Method:            "GET",
+				Path:              "/users/1",
+				WantHeader:        http.Header{"Vary": {"Accept"}},
+				WantHeaderPresent: []string{"ETag"},
+			},
+			want: []string{
+				`headers: header Vary: got ["Accept" "Accept-Encoding"], want ["Accept"]`,
+				"headers: header ETag: missing",
+			},
+		},
+		{
+			tc:   RouteTestCase{Method: "GET", Path: "/users/1", WantBody: `{"id": 1}`},
+			want: []string{"GET /users/1: body:"},
+		},
+		{
+			tc:   RouteTestCase{Method: "GET", Path: "/users/1", WantBody: `{"id": 2}`, WantBodySubset: true},
+			want: []string{"GET /users/1: body:"},
+		},
+		{
+			tc:   RouteTestCase{Method: "GET", Path: "/users/1", WantBody: `{"roles": []}`, WantBodySubset: true},
+			want: []string{"GET /users/1: body:"},
+		},
+		{
+			tc:   RouteTestCase{Method: "POST", Path: "/echo", Body: "hello", WantBody: "hell"},
+			want: []string{`POST /echo: body: got "hello", want "hell"`},
+		},
+		{
+			tc:   RouteTestCase{Method: "POST", Path: "/echo", Body: "hello", WantBody: "world", WantBodySubset: true},
+			want: []string{`POST /echo: body: got "hello", want "world"`},
+		},
+	}
+	for _, tt := range tests {
+		got := checkRoute(mux, tt.tc)
+		expectTrue(t, len(got) == len(tt.want))
+		for i := range got {
+			expectTrue(t, strings.HasPrefix(got[i], tt.want[i]))
+		}
+	}
+}
+
+func expectTrue(t *testing.T, condition bool) {
+	t.Helper()
+	if !condition {
+		t.FailNow()
+	}
+}
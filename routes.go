@@ -0,0 +1,101 @@
+package httprouterx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Routes returns the metadata of all registered routes, sorted by path and then by method.
+func (mux *ServeMux) Routes() []RouteInfo {
+	routes := make([]RouteInfo, len(mux.routes))
+	for i, info := range mux.routes {
+		info.Tags = append([]string(nil), info.Tags...)
+		info.Requires = append([]string(nil), info.Requires...)
+		routes[i] = info
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// RoutesHash returns a stable SHA-256 hex digest of the registered routes, made of the method, the path,
+// and the name of each route. The hash does not depend on registration order, so it can be asserted against
+// a golden value in a test to catch accidental changes of the API surface.
+func (mux *ServeMux) RoutesHash() string {
+	var b strings.Builder
+	for _, info := range mux.Routes() {
+		b.WriteString(info.Method)
+		b.WriteByte(' ')
+		b.WriteString(info.Path)
+		b.WriteByte(' ')
+		b.WriteString(info.Name)
+		b.WriteByte('\n')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// URL builds the path of the route registered with the given name, substituting its :param and *catchAll
+// segments with the params, e.g. URL("users.get", map[string]string{"id": "42"}) returns /users/42 for the
+// route /users/:id. The values of the named params are escaped as a path segment; the value of a catch-all param
+// may contain slashes, which are kept, and a leading slash is optional.
+//
+// It returns an error if no route has the name, if a param of the route is missing from params, or if params has
+// a param that the route does not have, which usually means a typo.
+func (mux *ServeMux) URL(name string, params map[string]string) (string, error) {
+	pattern, ok := mux.names[name]
+	if !ok {
+		return "", fmt.Errorf("httprouterx: no route named %q", name)
+	}
+
+	var (
+		b    strings.Builder
+		used = make(map[string]bool, len(params))
+	)
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != ':' && c != '*' {
+			b.WriteByte(c)
+			continue
+		}
+
+		end := strings.IndexByte(pattern[i:], '/')
+		if end < 0 {
+			end = len(pattern) - i
+		}
+		param := pattern[i+1 : i+end]
+		i += end - 1
+
+		value, ok := params[param]
+		if !ok {
+			return "", fmt.Errorf("httprouterx: route %q requires the param %q", name, param)
+		}
+		used[param] = true
+		if c == ':' {
+			b.WriteString(url.PathEscape(value))
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for j, segment := range segments {
+			if j > 0 {
+				b.WriteByte('/')
+			}
+			b.WriteString(url.PathEscape(segment))
+		}
+	}
+
+	for param := range params {
+		if !used[param] {
+			return "", fmt.Errorf("httprouterx: route %q has no param %q", name, param)
+		}
+	}
+	return b.String(), nil
+}
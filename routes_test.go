@@ -0,0 +1,101 @@
+package httprouterx
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServeMux_Routes(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "POST", Path: "/users", Handler: noop, Name: "users.create", Tags: []string{"users"}})
+	mux.HandleFunc("GET", "/users", noop)
+	mux.HandleFunc("GET", "/health", noop)
+
+	routes := mux.Routes()
+	expectTrue(t, len(routes) == 3)
+	expectTrue(t, routes[0].Method == "GET" && routes[0].Path == "/health")
+	expectTrue(t, routes[1].Method == "GET" && routes[1].Path == "/users")
+	expectTrue(t, routes[2].Method == "POST" && routes[2].Name == "users.create")
+
+	// the returned slice is a copy.
+	routes[2].Tags[0] = "changed"
+	expectTrue(t, mux.Routes()[2].Tags[0] == "users")
+}
+
+func TestServeMux_RoutesHash(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	a := NewServeMux()
+	a.HandleFunc("GET", "/users", noop)
+	a.Route(Route{Method: "POST", Path: "/users", Handler: noop, Name: "users.create"})
+
+	b := NewServeMux()
+	b.Route(Route{Method: "POST", Path: "/users", Handler: noop, Name: "users.create"})
+	b.HandleFunc("GET", "/users", noop)
+
+	expectTrue(t, a.RoutesHash() == b.RoutesHash())
+	expectTrue(t, len(a.RoutesHash()) == 64)
+
+	renamed := NewServeMux()
+	renamed.HandleFunc("GET", "/users", noop)
+	renamed.Route(Route{Method: "POST", Path: "/users", Handler: noop, Name: "users.new"})
+	expectFalse(t, a.RoutesHash() == renamed.RoutesHash())
+
+	added := NewServeMux()
+	added.HandleFunc("GET", "/users", noop)
+	added.Route(Route{Method: "POST", Path: "/users", Handler: noop, Name: "users.create"})
+	added.HandleFunc("DELETE", "/users/:id", noop)
+	expectFalse(t, a.RoutesHash() == added.RoutesHash())
+
+	expectTrue(t, NewServeMux().RoutesHash() == "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+}
+
+func TestServeMux_URL(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "GET", Path: "/users/:id", Handler: noop, Name: "users.get"})
+	mux.Route(Route{Method: "PUT", Path: "/users/:id", Handler: noop, Name: "users.get"})
+	mux.Route(Route{Method: "GET", Path: "/users/:id/files/*path", Handler: noop, Name: "users.files"})
+	mux.Route(Route{Method: "GET", Path: "/health", Handler: noop, Name: "health"})
+
+	u, err := mux.URL("users.get", map[string]string{"id": "42"})
+	expectTrue(t, err == nil && u == "/users/42")
+
+	u, err = mux.URL("users.get", map[string]string{"id": "a b/c"})
+	expectTrue(t, err == nil && u == "/users/a%20b%2Fc")
+
+	u, err = mux.URL("users.files", map[string]string{"id": "1", "path": "/docs/a b.txt"})
+	expectTrue(t, err == nil && u == "/users/1/files/docs/a%20b.txt")
+	u, err = mux.URL("users.files", map[string]string{"id": "1", "path": "docs/"})
+	expectTrue(t, err == nil && u == "/users/1/files/docs/")
+
+	u, err = mux.URL("health", nil)
+	expectTrue(t, err == nil && u == "/health")
+
+	// the generated URLs are routed back to the route.
+	var got string
+	mux.Route(Route{Method: "GET", Path: "/echo/:name/*rest", Name: "echo", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		got = PathParams(r).ByName("name") + "|" + PathParams(r).ByName("rest")
+		return nil
+	}})
+	u, _ = mux.URL("echo", map[string]string{"name": "a b", "rest": "x/y z"})
+	mux.TestRequest("GET", u, nil)
+	expectTrue(t, got == "a b|/x/y z")
+
+	_, err = mux.URL("missing", nil)
+	expectTrue(t, err != nil)
+	_, err = mux.URL("users.files", map[string]string{"id": "1"})
+	expectTrue(t, err != nil && strings.Contains(err.Error(), `"path"`))
+	_, err = mux.URL("users.get", map[string]string{"id": "1", "i": "2"})
+	expectTrue(t, err != nil && strings.Contains(err.Error(), `"i"`))
+
+	// names identify a single path.
+	defer func() {
+		expectTrue(t, recover() != nil)
+	}()
+	mux.Route(Route{Method: "DELETE", Path: "/accounts/:id", Handler: noop, Name: "users.get"})
+}
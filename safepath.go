@@ -0,0 +1,71 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is the underlying error of the *HTTPError returned by SafePathParam and SafeJoin
+// when a path could escape its base directory.
+var ErrUnsafePath = errors.New("unsafe path")
+
+// SafePathParam rejects, with 400 Bad Request, requests whose named path param could be used for path traversal
+// when mapped to the filesystem: it must not contain null bytes, ".." elements or absolute path indicators (a
+// leading slash or backslash, or a Windows volume name). The checks also apply to the percent-decoded value, to
+// catch encoded traversal attempts such as %2e%2e.
+//
+// For catch-all params (*name), the leading slash added by the router is allowed.
+func SafePathParam(name string) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			value := PathParams(r).ByName(name)
+			if route, ok := CurrentRoute(r); ok && strings.Contains(route.Path, "*"+name) {
+				value = strings.TrimPrefix(value, "/")
+			}
+
+			if !isSafePathValue(value) {
+				return &HTTPError{Status: http.StatusBadRequest, Message: "invalid path parameter: " + name, Err: ErrUnsafePath}
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isSafePathValue checks the value, and its percent-decoded form, for traversal attempts.
+func isSafePathValue(value string) bool {
+	decoded, err := url.PathUnescape(value)
+	if err != nil {
+		return false
+	}
+	for _, v := range []string{value, decoded} {
+		if strings.ContainsRune(v, 0) || strings.HasPrefix(v, "/") || strings.HasPrefix(v, `\`) {
+			return false
+		}
+		if len(v) >= 2 && v[1] == ':' && (v[0]|0x20 >= 'a' && v[0]|0x20 <= 'z') {
+			return false
+		}
+		for _, elem := range strings.FieldsFunc(v, func(r rune) bool { return r == '/' || r == '\\' }) {
+			if elem == ".." {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SafeJoin joins the relative path to the base directory, and rejects any path that would escape base, such as
+// ../etc/passwd. Leading slashes of rel are ignored, so catch-all path params can be used as is. The error is
+// an *HTTPError with status 400, wrapping ErrUnsafePath.
+func SafeJoin(base, rel string) (string, error) {
+	rel = strings.TrimLeft(rel, "/")
+	if rel == "" {
+		return filepath.Clean(base), nil
+	}
+	if strings.ContainsRune(rel, 0) || !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return "", &HTTPError{Status: http.StatusBadRequest, Message: "invalid path", Err: ErrUnsafePath}
+	}
+	return filepath.Join(base, filepath.FromSlash(rel)), nil
+}
@@ -0,0 +1,67 @@
+package httprouterx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSafePathParam(t *testing.T) {
+	mux := NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	mux.Route(Route{Method: "GET", Path: "/files/:name", Handler: ok}, SafePathParam("name"))
+	mux.Route(Route{Method: "GET", Path: "/static/*filepath", Handler: ok}, SafePathParam("filepath"))
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{target: "/files/report.pdf", status: 200},
+		{target: "/files/..", status: 400},
+		{target: "/files/%2e%2e", status: 400},
+		{target: "/files/%252e%252e", status: 400},
+		{target: "/files/a%00b", status: 400},
+		{target: "/files/%5cetc", status: 400},
+		{target: "/files/..%5cwindows", status: 400},
+		{target: "/files/C:", status: 400},
+		{target: "/static/css/app.css", status: 200},
+		{target: "/static/css/%2e%2e/%2e%2e/secret", status: 400},
+		{target: "/static//etc/passwd", status: 400},
+	}
+
+	for _, tt := range tests {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("GET", tt.target, nil))
+		expectTrue(t, res.Code == tt.status)
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	base := filepath.FromSlash("/srv/files")
+
+	tests := []struct {
+		rel  string
+		want string
+	}{
+		{rel: "a/b.txt", want: filepath.FromSlash("/srv/files/a/b.txt")},
+		{rel: "/a/b.txt", want: filepath.FromSlash("/srv/files/a/b.txt")},
+		{rel: "a/../b.txt", want: filepath.FromSlash("/srv/files/b.txt")},
+		{rel: "", want: base},
+		{rel: "../etc/passwd"},
+		{rel: "a/../../etc/passwd"},
+		{rel: "a\x00b"},
+	}
+
+	for _, tt := range tests {
+		got, err := SafeJoin(base, tt.rel)
+		if tt.want == "" {
+			expectTrue(t, errors.Is(err, ErrUnsafePath))
+			expectHTTPError(t, err, 400)
+			continue
+		}
+		expectTrue(t, err == nil)
+		expectTrue(t, got == tt.want)
+	}
+}
@@ -0,0 +1,89 @@
+package httprouterx
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ResponseValidationConfig is the configuration for ValidateResponseWithConfig.
+type ResponseValidationConfig struct {
+	// Schema is the JSON Schema of the response body. See ValidateResponse for the supported keywords.
+	Schema []byte
+
+	// Strict replaces invalid responses with a 500 error, instead of only logging them.
+	Strict bool
+
+	// Logger is used to report the invalid responses. Default slog.Default().
+	Logger *slog.Logger
+}
+
+// ValidateResponse validates that successful (2xx) JSON responses conform to the JSON Schema, and logs the
+// violations as errors. It is meant to catch contract drift during development and testing, so only enable it
+// there: the response is buffered and decoded, which is too costly for production.
+//
+// The commonly used subset of JSON Schema is supported: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern, minimum, maximum, allOf,
+// anyOf and oneOf. It panics if the schema is invalid.
+func ValidateResponse(schema []byte) Middleware {
+	return ValidateResponseWithConfig(ResponseValidationConfig{Schema: schema})
+}
+
+// ValidateResponseWithConfig is like ValidateResponse, but it can also fail invalid responses in strict mode.
+func ValidateResponseWithConfig(cfg ResponseValidationConfig) Middleware {
+	schema, err := compileSchema(cfg.Schema)
+	if err != nil {
+		panic("httprouterx: ValidateResponse: " + err.Error())
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			buf, r := bufferResponse(w, r)
+			if err := next.ServeHTTP(buf, r); err != nil || buf.streaming {
+				if buf.written() {
+					_ = buf.flushTo(w)
+				}
+				return err
+			}
+
+			status := buf.statusCode()
+			if status < 200 || status > 299 || !isJSONContentType(buf.Header().Get("Content-Type")) {
+				return buf.flushTo(w)
+			}
+
+			violations := schema.validateJSON(buf.body.Bytes())
+			if len(violations) == 0 {
+				return buf.flushTo(w)
+			}
+
+			cfg.Logger.ErrorContext(r.Context(), "response does not match the schema",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"violations", violations,
+			)
+			if cfg.Strict {
+				return &HTTPError{
+					Status:  http.StatusInternalServerError,
+					Message: http.StatusText(http.StatusInternalServerError),
+					Err:     errors.New("response does not match the schema: " + strings.Join(violations, "; ")),
+				}
+			}
+			return buf.flushTo(w)
+		})
+	}
+}
+
+// isJSONContentType reports whether the content type is application/json or a +json structured syntax suffix.
+func isJSONContentType(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || (strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json"))
+}
@@ -0,0 +1,73 @@
+package httprouterx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateResponse(t *testing.T) {
+	schema := []byte(`{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}`)
+
+	jsonHandler := func(status int, body string) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, err := io.WriteString(w, body)
+			return err
+		})
+	}
+
+	tests := []struct {
+		name    string
+		strict  bool
+		handler Handler
+		status  int
+		logged  bool
+		failed  bool
+	}{
+		{name: "valid", handler: jsonHandler(200, `{"id": 1}`), status: 200},
+		{name: "invalid is logged", handler: jsonHandler(200, `{"id": "1"}`), status: 200, logged: true},
+		{name: "invalid in strict mode", strict: true, handler: jsonHandler(200, `{"id": "1"}`), logged: true, failed: true},
+		{name: "non 2xx is not validated", strict: true, handler: jsonHandler(404, `{"error": "not found"}`), status: 404},
+		{name: "non json is not validated", strict: true, handler: HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			_, err := io.WriteString(w, "plain")
+			return err
+		}), status: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			mid := ValidateResponseWithConfig(ResponseValidationConfig{
+				Schema: schema,
+				Strict: tt.strict,
+				Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+			})
+
+			res := httptest.NewRecorder()
+			err := mid.Then(tt.handler).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+			expectTrue(t, strings.Contains(logs.String(), "response does not match the schema") == tt.logged)
+
+			if tt.failed {
+				var httpErr *HTTPError
+				expectTrue(t, errors.As(err, &httpErr))
+				expectTrue(t, httpErr.Status == 500)
+				expectTrue(t, res.Body.Len() == 0)
+				return
+			}
+			expectTrue(t, err == nil)
+			expectTrue(t, res.Code == tt.status)
+		})
+	}
+}
+
+func TestValidateResponse_InvalidSchema(t *testing.T) {
+	defer func() { expectTrue(t, recover() != nil) }()
+	ValidateResponse([]byte(`{`))
+}
@@ -0,0 +1,87 @@
+package httprouterx
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+)
+
+// DefaultSchemaCompatSampleRate is the default sampling rate of SchemaCompat: one in every
+// DefaultSchemaCompatSampleRate requests is checked.
+const DefaultSchemaCompatSampleRate = 10
+
+// SchemaCompatConfig is the configuration for SchemaCompatWithConfig.
+type SchemaCompatConfig struct {
+	// Old and New are the current and the proposed JSON Schemas of the request body. See ValidateResponse for the
+	// supported keywords.
+	Old, New []byte
+
+	// SampleRate checks one in every SampleRate requests, starting with the first one.
+	// Default DefaultSchemaCompatSampleRate, 1 checks them all.
+	SampleRate int
+
+	// Logger receives the breaking requests. Default slog.Default().
+	Logger *slog.Logger
+}
+
+// SchemaCompat tells, from the real traffic, whether a proposed change of the JSON Schema of the request bodies
+// would break the existing clients: the JSON bodies of the sampled requests are validated against both schemas,
+// and the ones that pass the old schema but fail the new one are logged as warnings, with the violations.
+//
+// It is purely observational, the requests are always passed to the handler untouched. The checked bodies are
+// buffered, up to DefaultMaxJSONBytes; larger bodies are not checked. It is meant for development and staging
+// environments. It panics if a schema is invalid.
+func SchemaCompat(old, new []byte) Middleware {
+	return SchemaCompatWithConfig(SchemaCompatConfig{Old: old, New: new})
+}
+
+// SchemaCompatWithConfig is like SchemaCompat, but with a custom sampling rate and logger.
+func SchemaCompatWithConfig(cfg SchemaCompatConfig) Middleware {
+	oldSchema, err := compileSchema(cfg.Old)
+	if err != nil {
+		panic("httprouterx: SchemaCompat: old: " + err.Error())
+	}
+	newSchema, err := compileSchema(cfg.New)
+	if err != nil {
+		panic("httprouterx: SchemaCompat: new: " + err.Error())
+	}
+	if cfg.SampleRate <= 0 {
+		cfg.SampleRate = DefaultSchemaCompatSampleRate
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	var counter atomic.Uint64
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Body == nil || r.Body == http.NoBody || !isJSONContentType(r.Header.Get("Content-Type")) {
+				return next.ServeHTTP(w, r)
+			}
+			if (counter.Add(1)-1)%uint64(cfg.SampleRate) != 0 {
+				return next.ServeHTTP(w, r)
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, DefaultMaxJSONBytes+1))
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+			if err != nil || len(body) > DefaultMaxJSONBytes {
+				return next.ServeHTTP(w, r)
+			}
+
+			if len(oldSchema.validateJSON(body)) == 0 {
+				if violations := newSchema.validateJSON(body); len(violations) > 0 {
+					route, _ := CurrentRoute(r)
+					cfg.Logger.WarnContext(r.Context(), "request breaks the new schema",
+						"method", r.Method,
+						"path", r.URL.Path,
+						"route", route.Path,
+						"violations", violations,
+					)
+				}
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package httprouterx
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSchemaCompat(t *testing.T) {
+	oldSchema := []byte(`{"type":"object","required":["name"],"properties":{"name":{"type":"string"},"age":{"type":"integer"}}}`)
+	// the new schema requires the email and restricts the age, which breaks the existing clients.
+	newSchema := []byte(`{"type":"object","required":["name","email"],"properties":{"name":{"type":"string"},"email":{"type":"string"},"age":{"type":"integer","minimum":0}}}`)
+
+	var logs bytes.Buffer
+	h := SchemaCompatWithConfig(SchemaCompatConfig{
+		Old:        oldSchema,
+		New:        newSchema,
+		SampleRate: 1,
+		Logger:     slog.New(slog.NewJSONHandler(&logs, nil)),
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		b, err := io.ReadAll(r.Body)
+		_, _ = w.Write(b)
+		return err
+	}))
+
+	tests := []struct {
+		body     string
+		breaking bool
+	}{
+		{body: `{"name":"gopher","email":"gopher@example.com"}`},
+		{body: `{"name":"gopher"}`, breaking: true},
+		{body: `{"name":"gopher","email":"gopher@example.com","age":-1}`, breaking: true},
+		{body: `{"age":1}`}, // already invalid for the old schema.
+		{body: `not json`},
+	}
+
+	for _, tt := range tests {
+		logs.Reset()
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+		expectTrue(t, h.ServeHTTP(res, req) == nil)
+		expectTrue(t, res.Body.String() == tt.body)
+
+		if !tt.breaking {
+			expectTrue(t, logs.Len() == 0)
+			continue
+		}
+		var record map[string]any
+		expectTrue(t, json.Unmarshal(logs.Bytes(), &record) == nil)
+		expectTrue(t, record["level"] == "WARN")
+		expectTrue(t, record["path"] == "/users")
+		expectTrue(t, len(record["violations"].([]any)) > 0)
+	}
+}
+
+func TestSchemaCompat_Sampling(t *testing.T) {
+	var logs bytes.Buffer
+	h := SchemaCompatWithConfig(SchemaCompatConfig{
+		Old:    []byte(`{"type":"object"}`),
+		New:    []byte(`{"type":"array"}`),
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	for i := 0; i < 2*DefaultSchemaCompatSampleRate; i++ {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		_ = h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	expectTrue(t, strings.Count(logs.String(), "request breaks the new schema") == 2)
+}
@@ -0,0 +1,54 @@
+package httprouterx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RequireScopes denies the requests whose token is not granted all the scopes with 403 Forbidden. The message of
+// the error lists the missing scopes.
+//
+// The scopes are read from the context, where the authentication middleware must store them with WithScopes, so
+// RequireScopes must be placed after it. If no scopes were stored, the request is considered unauthenticated and
+// denied with 401 Unauthorized.
+func RequireScopes(scopes ...string) Middleware {
+	return requireScopes(scopes, true)
+}
+
+// RequireAnyScope is like RequireScopes, but the token needs only one of the scopes.
+func RequireAnyScope(scopes ...string) Middleware {
+	return requireScopes(scopes, false)
+}
+
+func requireScopes(required []string, all bool) Middleware {
+	required = append([]string(nil), required...)
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			granted, ok := ScopesFromContext(r.Context())
+			if !ok {
+				return NewHTTPError(http.StatusUnauthorized, "")
+			}
+
+			has := make(map[string]bool, len(granted))
+			for _, s := range granted {
+				has[s] = true
+			}
+
+			var missing []string
+			for _, s := range required {
+				if !has[s] {
+					missing = append(missing, s)
+				}
+			}
+
+			switch {
+			case len(missing) == 0:
+			case all:
+				return NewHTTPError(http.StatusForbidden, "missing scopes: "+strings.Join(missing, " "))
+			case len(missing) == len(required):
+				return NewHTTPError(http.StatusForbidden, "requires one of the scopes: "+strings.Join(missing, " "))
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireScopes(t *testing.T) {
+	withScopes := func(scopes []string) *http.Request {
+		req := httptest.NewRequest("GET", "/", nil)
+		if scopes != nil {
+			req = req.WithContext(WithScopes(req.Context(), scopes))
+		}
+		return req
+	}
+	ok := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	tests := []struct {
+		name    string
+		mid     Middleware
+		granted []string
+		status  int
+		message string
+	}{
+		{name: "all present", mid: RequireScopes("read", "write"), granted: []string{"write", "read", "admin"}},
+		{name: "partial", mid: RequireScopes("read", "write"), granted: []string{"read"}, status: 403, message: "missing scopes: write"},
+		{name: "missing", mid: RequireScopes("read", "write"), granted: []string{}, status: 403, message: "missing scopes: read write"},
+		{name: "unauthenticated", mid: RequireScopes("read"), status: 401},
+		{name: "any present", mid: RequireAnyScope("read", "admin"), granted: []string{"admin"}},
+		{name: "any missing", mid: RequireAnyScope("read", "admin"), granted: []string{"write"}, status: 403, message: "requires one of the scopes: read admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.mid.Then(ok).ServeHTTP(httptest.NewRecorder(), withScopes(tt.granted))
+			if tt.status == 0 {
+				expectTrue(t, err == nil)
+				return
+			}
+			httpErr := expectHTTPError(t, err, tt.status)
+			if tt.message != "" {
+				expectTrue(t, httpErr.Message == tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Server sets the server used by ServeMux.ListenAndServe, e.g. to configure TLS or custom timeouts. Its Handler
+// is replaced by the ServeMux, and its Addr by the address given to ListenAndServe, unless empty. By default, a
+// zero http.Server is used.
+func (nsOpts) Server(srv *http.Server) Option {
+	return func(mux *ServeMux) { mux.server = srv }
+}
+
+// ListenAndServe serves the mux on the TCP address until the process receives SIGINT or SIGTERM, then shuts the
+// server down gracefully: it stops accepting connections and waits for the in-flight requests to complete, for
+// at most shutdownTimeout. When the server set by Options.Server has a TLS certificate in its TLSConfig, it
+// serves HTTPS.
+//
+// It returns nil after a graceful shutdown, the error of Shutdown if it did not complete in time, or the error
+// that stopped the server from serving, e.g. when the address is already in use.
+func (mux *ServeMux) ListenAndServe(addr string, shutdownTimeout time.Duration) error {
+	srv := mux.server
+	if srv == nil {
+		srv = &http.Server{}
+	}
+	if addr != "" {
+		srv.Addr = addr
+	}
+	srv.Handler = mux
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serveUntil(ctx, srv, ln, shutdownTimeout)
+}
+
+// serveUntil serves on the listener until ctx is done, then shuts the server down.
+func serveUntil(ctx context.Context, srv *http.Server, ln net.Listener, shutdownTimeout time.Duration) error {
+	served := make(chan error, 1)
+	go func() {
+		if tls := srv.TLSConfig; tls != nil && (len(tls.Certificates) > 0 || tls.GetCertificate != nil) {
+			served <- srv.ServeTLS(ln, "", "")
+		} else {
+			served <- srv.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-served:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil // closed by the owner of the server.
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeUntil(t *testing.T) {
+	started := make(chan struct{})
+	mux := NewServeMux()
+	mux.GET("/slow", func(w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+		return nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	expectTrue(t, err == nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- serveUntil(ctx, &http.Server{Handler: mux}, ln, time.Second) }()
+
+	// the in-flight request completes during the shutdown.
+	body := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		body <- string(b)
+	}()
+	<-started
+	cancel()
+
+	expectTrue(t, <-body == "done")
+	expectTrue(t, <-stopped == nil)
+
+	_, err = http.Get("http://" + ln.Addr().String() + "/slow")
+	expectTrue(t, err != nil)
+}
+
+func TestServeUntil_ShutdownTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	mux := NewServeMux()
+	mux.GET("/stuck", func(w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	expectTrue(t, err == nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- serveUntil(ctx, &http.Server{Handler: mux}, ln, 10*time.Millisecond) }()
+
+	go func() { _, _ = http.Get("http://" + ln.Addr().String() + "/stuck") }()
+	<-started
+	cancel()
+	expectTrue(t, errors.Is(<-stopped, context.DeadlineExceeded))
+}
+
+func TestServeMux_ListenAndServe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	expectTrue(t, err == nil)
+	defer ln.Close()
+
+	// the address is already in use.
+	srv := &http.Server{ReadHeaderTimeout: time.Second}
+	mux := NewServeMux(Options.Server(srv))
+	err = mux.ListenAndServe(ln.Addr().String(), time.Second)
+	expectTrue(t, err != nil)
+	expectTrue(t, srv.Addr == ln.Addr().String())
+	expectTrue(t, srv.Handler == mux)
+
+	// closed by the owner of the server.
+	srv = &http.Server{Addr: "127.0.0.1:0"}
+	mux = NewServeMux(Options.Server(srv))
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = srv.Close()
+	}()
+	expectTrue(t, mux.ListenAndServe("", time.Second) == nil)
+}
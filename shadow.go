@@ -0,0 +1,70 @@
+package httprouterx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// maxShadowInFlight bounds the number of shadow handlers running at the same time.
+// When the limit is reached, requests are served normally without being shadowed.
+const maxShadowInFlight = 32
+
+// Shadow mirrors the traffic to the shadow handler, which is useful to test a new implementation against
+// production traffic before migrating to it.
+//
+// The real handler is served normally. The shadow runs asynchronously on a copy of the request, with a buffered
+// copy of the body and a context that is not cancelled when the real request ends. The shadow response is
+// discarded, and its errors and panics are only logged, so they never affect the real response.
+func Shadow(shadow Handler) Middleware {
+	sem := make(chan struct{}, maxShadowInFlight)
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			select {
+			case sem <- struct{}{}:
+			default:
+				return next.ServeHTTP(w, r)
+			}
+
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				b, err := io.ReadAll(r.Body)
+				_ = r.Body.Close()
+				if err != nil {
+					<-sem
+					return err
+				}
+				body = b
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			clone := r.Clone(context.WithoutCancel(r.Context()))
+			if body != nil {
+				clone.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			go func() {
+				defer func() { <-sem }()
+				defer func() {
+					if v := recover(); v != nil {
+						slog.Warn("shadow handler panicked", "method", clone.Method, "path", clone.URL.Path, "panic", v)
+					}
+				}()
+				if err := shadow.ServeHTTP(discardResponseWriter{header: make(http.Header)}, clone); err != nil {
+					slog.Warn("shadow handler failed", "method", clone.Method, "path", clone.URL.Path, "error", err)
+				}
+			}()
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// discardResponseWriter is an http.ResponseWriter that discards everything written to it.
+type discardResponseWriter struct{ header http.Header }
+
+func (d discardResponseWriter) Header() http.Header         { return d.header }
+func (d discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d discardResponseWriter) WriteHeader(int)             {}
@@ -0,0 +1,51 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestShadow(t *testing.T) {
+	shadowed := make(chan string, 1)
+	shadow := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		b, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("shadow response"))
+		shadowed <- string(b)
+		return nil
+	})
+
+	h := Shadow(shadow).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		b, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(b)
+		return nil
+	}))
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/data", strings.NewReader("payload"))
+	err := h.ServeHTTP(res, req)
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Body.String() == "payload")
+	expectTrue(t, <-shadowed == "payload")
+}
+
+func TestShadow_PanicDoesNotAffectResponse(t *testing.T) {
+	done := make(chan struct{})
+	shadow := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		defer close(done)
+		panic("shadow panic")
+	})
+
+	h := Shadow(shadow).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(201)
+		return nil
+	}))
+
+	res := httptest.NewRecorder()
+	err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	<-done
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Code == 201)
+}
@@ -0,0 +1,100 @@
+package httprouterx
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AdaptiveConfig is the configuration for AdaptiveShed.
+type AdaptiveConfig struct {
+	// Target is the acceptable average latency. Requests are shed while the average is above it. Required.
+	Target time.Duration
+
+	// Alpha is the smoothing factor of the exponential moving average of the latency, between 0 and 1: the
+	// weight of the latest request. Higher values react faster, lower values absorb the spikes. Default 0.1.
+	Alpha float64
+
+	// MaxProbability caps the shed probability, between 0 and 1, so a fraction of the requests keeps flowing
+	// and the moving average can notice the recovery. Default 0.9.
+	MaxProbability float64
+
+	// OnProbability is called with the shed probability every time it changes, e.g. to export it as a gauge to
+	// a monitoring system. It is called while holding the shedder lock, so it must not block.
+	OnProbability func(p float64)
+
+	// Clock is used to measure the latency. Default SystemClock.
+	Clock Clock
+}
+
+// AdaptiveShed protects the tail latency under overload: it tracks an exponential moving average of the
+// latency of the served requests and, while it exceeds the target, rejects new requests with 503 Service
+// Unavailable at random, before they reach the handler. Unlike a fixed concurrency cap, it reacts to how the
+// service actually behaves, the same way CoDel reacts to the queueing delay.
+//
+// The shed probability grows linearly with the excess over the target: 0 at the target, and MaxProbability at
+// twice the target and above. Rejected requests are not measured.
+func AdaptiveShed(cfg AdaptiveConfig) Middleware {
+	if cfg.Target <= 0 {
+		panic("httprouterx: AdaptiveShed: the target latency must be positive")
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = 0.1
+	}
+	if cfg.MaxProbability <= 0 || cfg.MaxProbability > 1 {
+		cfg.MaxProbability = 0.9
+	}
+	clock := clockOrSystem(cfg.Clock)
+
+	s := &adaptiveShedder{cfg: cfg}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if p := s.probability(); p > 0 && rand.Float64() < p {
+				return NewHTTPError(http.StatusServiceUnavailable, "server overloaded, try again later")
+			}
+
+			start := clock.Now()
+			err := next.ServeHTTP(w, r)
+			s.observe(clock.Now().Sub(start))
+			return err
+		})
+	}
+}
+
+// adaptiveShedder holds the moving average of AdaptiveShed.
+type adaptiveShedder struct {
+	cfg AdaptiveConfig
+
+	mu      sync.Mutex
+	avg     float64 // nanoseconds.
+	sampled bool
+	p       float64
+}
+
+func (s *adaptiveShedder) probability() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p
+}
+
+// observe adds the latency to the moving average, and updates the shed probability.
+func (s *adaptiveShedder) observe(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sampled {
+		s.avg += s.cfg.Alpha * (float64(latency) - s.avg)
+	} else {
+		s.avg, s.sampled = float64(latency), true
+	}
+
+	target := float64(s.cfg.Target)
+	p := min(max((s.avg-target)/target, 0), 1) * s.cfg.MaxProbability
+	if p != s.p {
+		s.p = p
+		if s.cfg.OnProbability != nil {
+			s.cfg.OnProbability(p)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveShed(t *testing.T) {
+	clock := newFakeClock()
+	latency := 50 * time.Millisecond
+
+	var probability float64
+	h := AdaptiveShed(AdaptiveConfig{
+		Target:        100 * time.Millisecond,
+		Alpha:         0.5,
+		OnProbability: func(p float64) { probability = p },
+		Clock:         clock,
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		clock.Advance(latency)
+		w.WriteHeader(200)
+		return nil
+	}))
+
+	serve := func(n int) (rejected int) {
+		for i := 0; i < n; i++ {
+			err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				expectHTTPError(t, err, http.StatusServiceUnavailable)
+				rejected++
+			}
+		}
+		return rejected
+	}
+
+	// below the target.
+	expectTrue(t, serve(100) == 0)
+	expectTrue(t, probability == 0)
+
+	// at 1.5x the target, half of the max probability.
+	latency = 150 * time.Millisecond
+	serve(20)
+	expectTrue(t, probability > 0.44 && probability <= 0.45)
+	expectTrue(t, serve(200) > 0)
+
+	// far above the target, capped.
+	latency = time.Second
+	serve(50)
+	expectTrue(t, probability == 0.9)
+	rejected := serve(1000)
+	expectTrue(t, rejected > 800 && rejected < 1000)
+
+	// recovery: the requests that still flow bring the average down.
+	latency = 10 * time.Millisecond
+	serve(500)
+	expectTrue(t, probability == 0)
+	expectTrue(t, serve(100) == 0)
+}
+
+func TestAdaptiveShed_InvalidTarget(t *testing.T) {
+	defer func() {
+		expectTrue(t, recover() != nil)
+	}()
+	AdaptiveShed(AdaptiveConfig{})
+}
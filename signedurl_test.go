@@ -0,0 +1,54 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedURL(t *testing.T) {
+	secret := []byte("s3cret")
+	clock := newFakeClock()
+
+	mux := NewServeMux()
+	mux.Route(Route{Method: "GET", Path: "/files/:name", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(200)
+		return nil
+	}}, SignedURLWithConfig(SignedURLConfig{Secret: secret, TTL: 24 * time.Hour, Clock: clock}))
+
+	status := func(target string) int {
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, httptest.NewRequest("GET", target, nil))
+		return res.Code
+	}
+
+	valid := SignURL(secret, "/files/report.pdf?download=1", clock.Now().Add(time.Hour))
+	expectTrue(t, strings.HasPrefix(valid, "/files/report.pdf?download=1&expires="))
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{name: "valid", target: valid, status: 200},
+		{name: "unsigned", target: "/files/report.pdf", status: 403},
+		{name: "tampered path", target: strings.Replace(valid, "report", "secret", 1), status: 403},
+		{name: "tampered query", target: strings.Replace(valid, "download=1", "download=2", 1), status: 403},
+		{name: "extra query", target: valid + "&admin=1", status: 403},
+		{name: "wrong secret", target: SignURL([]byte("other"), "/files/report.pdf", clock.Now().Add(time.Hour)), status: 403},
+		{name: "too long", target: SignURL(secret, "/files/report.pdf", clock.Now().Add(48*time.Hour)), status: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectTrue(t, status(tt.target) == tt.status)
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		clock.Advance(time.Hour)
+		expectTrue(t, status(valid) == 403)
+	})
+}
@@ -0,0 +1,106 @@
+package httprouterx
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// SLOConfig is the configuration for SLO.
+type SLOConfig struct {
+	// Objective is the target ratio of successful requests, e.g. 0.999. Default 0.99.
+	Objective float64
+
+	// Window is the duration of the rolling window. Default 5 minutes.
+	Window time.Duration
+
+	// Buckets is the number of buckets the window is divided into, which is the resolution of the rolling window.
+	// Default 60.
+	Buckets int
+
+	// MinRequests is the minimum number of requests in the window before the budget can be considered exhausted,
+	// to avoid reacting to a handful of failures. Default 20.
+	MinRequests int
+
+	// IsFailure classifies a response. Default: a returned error or a 5xx status.
+	IsFailure func(status int, err error) bool
+
+	// ShedFraction is the fraction of requests (between 0 and 1) rejected with 503 while the error budget is
+	// exhausted. Default 0, which disables the load shedding.
+	ShedFraction float64
+
+	// Clock is used to place the requests in the window. Default SystemClock.
+	Clock Clock
+}
+
+// SLO tracks the error budget of the service level objective over a rolling window, and optionally sheds load
+// while the budget is exhausted, giving the service some room to recover. The returned tracker exposes the
+// current burn rate for monitoring.
+//
+// Rejected requests are not counted in the window.
+func SLO(cfg SLOConfig) (Middleware, *SLOTracker) {
+	if cfg.Objective <= 0 || cfg.Objective >= 1 {
+		cfg.Objective = 0.99
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 5 * time.Minute
+	}
+	if cfg.Buckets <= 0 {
+		cfg.Buckets = 60
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 20
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(status int, err error) bool { return err != nil || status >= 500 }
+	}
+
+	tracker := &SLOTracker{
+		objective:   cfg.Objective,
+		minRequests: cfg.MinRequests,
+		window:      newRollingWindow(cfg.Window, cfg.Buckets, clockOrSystem(cfg.Clock)),
+	}
+
+	mid := func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if cfg.ShedFraction > 0 && tracker.Exhausted() && rand.Float64() < cfg.ShedFraction {
+				return NewHTTPError(http.StatusServiceUnavailable, "error budget exhausted")
+			}
+
+			sw := newStatusWriter(w)
+			err := next.ServeHTTP(sw, r)
+			tracker.window.add(cfg.IsFailure(sw.statusCode(), err))
+			return err
+		})
+	}
+	return mid, tracker
+}
+
+// SLOTracker tracks the error budget of an SLO. It is safe for concurrent use.
+type SLOTracker struct {
+	objective   float64
+	minRequests int
+	window      *rollingWindow
+}
+
+// ErrorRate returns the ratio of failed requests in the current window.
+func (t *SLOTracker) ErrorRate() float64 {
+	total, failed := t.window.counts()
+	if total == 0 {
+		return 0
+	}
+	return float64(failed) / float64(total)
+}
+
+// BurnRate returns how fast the error budget is being consumed: the error rate divided by the allowed error
+// rate (1 - objective). A burn rate of 1 consumes exactly the budget, above 1 the budget runs out.
+func (t *SLOTracker) BurnRate() float64 {
+	return t.ErrorRate() / (1 - t.objective)
+}
+
+// Exhausted reports whether the window has enough requests and the burn rate is above 1.
+func (t *SLOTracker) Exhausted() bool {
+	total, _ := t.window.counts()
+	// the epsilon absorbs the rounding of 1 - objective, so consuming exactly the budget is not exhaustion.
+	return total >= t.minRequests && t.BurnRate() > 1+1e-9
+}
@@ -0,0 +1,67 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSLO(t *testing.T) {
+	clock := newFakeClock()
+	mid, tracker := SLO(SLOConfig{
+		Objective:    0.9,
+		Window:       time.Minute,
+		Buckets:      6,
+		MinRequests:  10,
+		ShedFraction: 1,
+		Clock:        clock,
+	})
+
+	status := 200
+	h := mid.Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(status)
+		return nil
+	}))
+
+	serve := func() int {
+		res := httptest.NewRecorder()
+		if err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil)); err != nil {
+			return expectHTTPError(t, err, http.StatusServiceUnavailable).Status
+		}
+		return res.Code
+	}
+
+	for i := 0; i < 9; i++ {
+		expectTrue(t, serve() == 200)
+	}
+	status = 500
+	expectTrue(t, serve() == 500)
+	expectTrue(t, tracker.ErrorRate() == 0.1)
+	expectFalse(t, tracker.Exhausted())
+
+	expectTrue(t, serve() == 500)
+	expectTrue(t, tracker.BurnRate() > 1)
+	expectTrue(t, tracker.Exhausted())
+
+	// the budget is exhausted, so requests are shed without being counted.
+	expectTrue(t, serve() == http.StatusServiceUnavailable)
+	expectTrue(t, tracker.ErrorRate() == 2.0/11.0)
+
+	// failures leave the window.
+	clock.Advance(2 * time.Minute)
+	expectTrue(t, tracker.ErrorRate() == 0)
+	expectFalse(t, tracker.Exhausted())
+	status = 200
+	expectTrue(t, serve() == 200)
+}
+
+func TestSLO_CustomClassification(t *testing.T) {
+	mid, tracker := SLO(SLOConfig{IsFailure: func(status int, err error) bool { return status == 429 }})
+	h := mid.Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(429)
+		return nil
+	}))
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, tracker.ErrorRate() == 1)
+}
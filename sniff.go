@@ -0,0 +1,67 @@
+package httprouterx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// maxSniffWhitespace is the maximum leading whitespace skipped before the first significant byte of the body.
+const maxSniffWhitespace = 512
+
+// VerifyBodyMatchesContentType rejects, with 400 Bad Request, requests claiming a JSON Content-Type whose body
+// does not start (after whitespace) with { or [, e.g. HTML or form data posted as JSON. This catches malformed or
+// malicious payloads before the handler decodes them.
+//
+// Only the leading bytes are read, and they are restored, so the handler reads the whole body as usual.
+// Empty bodies are passed through.
+func VerifyBodyMatchesContentType() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Body == nil || r.Body == http.NoBody || !isJSONContentType(r.Header.Get("Content-Type")) {
+				return next.ServeHTTP(w, r)
+			}
+
+			peeked, first, err := peekSignificantByte(r.Body)
+			if err != nil {
+				return err
+			}
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(peeked), r.Body), Closer: r.Body}
+
+			if len(peeked) > 0 && first != '{' && first != '[' {
+				return NewHTTPError(http.StatusBadRequest, "request body does not match the JSON content type")
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// peekSignificantByte reads until the first non-whitespace byte, and returns everything read so far.
+// It stops after maxSniffWhitespace bytes, returning the last byte read.
+func peekSignificantByte(r io.Reader) ([]byte, byte, error) {
+	var (
+		peeked []byte
+		b      [1]byte
+	)
+	for len(peeked) <= maxSniffWhitespace {
+		n, err := r.Read(b[:])
+		if n == 1 {
+			peeked = append(peeked, b[0])
+			switch b[0] {
+			case ' ', '\t', '\r', '\n':
+				continue
+			}
+			return peeked, b[0], nil
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return peeked, 0, err
+		}
+	}
+	if len(peeked) == 0 {
+		return nil, 0, nil
+	}
+	return peeked, peeked[len(peeked)-1], nil
+}
@@ -0,0 +1,46 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyBodyMatchesContentType(t *testing.T) {
+	var received string
+	h := VerifyBodyMatchesContentType().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		b, err := io.ReadAll(r.Body)
+		received = string(b)
+		return err
+	}))
+
+	tests := []struct {
+		contentType string
+		body        string
+		ok          bool
+	}{
+		{contentType: "application/json", body: `{"a":1}`, ok: true},
+		{contentType: "application/json; charset=utf-8", body: " \n\t[1,2]", ok: true},
+		{contentType: "application/vnd.api+json", body: `{}`, ok: true},
+		{contentType: "application/json", body: "", ok: true},
+		{contentType: "text/html", body: "<html></html>", ok: true},
+		{contentType: "application/json", body: "<html></html>", ok: false},
+		{contentType: "application/json", body: "a=1&b=2", ok: false},
+		{contentType: "application/json", body: strings.Repeat(" ", 1000) + "{}", ok: false},
+	}
+
+	for _, tt := range tests {
+		received = ""
+		req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		err := h.ServeHTTP(httptest.NewRecorder(), req)
+		if !tt.ok {
+			expectHTTPError(t, err, 400)
+			continue
+		}
+		expectTrue(t, err == nil)
+		expectTrue(t, received == tt.body)
+	}
+}
@@ -0,0 +1,104 @@
+package httprouterx
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"math/rand"
+	"net/http"
+)
+
+// Variant is a target of TrafficSplit.
+type Variant struct {
+	// Name identifies the variant in the logs and the OnSelect hook, e.g. "v2".
+	Name string
+
+	// Weight is the share of the traffic the variant gets, relative to the other variants, e.g. 90 and 10.
+	// A zero weight disables the variant.
+	Weight int
+
+	// Handler serves the requests of the variant. Nil means the handler wrapped by the middleware, which is
+	// typically the current version.
+	Handler Handler
+}
+
+// TrafficSplitConfig is the configuration for TrafficSplitWithConfig.
+type TrafficSplitConfig struct {
+	Variants []Variant
+
+	// Key gets the client key used for sticky assignment: the requests with the same key always get the same
+	// variant, as long as the variants and their weights do not change. Requests with an empty key, or all of them
+	// if Key is nil, get a random variant.
+	Key func(*http.Request) string
+
+	// Logger receives a debug record with the chosen variant for each request. Default slog.Default().
+	Logger *slog.Logger
+
+	// OnSelect is called with the chosen variant for each request, e.g. to count them in a metric.
+	OnSelect func(r *http.Request, variant string)
+}
+
+// TrafficSplit sends each request to one of the variants, chosen at random according to the weights, to run
+// several versions side by side at the same path, e.g. a 90/10 canary. See TrafficSplitWithConfig for sticky
+// assignment.
+func TrafficSplit(variants []Variant) Middleware {
+	return TrafficSplitWithConfig(TrafficSplitConfig{Variants: variants})
+}
+
+// TrafficSplitWithConfig is like TrafficSplit, but with sticky assignment and a hook. The sticky variant is
+// chosen from the FNV-1a hash of the key, so it is stable across processes and restarts.
+//
+// It panics if there are no variants, a weight is negative, or all the weights are zero.
+func TrafficSplitWithConfig(cfg TrafficSplitConfig) Middleware {
+	total := 0
+	for _, v := range cfg.Variants {
+		if v.Weight < 0 {
+			panic("httprouterx: TrafficSplit: negative weight for variant " + v.Name)
+		}
+		total += v.Weight
+	}
+	if total == 0 {
+		panic("httprouterx: TrafficSplit: no variant with a positive weight")
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			var n int
+			if key := splitKey(cfg.Key, r); key != "" {
+				h := fnv.New64a()
+				_, _ = h.Write([]byte(key))
+				n = int(h.Sum64() % uint64(total))
+			} else {
+				n = rand.Intn(total)
+			}
+
+			variant := cfg.Variants[0]
+			for _, v := range cfg.Variants {
+				if n < v.Weight {
+					variant = v
+					break
+				}
+				n -= v.Weight
+			}
+
+			cfg.Logger.DebugContext(r.Context(), "traffic split", "variant", variant.Name, "method", r.Method, "path", r.URL.Path)
+			if cfg.OnSelect != nil {
+				cfg.OnSelect(r, variant.Name)
+			}
+			if variant.Handler == nil {
+				return next.ServeHTTP(w, r)
+			}
+			return variant.Handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// splitKey gets the sticky key of the request, if any.
+func splitKey(key func(*http.Request) string, r *http.Request) string {
+	if key == nil {
+		return ""
+	}
+	return key(r)
+}
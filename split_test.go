@@ -0,0 +1,67 @@
+package httprouterx
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestTrafficSplit(t *testing.T) {
+	var v2 int
+	h := TrafficSplit([]Variant{
+		{Name: "v1", Weight: 90},
+		{Name: "v2", Weight: 10, Handler: HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			v2++
+			return nil
+		})},
+		{Name: "disabled", Weight: 0, Handler: HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			t.Fatal("a disabled variant must not be selected")
+			return nil
+		})},
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	const requests = 20000
+	for i := 0; i < requests; i++ {
+		_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	ratio := float64(v2) / requests
+	expectTrue(t, math.Abs(ratio-0.1) < 0.02)
+}
+
+func TestTrafficSplitWithConfig_Sticky(t *testing.T) {
+	selected := make(map[string]string)
+	counts := make(map[string]int)
+	h := TrafficSplitWithConfig(TrafficSplitConfig{
+		Variants: []Variant{{Name: "v1", Weight: 50}, {Name: "v2", Weight: 50}},
+		Key:      func(r *http.Request) string { return r.Header.Get("X-User") },
+		OnSelect: func(r *http.Request, variant string) {
+			user := r.Header.Get("X-User")
+			if prev, ok := selected[user]; ok {
+				expectTrue(t, prev == variant)
+			}
+			selected[user] = variant
+			counts[variant]++
+		},
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	for round := 0; round < 3; round++ {
+		for user := 0; user < 1000; user++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-User", "user-"+strconv.Itoa(user))
+			_ = h.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	}
+	expectTrue(t, len(selected) == 1000)
+	expectTrue(t, counts["v1"] > 1200 && counts["v2"] > 1200)
+}
+
+func TestTrafficSplit_Invalid(t *testing.T) {
+	for _, variants := range [][]Variant{nil, {{Name: "a", Weight: 0}}, {{Name: "a", Weight: -1}, {Name: "b", Weight: 2}}} {
+		func() {
+			defer func() { expectTrue(t, recover() != nil) }()
+			TrafficSplit(variants)
+		}()
+	}
+}
@@ -0,0 +1,140 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flushRecorder is a thread-safe recorder that counts the flushes.
+type flushRecorder struct {
+	mu      sync.Mutex
+	header  http.Header
+	body    strings.Builder
+	flushed string
+	flushes int
+}
+
+func (f *flushRecorder) Header() http.Header { return f.header }
+func (f *flushRecorder) WriteHeader(int)     {}
+
+func (f *flushRecorder) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.body.Write(p)
+}
+
+func (f *flushRecorder) Flush() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	f.flushed = f.body.String()
+}
+
+func (f *flushRecorder) snapshot() (string, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushed, f.flushes
+}
+
+func TestStreamWriter(t *testing.T) {
+	rec := &flushRecorder{header: make(http.Header)}
+	s, err := NewStreamWriter(rec, 20*time.Millisecond)
+	expectTrue(t, err == nil)
+
+	_, _ = s.Write([]byte("a\n"))
+	_, _ = s.Write([]byte("b\n"))
+	flushed, flushes := rec.snapshot()
+	expectTrue(t, flushed == "" && flushes == 0)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for flushed == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		flushed, _ = rec.snapshot()
+	}
+	expectTrue(t, flushed == "a\nb\n")
+
+	_, _ = s.Write([]byte("c\n"))
+	expectTrue(t, s.Close() == nil)
+	flushed, _ = rec.snapshot()
+	expectTrue(t, flushed == "a\nb\nc\n")
+
+	_, err = s.Write([]byte("d\n"))
+	expectTrue(t, errors.Is(err, ErrStreamClosed))
+	expectTrue(t, errors.Is(s.Close(), ErrStreamClosed))
+}
+
+func TestStreamWriter_BufferFull(t *testing.T) {
+	rec := &flushRecorder{header: make(http.Header)}
+	s, err := NewStreamWriter(rec, time.Hour)
+	expectTrue(t, err == nil)
+	defer s.Close()
+
+	_, err = s.Write([]byte(strings.Repeat("x", DefaultStreamBufferSize+1)))
+	expectTrue(t, err == nil)
+	flushed, flushes := rec.snapshot()
+	expectTrue(t, flushes == 1)
+	expectTrue(t, len(flushed) >= DefaultStreamBufferSize)
+}
+
+func TestStreamWriter_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := NewStreamWriterContext(ctx, &flushRecorder{header: make(http.Header)}, time.Hour)
+	expectTrue(t, err == nil)
+
+	cancel()
+	_, err = s.Write([]byte("a"))
+	expectTrue(t, errors.Is(err, context.Canceled))
+	expectTrue(t, errors.Is(s.Close(), context.Canceled))
+}
+
+func TestStreamWriter_NonPositiveInterval(t *testing.T) {
+	for _, every := range []time.Duration{0, -time.Second} {
+		rec := &flushRecorder{header: make(http.Header)}
+		s, err := NewStreamWriter(rec, every)
+		expectTrue(t, err == nil)
+
+		_, _ = s.Write([]byte("a\n"))
+		eventually(t, func() bool {
+			flushed, _ := rec.snapshot()
+			return flushed == "a\n"
+		})
+		expectTrue(t, s.Close() == nil)
+	}
+}
+
+func TestStreamWriter_NotFlusher(t *testing.T) {
+	_, err := NewStreamWriter(discardResponseWriter{header: make(http.Header)}, time.Second)
+	expectTrue(t, errors.Is(err, http.ErrNotSupported))
+}
+
+func BenchmarkStreamWriter(b *testing.B) {
+	record := []byte(`{"level":"info","msg":"something happened"}` + "\n")
+
+	b.Run("flush every write", func(b *testing.B) {
+		w := httptest.NewRecorder()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = w.Write(record)
+			w.Flush()
+			if w.Body.Len() > 1<<20 {
+				w.Body.Reset()
+			}
+		}
+	})
+
+	b.Run("stream writer", func(b *testing.B) {
+		w := httptest.NewRecorder()
+		s, _ := NewStreamWriter(w, 50*time.Millisecond)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = s.Write(record)
+		}
+		_ = s.Close()
+	})
+}
@@ -0,0 +1,79 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StripPrefix makes the ServeMux remove the prefix from the path of the requests before routing them, like
+// http.StripPrefix, for a mux mounted behind a reverse proxy that forwards /api/users/42 for /users/42. The
+// routes are registered without the prefix, and their path params resolve against the stripped path.
+//
+// It is an option of the mux rather than a Middleware, because the middlewares run after the routing, once the
+// route is matched against the full path. Requests whose path does not start with the prefix followed by a slash
+// (or nothing) get the NotFound handler, without the OnResponse hooks. The redirects of the router, see
+// RedirectTrailingSlash and RedirectFixedPath, get the prefix back in their Location, so the client stays behind
+// the proxy. A trailing slash of the prefix is ignored.
+func (nsOpts) StripPrefix(prefix string) Option {
+	return func(mux *ServeMux) { mux.stripPrefix = strings.TrimSuffix(prefix, "/") }
+}
+
+// stripRequest returns the request without the prefix, and the writer to use for it. It returns false if the
+// path does not have the prefix.
+func (mux *ServeMux) stripRequest(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request, bool) {
+	path, ok := cutPathPrefix(r.URL.Path, mux.stripPrefix)
+	if !ok {
+		return w, r, false
+	}
+	rawPath := r.URL.RawPath
+	if rawPath != "" {
+		if rawPath, ok = cutPathPrefix(rawPath, mux.stripPrefix); !ok {
+			return w, r, false
+		}
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path, r2.URL.RawPath = path, rawPath
+
+	// only the router redirects when no route matches, the handlers keep their Location as is.
+	if h, _, _ := mux.core.Lookup(r2.Method, r2.URL.Path); h == nil {
+		w = &prefixRedirectWriter{ResponseWriter: w, prefix: mux.stripPrefix}
+	}
+	return w, r2, true
+}
+
+// cutPathPrefix removes the prefix from the path, which must be followed by a slash or nothing. An empty
+// remainder is the root.
+func cutPathPrefix(path, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok || rest != "" && rest[0] != '/' {
+		return "", false
+	}
+	if rest == "" {
+		rest = "/"
+	}
+	return rest, true
+}
+
+// prefixRedirectWriter adds the prefix back to the Location of the redirects.
+type prefixRedirectWriter struct {
+	http.ResponseWriter
+	prefix string
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *prefixRedirectWriter) WriteHeader(status int) {
+	if status >= 300 && status <= 399 {
+		if loc := w.Header().Get("Location"); strings.HasPrefix(loc, "/") && !strings.HasPrefix(loc, "//") {
+			w.Header().Set("Location", w.prefix+loc)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *prefixRedirectWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,45 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptions_StripPrefix(t *testing.T) {
+	mux := NewServeMux(Options.StripPrefix("/api/"))
+	mux.HandleFunc("GET", "/users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.WriteString(w, r.URL.Path+" "+PathParams(r).ByName("id")+" "+MatchedPath(r))
+		return err
+	})
+	mux.HandleFunc("GET", "/", func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.WriteString(w, "root")
+		return err
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/users/42", nil))
+	expectTrue(t, rec.Code == http.StatusOK)
+	expectTrue(t, rec.Body.String() == "/users/42 42 /users/:id")
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api", nil))
+	expectTrue(t, rec.Body.String() == "root")
+
+	for _, path := range []string{"/users/42", "/apiv2/users/42"} {
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		expectTrue(t, rec.Code == http.StatusNotFound)
+	}
+
+	// the redirects of the router keep the prefix, such as the ones of RedirectTrailingSlash.
+	mux = NewServeMux(
+		Options.StripPrefix("/api"),
+		Options.NotFoundHandler(http.RedirectHandler("/users", http.StatusMovedPermanently)),
+	)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/users/", nil))
+	expectTrue(t, rec.Code == http.StatusMovedPermanently)
+	expectTrue(t, rec.Header().Get("Location") == "/api/users")
+}
@@ -0,0 +1,94 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Tenant resolves the tenant of the request and stores its ID in the context, so the following middlewares and
+// handlers can scope their data with TenantID. This keeps the tenant resolution in one place.
+//
+// Errors returned by resolve are mapped to 400 Bad Request, unless they are already an *HTTPError. If resolve
+// returns an empty ID, the request is rejected with 404 Not Found. The ID must be 1 to 63 characters long and
+// only contain ASCII letters, digits, '-' and '_', otherwise the request is rejected with 400.
+//
+// SubdomainTenant and HeaderTenant are ready-to-use resolvers.
+func Tenant(resolve func(*http.Request) (string, error)) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			id, err := resolve(r)
+			if err != nil {
+				var httpErr *HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				return &HTTPError{Status: http.StatusBadRequest, Message: "invalid tenant", Err: err}
+			}
+			if id == "" {
+				return NewHTTPError(http.StatusNotFound, "tenant not found")
+			}
+			if !isValidTenantID(id) {
+				return NewHTTPError(http.StatusBadRequest, "invalid tenant")
+			}
+			return next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey, id)))
+		})
+	}
+}
+
+// TenantID gets the tenant ID resolved by the Tenant middleware.
+// It returns an empty string if the middleware was not applied.
+func TenantID(r *http.Request) string {
+	id, _ := r.Context().Value(tenantKey).(string)
+	return id
+}
+
+// SubdomainTenant resolves the tenant from the subdomain of the base domain, e.g. acme for
+// acme.example.com when the base domain is example.com. Requests to the base domain itself resolve to no tenant,
+// and requests to other domains or to nested subdomains fail.
+func SubdomainTenant(baseDomain string) func(*http.Request) (string, error) {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+	return func(r *http.Request) (string, error) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+		if host == suffix[1:] {
+			return "", nil
+		}
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" {
+			return "", fmt.Errorf("host %q is not a subdomain of %q", host, suffix[1:])
+		}
+		if strings.Contains(sub, ".") {
+			return "", fmt.Errorf("host %q has nested subdomains", host)
+		}
+		return sub, nil
+	}
+}
+
+// HeaderTenant resolves the tenant from the given request header, e.g. X-Tenant-ID.
+func HeaderTenant(name string) func(*http.Request) (string, error) {
+	return func(r *http.Request) (string, error) {
+		return strings.TrimSpace(r.Header.Get(name)), nil
+	}
+}
+
+func isValidTenantID(id string) bool {
+	if len(id) > 63 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTenant_Subdomain(t *testing.T) {
+	var tenant string
+	h := Tenant(SubdomainTenant("example.com")).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		tenant = TenantID(r)
+		return nil
+	}))
+
+	tests := []struct {
+		host   string
+		tenant string
+		status int
+	}{
+		{host: "acme.example.com", tenant: "acme"},
+		{host: "ACME.example.com:8080", tenant: "acme"},
+		{host: "example.com", status: 404},
+		{host: "a.b.example.com", status: 400},
+		{host: "acme.other.com", status: 400},
+		{host: "evil-example.com", status: 400},
+	}
+
+	for _, tt := range tests {
+		tenant = ""
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = tt.host
+		err := h.ServeHTTP(httptest.NewRecorder(), req)
+		if tt.status != 0 {
+			expectHTTPError(t, err, tt.status)
+			continue
+		}
+		expectTrue(t, err == nil)
+		expectTrue(t, tenant == tt.tenant)
+	}
+}
+
+func TestTenant_Header(t *testing.T) {
+	var tenant string
+	h := Tenant(HeaderTenant("X-Tenant-ID")).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		tenant = TenantID(r)
+		return nil
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Tenant-ID", "tenant_1")
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), req) == nil)
+	expectTrue(t, tenant == "tenant_1")
+
+	req.Header.Set("X-Tenant-ID", "../admin")
+	expectHTTPError(t, h.ServeHTTP(httptest.NewRecorder(), req), 400)
+
+	req.Header.Del("X-Tenant-ID")
+	expectHTTPError(t, h.ServeHTTP(httptest.NewRecorder(), req), 404)
+
+	expectTrue(t, TenantID(httptest.NewRequest("GET", "/", nil)) == "")
+}
@@ -0,0 +1,19 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http/httptest"
+)
+
+// TestRequest serves a request built with httptest.NewRequest and returns the recorded response, which makes
+// the tests of the routes shorter:
+//
+//	res := mux.TestRequest("GET", "/users/1", nil)
+//
+// The request is served like any other, through the middlewares and the handlers of the mux. It panics if the
+// method or the target is invalid, like httptest.NewRequest. See WithPathParams to test a single handler instead.
+func (mux *ServeMux) TestRequest(method, target string, body io.Reader) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest(method, target, body))
+	return res
+}
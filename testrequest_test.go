@@ -0,0 +1,43 @@
+package httprouterx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestServeMux_TestRequest(t *testing.T) {
+	mux := NewServeMux()
+	mux.POST("/echo/:name", func(w http.ResponseWriter, r *http.Request) error {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, PathParams(r).ByName("name")+": "+string(body))
+		return nil
+	})
+
+	res := mux.TestRequest("POST", "/echo/ada", strings.NewReader("hello"))
+	expectTrue(t, res.Code == http.StatusCreated)
+	expectTrue(t, res.Body.String() == "ada: hello")
+
+	res = mux.TestRequest("GET", "/missing", nil)
+	expectTrue(t, res.Code == http.StatusNotFound)
+}
+
+func TestWithPathParams(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		id, err := PathParamInt(r, "id")
+		if err != nil {
+			return err
+		}
+		_, _ = io.WriteString(w, PathParams(r).ByName("name")+"#"+strconv.Itoa(id))
+		return nil
+	})
+
+	res := httptest.NewRecorder()
+	r := WithPathParams(httptest.NewRequest("GET", "/", nil), Param{Key: "id", Value: "7"}, Param{Key: "name", Value: "ada"})
+	expectTrue(t, handler.ServeHTTP(res, r) == nil)
+	expectTrue(t, res.Body.String() == "ada#7")
+}
@@ -0,0 +1,152 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrHandlerTimeout is returned by the writes of a handler that has timed out, and is the underlying error of
+// the *HTTPError returned by TimeoutMiddleware. It is http.ErrHandlerTimeout, so both can be matched with
+// errors.Is.
+var ErrHandlerTimeout = http.ErrHandlerTimeout
+
+// TimeoutConfig is the configuration for TimeoutMiddlewareWithConfig.
+type TimeoutConfig struct {
+	// Timeout is the time the handler has to finish.
+	Timeout time.Duration
+
+	// Status is the status of the timeout error. Default 503 Service Unavailable, e.g. 504 Gateway Timeout for a
+	// proxy.
+	Status int
+}
+
+// TimeoutMiddleware gives the handler at most d to finish: its request context is cancelled after d and, if it has
+// not returned by then, the middleware returns an *HTTPError with status 503 wrapping ErrHandlerTimeout, without
+// waiting for it. See TimeoutMiddlewareWithConfig for the details.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return TimeoutMiddlewareWithConfig(TimeoutConfig{Timeout: d})
+}
+
+// TimeoutMiddlewareWithConfig is like TimeoutMiddleware, but with a custom status.
+//
+// The handler runs in its own goroutine, with a guarded response writer: once the timeout has fired, its writes
+// fail with ErrHandlerTimeout instead of reaching the client, so the response is never written twice. The handler
+// should watch its context to stop early and free its resources. If the handler had already started its
+// response when the timeout fired, the response cannot be replaced and the error is ErrHandlerTimeout, as is.
+//
+// A panic of the handler is propagated to the calling goroutine, so the recovery middlewares still see it, unless
+// it happens after the timeout.
+// The writer passed to the handler supports http.Flusher, but not http.Hijacker.
+func TimeoutMiddlewareWithConfig(cfg TimeoutConfig) Middleware {
+	if cfg.Status == 0 {
+		cfg.Status = http.StatusServiceUnavailable
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ctx, cancel := context.WithTimeout(r.Context(), cfg.Timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{w: w, header: w.Header().Clone()}
+			done := make(chan error, 1)
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if v := recover(); v != nil {
+						panicked <- v
+					}
+				}()
+				done <- next.ServeHTTP(tw, r.WithContext(ctx))
+			}()
+
+			select {
+			case v := <-panicked:
+				panic(v)
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				switch {
+				case !errors.Is(ctx.Err(), context.DeadlineExceeded):
+					return ctx.Err()
+				case tw.wroteHeader:
+					return ErrHandlerTimeout
+				default:
+					return &HTTPError{Status: cfg.Status, Message: "request timed out", Err: ErrHandlerTimeout}
+				}
+			}
+		})
+	}
+}
+
+// timeoutWriter guards the response writer of TimeoutMiddleware. The handler gets its own header map, copied to
+// the response when it is started, so a late handler never touches the real one.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header implements http.ResponseWriter.
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+// WriteHeader implements http.ResponseWriter. It is a no-op after the timeout.
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.writeHeaderLocked(status)
+	}
+}
+
+// Write implements http.ResponseWriter. It fails with ErrHandlerTimeout after the timeout.
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.w.Write(p)
+}
+
+// Flush implements http.Flusher. It is a no-op after the timeout, or if the underlying writer is not an
+// http.Flusher.
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if f, ok := tw.w.(http.Flusher); ok && !tw.timedOut {
+		if !tw.wroteHeader {
+			tw.writeHeaderLocked(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// writeHeaderLocked copies the headers and writes the status. Informational (1xx) statuses are passed through
+// without starting the response.
+func (tw *timeoutWriter) writeHeaderLocked(status int) {
+	if tw.wroteHeader {
+		return
+	}
+	dst := tw.w.Header()
+	for k := range dst {
+		if _, ok := tw.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range tw.header {
+		dst[k] = append([]string(nil), v...)
+	}
+	tw.w.WriteHeader(status)
+	tw.wroteHeader = status < 100 || status > 199
+}
@@ -0,0 +1,83 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	late := make(chan error, 1)
+	cancelled := make(chan struct{})
+	h := TimeoutMiddleware(20 * time.Millisecond).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		close(cancelled)
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("X-Late", "1")
+		_, err := w.Write([]byte("too late"))
+		late <- err
+		return err
+	}))
+
+	res := httptest.NewRecorder()
+	err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	httpErr := expectHTTPError(t, err, http.StatusServiceUnavailable)
+	expectTrue(t, errors.Is(httpErr, ErrHandlerTimeout))
+
+	<-cancelled
+	expectTrue(t, errors.Is(<-late, ErrHandlerTimeout))
+	expectTrue(t, res.Body.Len() == 0)
+	expectTrue(t, res.Header().Get("X-Late") == "")
+}
+
+func TestTimeoutMiddlewareWithConfig(t *testing.T) {
+	slow := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		return r.Context().Err()
+	})
+	h := TimeoutMiddlewareWithConfig(TimeoutConfig{Timeout: 10 * time.Millisecond, Status: http.StatusGatewayTimeout}).Then(slow)
+	expectHTTPError(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)), http.StatusGatewayTimeout)
+
+	// in time.
+	h = TimeoutMiddleware(time.Second).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Handler", "1")
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte("ok"))
+		return err
+	}))
+	res := httptest.NewRecorder()
+	expectTrue(t, h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil)) == nil)
+	expectTrue(t, res.Code == http.StatusCreated)
+	expectTrue(t, res.Body.String() == "ok")
+	expectTrue(t, res.Header().Get("X-Handler") == "1")
+
+	// the response already started.
+	h = TimeoutMiddleware(10 * time.Millisecond).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("partial"))
+		<-r.Context().Done()
+		return nil
+	}))
+	res = httptest.NewRecorder()
+	err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, err == ErrHandlerTimeout)
+	expectTrue(t, res.Body.String() == "partial")
+
+	// the client went away.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h = TimeoutMiddleware(time.Second).Then(slow)
+	err = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	expectTrue(t, errors.Is(err, context.Canceled))
+}
+
+func TestTimeoutMiddleware_Panic(t *testing.T) {
+	h := TimeoutMiddleware(time.Second).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}))
+	defer func() { expectTrue(t, recover() == "boom") }()
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	t.Fatal("the panic must be propagated")
+}
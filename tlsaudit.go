@@ -0,0 +1,71 @@
+package httprouterx
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// TLSAuditConfig is the configuration for TLSAuditWithConfig.
+type TLSAuditConfig struct {
+	// Log is called with the negotiated connection state of every TLS request. Optional.
+	Log func(*http.Request, *tls.ConnectionState)
+
+	// MinVersion is the minimum accepted TLS version, e.g. tls.VersionTLS12.
+	// Requests negotiated below it are rejected with 426 Upgrade Required. Zero accepts any version.
+	MinVersion uint16
+
+	// RejectPlaintext rejects requests without TLS with 426 Upgrade Required. Leave it disabled when TLS is
+	// terminated by a proxy in front of the server.
+	RejectPlaintext bool
+}
+
+// TLSAudit logs the negotiated TLS version, cipher suite and SNI server name of every TLS request using log.
+// Plaintext requests pass through untouched. See TLSAuditWithConfig to enforce a minimum TLS version.
+func TLSAudit(log func(*http.Request, *tls.ConnectionState)) Middleware {
+	return TLSAuditWithConfig(TLSAuditConfig{Log: log})
+}
+
+// TLSAuditWithConfig is like TLSAudit, but it can also reject connections below the configured minimum
+// TLS version, or without TLS at all.
+func TLSAuditWithConfig(cfg TLSAuditConfig) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.TLS == nil {
+				if cfg.RejectPlaintext {
+					return NewHTTPError(http.StatusUpgradeRequired, "TLS is required")
+				}
+				return next.ServeHTTP(w, r)
+			}
+
+			if cfg.Log != nil {
+				cfg.Log(r, r.TLS)
+			}
+
+			if cfg.MinVersion != 0 && r.TLS.Version < cfg.MinVersion {
+				w.Header().Set("Upgrade", tlsUpgradeToken(cfg.MinVersion))
+				return NewHTTPError(http.StatusUpgradeRequired, "TLS version is not supported, minimum is "+tls.VersionName(cfg.MinVersion))
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tlsUpgradeToken returns the Upgrade protocol token of the TLS version, e.g. "TLS/1.2" (RFC 2817).
+func tlsUpgradeToken(version uint16) string {
+	return strings.Replace(tls.VersionName(version), " ", "/", 1)
+}
+
+// SlogTLSAudit returns a TLSAudit log function that writes the TLS details using the given logger.
+func SlogTLSAudit(logger *slog.Logger) func(*http.Request, *tls.ConnectionState) {
+	return func(r *http.Request, cs *tls.ConnectionState) {
+		logger.InfoContext(r.Context(), "tls connection",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"tls_version", tls.VersionName(cs.Version),
+			"cipher_suite", tls.CipherSuiteName(cs.CipherSuite),
+			"server_name", cs.ServerName,
+		)
+	}
+}
@@ -0,0 +1,76 @@
+package httprouterx
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTLSAudit(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	h := TLSAudit(SlogTLSAudit(logger)).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	req := httptest.NewRequest("GET", "https://api.example.com/", nil)
+	req.TLS.Version = tls.VersionTLS13
+	req.TLS.CipherSuite = tls.TLS_AES_128_GCM_SHA256
+	req.TLS.ServerName = "api.example.com"
+	err := h.ServeHTTP(httptest.NewRecorder(), req)
+	expectTrue(t, err == nil)
+
+	logged := buf.String()
+	expectTrue(t, strings.Contains(logged, `tls_version="TLS 1.3"`))
+	expectTrue(t, strings.Contains(logged, "cipher_suite=TLS_AES_128_GCM_SHA256"))
+	expectTrue(t, strings.Contains(logged, "server_name=api.example.com"))
+
+	buf.Reset()
+	err = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, err == nil)
+	expectTrue(t, buf.Len() == 0)
+}
+
+func TestTLSAuditWithConfig(t *testing.T) {
+	h := TLSAuditWithConfig(TLSAuditConfig{MinVersion: tls.VersionTLS12, RejectPlaintext: true}).
+		Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	tests := []struct {
+		name    string
+		version uint16
+		status  int
+		upgrade string
+	}{
+		{name: "plaintext", version: 0, status: http.StatusUpgradeRequired},
+		{name: "below minimum", version: tls.VersionTLS11, status: http.StatusUpgradeRequired, upgrade: "TLS/1.2"},
+		{name: "minimum", version: tls.VersionTLS12, status: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "https://example.com/", nil)
+			if tt.version == 0 {
+				req.TLS = nil
+			} else {
+				req.TLS.Version = tt.version
+			}
+
+			res := httptest.NewRecorder()
+			err := h.ServeHTTP(res, req)
+			expectTrue(t, res.Header().Get("Upgrade") == tt.upgrade)
+			if tt.status == 0 {
+				expectTrue(t, err == nil)
+				return
+			}
+			var httpErr *HTTPError
+			expectTrue(t, errors.As(err, &httpErr))
+			expectTrue(t, httpErr.Status == tt.status)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeTracer records the spans as "parent > name" when they start, and "end name: error" when they end.
+type fakeTracer struct {
+	events []string
+}
+
+type fakeSpanKey struct{}
+
+type fakeSpan struct {
+	tracer *fakeTracer
+	name   string
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(fakeSpanKey{}).(*fakeSpan)
+	if parent != nil {
+		t.events = append(t.events, parent.name+" > "+name)
+	} else {
+		t.events = append(t.events, "> "+name)
+	}
+	span := &fakeSpan{tracer: t, name: name}
+	return context.WithValue(ctx, fakeSpanKey{}, span), span
+}
+
+func (s *fakeSpan) End(err error) {
+	event := "end " + s.name
+	if err != nil {
+		event += ": " + err.Error()
+	}
+	s.tracer.events = append(s.tracer.events, event)
+}
+
+func TestInstrumentMiddleware(t *testing.T) {
+	tracer := &fakeTracer{}
+	stage := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				tracer.events = append(tracer.events, "run "+name)
+				return next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	mux := NewServeMux(Options.Middleware(TracingMiddleware(tracer)))
+	mux.Route(Route{Method: "GET", Path: "/users/:id", Handler: func(w http.ResponseWriter, r *http.Request) error {
+		tracer.events = append(tracer.events, "handler")
+		if r.URL.Path == "/users/0" {
+			return errors.New("boom")
+		}
+		return nil
+	}}, InstrumentMiddleware("auth", stage("auth")), InstrumentMiddleware("validate", stage("validate")))
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+	expectTrue(t, strings.Join(tracer.events, "\n") == strings.Join([]string{
+		"> GET /users/:id",
+		"GET /users/:id > auth",
+		"run auth",
+		"auth > validate",
+		"run validate",
+		"handler",
+		"end validate",
+		"end auth",
+		"end GET /users/:id",
+	}, "\n"))
+
+	tracer.events = nil
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/0", nil))
+	expectTrue(t, tracer.events[len(tracer.events)-3] == "end validate: boom")
+	expectTrue(t, tracer.events[len(tracer.events)-1] == "end GET /users/:id: boom")
+}
+
+func TestInstrumentMiddleware_WithoutTracer(t *testing.T) {
+	var ran bool
+	h := InstrumentMiddleware("stage", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ran = true
+			return next.ServeHTTP(w, r)
+		})
+	}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+
+	expectTrue(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)) == nil)
+	expectTrue(t, ran)
+}
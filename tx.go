@@ -0,0 +1,67 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Tx is a transaction, e.g. a *sql.Tx.
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// Transaction runs each request in its own transaction: it begins the transaction, stores it in the context for
+// the handler (see TxFromContext), then commits it if the handler succeeds, or rolls it back if the handler
+// returns an error or panics. The panic is propagated after the rollback.
+//
+// The response is buffered, and only sent once the transaction is committed: when Commit fails, the response is
+// discarded and the error is returned as is, so the client gets the error response instead of a success for a
+// rolled back write. An error from begin is returned as is too. A rollback error is joined to the handler error.
+//
+// A handler that calls DisableBuffering streams its response before the commit, so a failed commit can then only
+// be logged by the LastResortErrorHandler: the client has already been told the request succeeded.
+func Transaction(begin func(context.Context) (Tx, error)) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
+			tx, err := begin(r.Context())
+			if err != nil {
+				return err
+			}
+
+			committed := false
+			defer func() {
+				if committed {
+					return
+				}
+				if v := recover(); v != nil {
+					_ = tx.Rollback()
+					panic(v)
+				}
+				if rbErr := tx.Rollback(); rbErr != nil {
+					err = errors.Join(err, rbErr)
+				}
+			}()
+
+			buf, br := bufferResponse(w, r.WithContext(context.WithValue(r.Context(), txKey, tx)))
+			if err = next.ServeHTTP(buf, br); err != nil {
+				if buf.written() {
+					_ = buf.flushTo(w)
+				}
+				return err
+			}
+			committed = true
+			if err := tx.Commit(); err != nil {
+				return err // the buffered response is discarded.
+			}
+			return buf.flushTo(w)
+		})
+	}
+}
+
+// TxFromContext gets the transaction of the request started by Transaction, or nil if there is none.
+func TxFromContext(r *http.Request) Tx {
+	tx, _ := r.Context().Value(txKey).(Tx)
+	return tx
+}
@@ -0,0 +1,106 @@
+package httprouterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeTx struct {
+	committed, rolledBack bool
+	commitErr, rbErr      error
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return tx.rbErr
+}
+
+func TestTransaction(t *testing.T) {
+	anError := errors.New("an error")
+
+	t.Run("commit on success", func(t *testing.T) {
+		tx := &fakeTx{}
+		h := Transaction(func(ctx context.Context) (Tx, error) { return tx, nil }).
+			Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				expectTrue(t, TxFromContext(r) == tx)
+				w.WriteHeader(http.StatusCreated)
+				return nil
+			}))
+
+		res := httptest.NewRecorder()
+		err := h.ServeHTTP(res, httptest.NewRequest("POST", "/", nil))
+		expectTrue(t, err == nil)
+		expectTrue(t, res.Code == http.StatusCreated)
+		expectTrue(t, tx.committed)
+		expectFalse(t, tx.rolledBack)
+	})
+
+	t.Run("commit error", func(t *testing.T) {
+		tx := &fakeTx{commitErr: anError}
+		mux := NewServeMux(Options.Middleware(Transaction(func(ctx context.Context) (Tx, error) { return tx, nil })))
+		mux.POST("/", func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusCreated)
+			_, err := w.Write([]byte("created"))
+			return err
+		})
+
+		// the client gets the error, not the response of the rolled back write.
+		res := mux.TestRequest("POST", "/", nil)
+		expectTrue(t, res.Code == http.StatusInternalServerError)
+		expectFalse(t, strings.Contains(res.Body.String(), "created"))
+		expectTrue(t, tx.committed)
+		expectFalse(t, tx.rolledBack)
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		rbErr := errors.New("rollback failed")
+		tx := &fakeTx{rbErr: rbErr}
+		h := Transaction(func(ctx context.Context) (Tx, error) { return tx, nil }).
+			Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return anError }))
+
+		err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+		expectTrue(t, errors.Is(err, anError))
+		expectTrue(t, errors.Is(err, rbErr))
+		expectTrue(t, tx.rolledBack)
+		expectFalse(t, tx.committed)
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		tx := &fakeTx{}
+		h := Transaction(func(ctx context.Context) (Tx, error) { return tx, nil }).
+			Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { panic("boom") }))
+
+		func() {
+			defer func() { expectTrue(t, recover() == "boom") }()
+			_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+		}()
+		expectTrue(t, tx.rolledBack)
+		expectFalse(t, tx.committed)
+	})
+
+	t.Run("begin error", func(t *testing.T) {
+		called := false
+		h := Transaction(func(ctx context.Context) (Tx, error) { return nil, anError }).
+			Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				called = true
+				return nil
+			}))
+
+		err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+		expectTrue(t, errors.Is(err, anError))
+		expectFalse(t, called)
+	})
+}
+
+func TestTxFromContext_None(t *testing.T) {
+	expectTrue(t, TxFromContext(httptest.NewRequest("GET", "/", nil)) == nil)
+}
@@ -0,0 +1,64 @@
+package httprouterx
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// rfc3339Pattern matches the strings shaped like an RFC 3339 timestamp with a time zone.
+var rfc3339Pattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d{1,9})?(Z|[+-]\d{2}:\d{2})$`)
+
+// UTCTimestamps converts all the RFC 3339 timestamps of successful (2xx) JSON responses to UTC, e.g.
+// "2024-01-02T10:00:00+07:00" becomes "2024-01-02T03:00:00Z", so clients always get timestamps in the same zone
+// whatever the handler produced. The convention is: the API sends timestamps as RFC 3339 strings in UTC.
+//
+// Any string value shaped like an RFC 3339 timestamp, at any depth, is converted and keeps its fractional
+// seconds. Other strings, object keys, and strings that look like timestamps but are not valid dates are left
+// alone.
+//
+// It is opt-in since the whole response is buffered, decoded, scanned and encoded again, which costs memory and
+// CPU proportional to its size. Handlers that already format their times in UTC do not need it. The object keys
+// of the rewritten response are sorted, and its Content-Length is adjusted.
+func UTCTimestamps() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return rewriteJSONResponse(w, r, next, isJSONContentType, toUTC)
+		})
+	}
+}
+
+// toUTC converts the timestamps of the JSON value, at any depth.
+func toUTC(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = toUTC(child)
+		}
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = toUTC(child)
+		}
+		return v
+	case string:
+		if !rfc3339Pattern.MatchString(v) {
+			return v
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return v
+		}
+
+		// keeps the precision of the original timestamp.
+		layout := "2006-01-02T15:04:05Z"
+		if i := strings.IndexByte(v, '.'); i >= 0 {
+			digits := strings.IndexAny(v[i+1:], "Z+-")
+			layout = "2006-01-02T15:04:05." + strings.Repeat("0", digits) + "Z"
+		}
+		return t.UTC().Format(layout)
+	default:
+		return v
+	}
+}
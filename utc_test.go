@@ -0,0 +1,50 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUTCTimestamps(t *testing.T) {
+	body := `{
+		"created_at": "2024-01-02T10:00:00+07:00",
+		"updated_at": "2024-01-01T23:30:00.120-05:00",
+		"deleted_at": "2024-01-02T03:00:00Z",
+		"events": [{"at": "2024-01-02T00:00:00.5+01:00"}, {"at": null}],
+		"date": "2024-01-02",
+		"invalid": "2024-13-45T99:00:00+07:00",
+		"note": "meet at 2024-01-02T10:00:00+07:00",
+		"count": 12345678901234567890
+	}`
+
+	h := UTCTimestamps().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+		return nil
+	}))
+
+	res := httptest.NewRecorder()
+	err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, err == nil)
+
+	want := `{"count":12345678901234567890,"created_at":"2024-01-02T03:00:00Z","date":"2024-01-02",` +
+		`"deleted_at":"2024-01-02T03:00:00Z","events":[{"at":"2024-01-01T23:00:00.5Z"},{"at":null}],` +
+		`"invalid":"2024-13-45T99:00:00+07:00","note":"meet at 2024-01-02T10:00:00+07:00",` +
+		`"updated_at":"2024-01-02T04:30:00.120Z"}`
+	expectTrue(t, res.Body.String() == want)
+}
+
+func TestUTCTimestamps_NotJSON(t *testing.T) {
+	body := "2024-01-02T10:00:00+07:00"
+	h := UTCTimestamps().Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(body))
+		return nil
+	}))
+
+	res := httptest.NewRecorder()
+	err := h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	expectTrue(t, err == nil)
+	expectTrue(t, res.Body.String() == body)
+}
@@ -0,0 +1,218 @@
+package httprouterx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxWebhookBytes is the maximum size of a webhook payload accepted by WebhookVerifier.
+	DefaultMaxWebhookBytes = 1 << 20
+
+	// DefaultWebhookTolerance is the maximum age of a timestamped webhook signature, to prevent replay attacks.
+	DefaultWebhookTolerance = 5 * time.Minute
+)
+
+// WebhookProvider verifies the signature of the webhooks sent by a provider.
+type WebhookProvider interface {
+	// Verify checks the signature of the request, given its raw body. The returned error is sent to the client
+	// as the detail of the 401 response, so it must not leak the secret or the expected signature.
+	Verify(r *http.Request, body []byte) error
+}
+
+// WebhookVerifier rejects the webhooks whose signature is not valid for the provider with 401 Unauthorized.
+// The body is read, up to DefaultMaxWebhookBytes (413 Request Entity Too Large beyond that), to be verified and
+// is then made available again to the next handler.
+//
+// GitHubWebhook, StripeWebhook and SlackWebhook are the built-in providers, other providers can be supported by
+// implementing WebhookProvider. It panics if a built-in provider has no Secret.
+func WebhookVerifier(provider WebhookProvider) Middleware {
+	switch p := provider.(type) {
+	case GitHubWebhook:
+		mustHaveWebhookSecret(p.Secret)
+	case StripeWebhook:
+		mustHaveWebhookSecret(p.Secret)
+	case SlackWebhook:
+		mustHaveWebhookSecret(p.Secret)
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			var body []byte
+			if r.Body != nil {
+				b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, DefaultMaxWebhookBytes))
+				if err != nil {
+					var maxErr *http.MaxBytesError
+					if errors.As(err, &maxErr) {
+						return &HTTPError{Status: http.StatusRequestEntityTooLarge, Message: "webhook payload is too large", Err: err}
+					}
+					return &HTTPError{Status: http.StatusBadRequest, Message: "failed to read webhook payload", Err: err}
+				}
+				body = b
+			}
+
+			if err := provider.Verify(r, body); err != nil {
+				var httpErr *HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				return &HTTPError{Status: http.StatusUnauthorized, Message: err.Error(), Err: err}
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GitHubWebhook verifies the X-Hub-Signature-256 header of GitHub webhooks.
+type GitHubWebhook struct {
+	// Secret is the secret of the webhook. Required.
+	Secret string
+}
+
+// Verify implements WebhookProvider.
+func (p GitHubWebhook) Verify(r *http.Request, body []byte) error {
+	header := r.Header.Get("X-Hub-Signature-256")
+	if header == "" {
+		return errors.New("github: missing X-Hub-Signature-256 header")
+	}
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return errors.New("github: unsupported signature algorithm")
+	}
+	if !validHMACSHA256(p.Secret, body, sig) {
+		return errors.New("github: signature mismatch")
+	}
+	return nil
+}
+
+// StripeWebhook verifies the Stripe-Signature header of Stripe webhooks, including the age of its timestamp.
+type StripeWebhook struct {
+	// Secret is the signing secret of the endpoint. Required.
+	Secret string
+
+	// Tolerance is the maximum age of the signature. Default DefaultWebhookTolerance.
+	Tolerance time.Duration
+
+	// Clock is used to check the timestamp of the signature. Default SystemClock.
+	Clock Clock
+}
+
+// Verify implements WebhookProvider.
+func (p StripeWebhook) Verify(r *http.Request, body []byte) error {
+	header := r.Header.Get("Stripe-Signature")
+	if header == "" {
+		return errors.New("stripe: missing Stripe-Signature header")
+	}
+
+	var (
+		timestamp  string
+		signatures []string
+	)
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("stripe: malformed Stripe-Signature header")
+	}
+	if err := checkWebhookTimestamp(timestamp, p.Tolerance, p.Clock); err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+
+	payload := append([]byte(timestamp+"."), body...)
+	for _, sig := range signatures {
+		if validHMACSHA256(p.Secret, payload, sig) {
+			return nil
+		}
+	}
+	return errors.New("stripe: signature mismatch")
+}
+
+// SlackWebhook verifies the X-Slack-Signature and X-Slack-Request-Timestamp headers of Slack requests.
+type SlackWebhook struct {
+	// Secret is the signing secret of the Slack app. Required.
+	Secret string
+
+	// Tolerance is the maximum age of the signature. Default DefaultWebhookTolerance.
+	Tolerance time.Duration
+
+	// Clock is used to check the timestamp of the signature. Default SystemClock.
+	Clock Clock
+}
+
+// Verify implements WebhookProvider.
+func (p SlackWebhook) Verify(r *http.Request, body []byte) error {
+	header := r.Header.Get("X-Slack-Signature")
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	if header == "" || timestamp == "" {
+		return errors.New("slack: missing X-Slack-Signature or X-Slack-Request-Timestamp header")
+	}
+	sig, ok := strings.CutPrefix(header, "v0=")
+	if !ok {
+		return errors.New("slack: unsupported signature version")
+	}
+	if err := checkWebhookTimestamp(timestamp, p.Tolerance, p.Clock); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+
+	payload := append([]byte("v0:"+timestamp+":"), body...)
+	if !validHMACSHA256(p.Secret, payload, sig) {
+		return errors.New("slack: signature mismatch")
+	}
+	return nil
+}
+
+// mustHaveWebhookSecret panics if the secret of a built-in provider is empty.
+func mustHaveWebhookSecret(secret string) {
+	if secret == "" {
+		panic("httprouterx: WebhookVerifier: the provider has no Secret")
+	}
+}
+
+// validHMACSHA256 reports whether sig is the hex-encoded HMAC-SHA256 of the payload, in constant time. It is
+// always false for an empty secret, whose signatures anyone can compute.
+func validHMACSHA256(secret string, payload []byte, sig string) bool {
+	if secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// checkWebhookTimestamp checks that the Unix timestamp is within the tolerance of the current time.
+func checkWebhookTimestamp(timestamp string, tolerance time.Duration, clock Clock) error {
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	age := clockOrSystem(clock).Now().Sub(time.Unix(sec, 0))
+	if math.Abs(float64(age)) > float64(tolerance) {
+		return errors.New("timestamp is outside the tolerance")
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package httprouterx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookVerifier(t *testing.T) {
+	const (
+		secret = "s3cret"
+		body   = `{"event":"push"}`
+	)
+	clock := newFakeClock()
+	now := strconv.FormatInt(clock.Now().Unix(), 10)
+	old := strconv.FormatInt(clock.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name     string
+		provider WebhookProvider
+		headers  map[string]string
+		status   int
+		detail   string
+	}{
+		{
+			name:     "github valid",
+			provider: GitHubWebhook{Secret: secret},
+			headers:  map[string]string{"X-Hub-Signature-256": "sha256=" + sign(secret, body)},
+			status:   200,
+		},
+		{
+			name:     "github missing",
+			provider: GitHubWebhook{Secret: secret},
+			status:   401,
+			detail:   "github: missing X-Hub-Signature-256 header",
+		},
+		{
+			name:     "github wrong secret",
+			provider: GitHubWebhook{Secret: secret},
+			headers:  map[string]string{"X-Hub-Signature-256": "sha256=" + sign("other", body)},
+			status:   401,
+			detail:   "github: signature mismatch",
+		},
+		{
+			name:     "stripe valid",
+			provider: StripeWebhook{Secret: secret, Clock: clock},
+			headers:  map[string]string{"Stripe-Signature": "t=" + now + ",v1=" + sign("other", now+"."+body) + ",v1=" + sign(secret, now+"."+body)},
+			status:   200,
+		},
+		{
+			name:     "stripe expired",
+			provider: StripeWebhook{Secret: secret, Clock: clock},
+			headers:  map[string]string{"Stripe-Signature": "t=" + old + ",v1=" + sign(secret, old+"."+body)},
+			status:   401,
+			detail:   "stripe: timestamp is outside the tolerance",
+		},
+		{
+			name:     "stripe malformed",
+			provider: StripeWebhook{Secret: secret, Clock: clock},
+			headers:  map[string]string{"Stripe-Signature": "v1=abc"},
+			status:   401,
+			detail:   "stripe: malformed Stripe-Signature header",
+		},
+		{
+			name:     "slack valid",
+			provider: SlackWebhook{Secret: secret, Clock: clock},
+			headers:  map[string]string{"X-Slack-Request-Timestamp": now, "X-Slack-Signature": "v0=" + sign(secret, "v0:"+now+":"+body)},
+			status:   200,
+		},
+		{
+			name:     "slack tampered",
+			provider: SlackWebhook{Secret: secret, Clock: clock},
+			headers:  map[string]string{"X-Slack-Request-Timestamp": now, "X-Slack-Signature": "v0=" + sign(secret, "v0:"+now+":{}")},
+			status:   401,
+			detail:   "slack: signature mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := NewServeMux()
+			mux.Route(Route{Method: "POST", Path: "/hooks", Handler: func(w http.ResponseWriter, r *http.Request) error {
+				b, _ := io.ReadAll(r.Body)
+				expectTrue(t, string(b) == body)
+				w.WriteHeader(200)
+				return nil
+			}}, WebhookVerifier(tt.provider))
+
+			req := httptest.NewRequest("POST", "/hooks", strings.NewReader(body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			res := httptest.NewRecorder()
+			mux.ServeHTTP(res, req)
+			expectTrue(t, res.Code == tt.status)
+			expectTrue(t, strings.Contains(res.Body.String(), tt.detail))
+		})
+	}
+}
+
+func TestWebhookVerifier_TooLarge(t *testing.T) {
+	h := WebhookVerifier(GitHubWebhook{Secret: "s"}).Then(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return nil }))
+	req := httptest.NewRequest("POST", "/hooks", strings.NewReader(strings.Repeat("x", DefaultMaxWebhookBytes+1)))
+	err := h.ServeHTTP(httptest.NewRecorder(), req)
+	expectHTTPError(t, err, http.StatusRequestEntityTooLarge)
+}
+
+func TestWebhookVerifier_EmptySecret(t *testing.T) {
+	for _, provider := range []WebhookProvider{GitHubWebhook{}, StripeWebhook{}, SlackWebhook{}} {
+		func() {
+			defer func() { expectTrue(t, recover() != nil) }()
+			WebhookVerifier(provider)
+		}()
+	}
+
+	// Verify used directly must not accept a signature computed with the empty key.
+	body := []byte(`{"event":"push"}`)
+	req := httptest.NewRequest("POST", "/hooks", nil)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+sign("", string(body)))
+	expectTrue(t, GitHubWebhook{}.Verify(req, body) != nil)
+}
@@ -0,0 +1,65 @@
+package httprouterx
+
+import (
+	"sync"
+	"time"
+)
+
+// rollingWindow counts the total and failed events of the last window, divided into buckets.
+type rollingWindow struct {
+	mu         sync.Mutex
+	clock      Clock
+	bucketSize time.Duration
+	buckets    []windowBucket
+}
+
+type windowBucket struct {
+	slot   int64
+	total  int
+	failed int
+}
+
+func newRollingWindow(window time.Duration, buckets int, clock Clock) *rollingWindow {
+	size := window / time.Duration(buckets)
+	if size <= 0 {
+		size = 1
+	}
+	return &rollingWindow{clock: clock, bucketSize: size, buckets: make([]windowBucket, buckets)}
+}
+
+func (rw *rollingWindow) add(failed bool) {
+	slot := rw.clock.Now().UnixNano() / int64(rw.bucketSize)
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	b := &rw.buckets[slot%int64(len(rw.buckets))]
+	if b.slot != slot {
+		*b = windowBucket{slot: slot}
+	}
+	b.total++
+	if failed {
+		b.failed++
+	}
+}
+
+func (rw *rollingWindow) counts() (total, failed int) {
+	slot := rw.clock.Now().UnixNano() / int64(rw.bucketSize)
+	oldest := slot - int64(len(rw.buckets)) + 1
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	for _, b := range rw.buckets {
+		if b.slot >= oldest && b.slot <= slot {
+			total += b.total
+			failed += b.failed
+		}
+	}
+	return total, failed
+}
+
+// reset forgets all the events.
+func (rw *rollingWindow) reset() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	clear(rw.buckets)
+}
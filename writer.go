@@ -0,0 +1,72 @@
+package httprouterx
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// statusWriter wraps an http.ResponseWriter to capture the status and the number of bytes written.
+// It still supports http.Flusher and http.Hijacker when the underlying writer does, and exposes the underlying
+// writer to http.ResponseController via Unwrap.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func newStatusWriter(w http.ResponseWriter) *statusWriter {
+	return &statusWriter{ResponseWriter: w}
+}
+
+// WriteHeader implements http.ResponseWriter. Informational (1xx) statuses are not recorded, since they are
+// not the final status.
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 && (status < 100 || status > 199) {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher. It is a no-op if the underlying writer is not an http.Flusher.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker. It returns http.ErrNotSupported if the underlying writer is not an
+// http.Hijacker.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// written reports whether the status or any part of the body has been written.
+func (w *statusWriter) written() bool { return w.status != 0 }
+
+// statusCode returns the written status, or 200 if nothing has been written yet.
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
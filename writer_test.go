@@ -0,0 +1,36 @@
+package httprouterx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusWriter(t *testing.T) {
+	res := httptest.NewRecorder()
+	sw := newStatusWriter(res)
+	expectFalse(t, sw.written())
+	expectTrue(t, sw.statusCode() == 200)
+
+	sw.WriteHeader(201)
+	_, _ = sw.Write([]byte("hello"))
+	expectTrue(t, sw.written())
+	expectTrue(t, sw.statusCode() == 201)
+	expectTrue(t, sw.bytes == 5)
+
+	sw.Flush()
+	expectTrue(t, res.Flushed)
+
+	_, _, err := sw.Hijack()
+	expectTrue(t, err == http.ErrNotSupported)
+
+	expectTrue(t, http.NewResponseController(sw).Flush() == nil)
+}
+
+func TestStatusWriter_InformationalStatus(t *testing.T) {
+	sw := newStatusWriter(discardResponseWriter{header: make(http.Header)})
+	sw.WriteHeader(http.StatusEarlyHints)
+	expectFalse(t, sw.written())
+	sw.WriteHeader(http.StatusAccepted)
+	expectTrue(t, sw.statusCode() == http.StatusAccepted)
+}